@@ -13,6 +13,8 @@ type Config struct {
 	JWTSecret            []byte
 	Port                 string
 	BaseURL              string
+	BaseURLExplicit      bool     // BASE_URL is set; otherwise media URLs follow the request's scheme and host
+	TrustedProxies       []string // IPs/CIDRs whose X-Forwarded-Proto/-For is believed (TRUSTED_PROXIES)
 	UploadDir            string
 	MaxUploadSize        int64
 	MaxImageUploadSize   int64
@@ -25,6 +27,13 @@ type Config struct {
 	InstagramRedirectURI string
 	FacebookVersion      string
 	InstagramVersion     string
+	LinkedInClientID     string
+	LinkedInClientSecret string
+	LinkedInRedirectURI  string
+	ThreadsAppID         string
+	ThreadsAppSecret     string
+	ThreadsRedirectURI   string
+	ThreadsVersion       string
 	TikTokClientKey      string
 	TikTokClientSecret   string
 	TikTokRedirectURI    string
@@ -34,15 +43,113 @@ type Config struct {
 	YouTubeClientID      string
 	YouTubeClientSecret  string
 	YouTubeRedirectURI   string
+	YouTubeRegionCode    string
 	TokenEncryptionKey   []byte
 	TLSEnabled           bool
 	TLSCertFile          string
 	TLSKeyFile           string
+	TLSCipherSuites      []string // TLS 1.2 cipher suites by crypto/tls name; empty = Go's defaults (TLS_CIPHER_SUITES)
+	TLSOCSPStapleFile    string   // DER OCSP response stapled to the certificate, reloaded with it (TLS_OCSP_STAPLE_FILE)
 	MediaSigningKey      []byte
 	MediaURLExpiry       time.Duration
+	MediaCDNBase         string // Host media URLs are generated against instead of BaseURL; empty = BaseURL (MEDIA_CDN_BASE)
+
+	// Resumable (tus) uploads
+	ResumableUploadDir    string        // Partial files of in-progress uploads; must not be under UploadDir
+	ResumableUploadExpiry time.Duration // Unfinished uploads are discarded after this long
+
+	// Publishing
+	InstagramCarouselConcurrency     int // Max carousel child containers created in parallel
+	InstagramCarouselChildRetries    int // Retries of a carousel child container after a transient or rate-limited failure (INSTAGRAM_CAROUSEL_CHILD_RETRIES)
+	FacebookReelTitleMaxLength       int // Reel title, taken from the first line of content (FACEBOOK_REEL_TITLE_MAX_LENGTH)
+	FacebookReelDescriptionMaxLength int // Reel description, i.e. the content limit of Facebook short posts (FACEBOOK_REEL_DESCRIPTION_MAX_LENGTH)
+
+	// Instagram container processing is polled with a wait that doubles after
+	// each check, until the container is ready, the attempts run out or the
+	// timeout passes
+	InstagramContainerPollAttempts      int           // INSTAGRAM_CONTAINER_POLL_ATTEMPTS
+	InstagramContainerPollInterval      time.Duration // First wait (INSTAGRAM_CONTAINER_POLL_INTERVAL_SECONDS)
+	InstagramContainerTimeout           time.Duration // INSTAGRAM_CONTAINER_TIMEOUT_SECONDS
+	InstagramVideoContainerPollAttempts int           // Reels, video posts and stories (INSTAGRAM_VIDEO_CONTAINER_POLL_ATTEMPTS)
+	InstagramVideoContainerTimeout      time.Duration // INSTAGRAM_VIDEO_CONTAINER_TIMEOUT_SECONDS
+
+	// Platform API calls answered with 429 or 5xx are retried with backoff
+	PlatformRetryAttempts int           // Attempts per call, the first included (PLATFORM_RETRY_ATTEMPTS)
+	PlatformRetryMaxWait  time.Duration // Total retry wait of a platform publish, over all its calls (PLATFORM_RETRY_MAX_WAIT_SECONDS)
+
+	// Platform API bases (override for sandboxes, mock servers or proxies)
+	FacebookGraphBase  string
+	InstagramGraphBase string
+	LinkedInAPIBase    string
+	ThreadsGraphBase   string
+	TikTokAPIBase      string
+	TwitterAPIBase     string
+	TwitterUploadBase  string
+	YouTubeAPIBase     string
+	GoogleOAuthBase    string // Token refresh, exchange and revocation (GOOGLE_OAUTH_BASE)
+
+	// Request timeout
+	RequestTimeout time.Duration // Max wait for an API response, except uploads and publishing (REQUEST_TIMEOUT_SECONDS)
+
+	// Outgoing email (password resets, notifications); a no-op mailer is used when SMTPHost is empty
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Notifications
+	PostRetryURL       string        // Retry link in publish failure emails; "{id}" is replaced by the post ID (POST_RETRY_URL)
+	TokenExpiryWarning time.Duration // Email users this long before a platform token expires; 0 disables (TOKEN_EXPIRY_WARNING_DAYS)
+
+	// Password reset
+	PasswordResetURL string        // Page the reset email links to, with ?token=...; empty = the email only contains the token
+	PasswordResetTTL time.Duration // How long a reset token stays valid (PASSWORD_RESET_TTL_MINUTES)
+
+	// Content limits
+	CaptionMaxLength    int            // Optional cap (characters) on post content below each platform's own limit; 0 = platform limits only (MAX_CAPTION_LENGTH)
+	VideoMaxResolution  map[string]int // Per-platform overrides of the longest video edge in pixels, e.g. "tiktok=1920"; 0 = no limit (VIDEO_MAX_RESOLUTION)
+	VideoMaxBitrateKbps map[string]int // Per-platform overrides of the average video bitrate (VIDEO_MAX_BITRATE_KBPS)
+
+	// Post types: platforms each post_type may be published to; empty = built-in defaults
+	NormalPostPlatforms []string // POST_TYPE_NORMAL_PLATFORMS
+	ShortPostPlatforms  []string // POST_TYPE_SHORT_PLATFORMS
+	StoryPostPlatforms  []string // POST_TYPE_STORY_PLATFORMS
+
+	// Response compression
+	CompressionMinSize int // Smallest response body (bytes) worth gzipping (COMPRESSION_MIN_SIZE)
+
+	// Outbound HTTP
+	OAuthHTTPTimeout time.Duration // Timeout for OAuth token and identity requests (OAUTH_HTTP_TIMEOUT_SECONDS)
+	HTTPUserAgent    string        // User-Agent sent on every platform API and OAuth request (HTTP_USER_AGENT)
+
+	// OAuth redirects
+	OAuthRedirectAllowlist []string // Extra origins OAuth callbacks may redirect to (OAUTH_REDIRECT_ALLOWLIST); relative paths are always allowed
+
+	// Scheduler
+	SchedulerBatchSize int // Most due posts and retries queued per tick; the rest wait for the next tick (SCHEDULER_BATCH_SIZE)
+
+	// Publish queue
+	PublishWorkers       int           // Workers publishing scheduled, retried and async posts (PUBLISH_WORKERS)
+	PublishRecoveryAfter time.Duration // Publish jobs and posts left unfinished this long (e.g. by a restart) are queued again (PUBLISH_RECOVERY_AFTER_MINUTES)
+
+	// Max platform publishes (uploads) in flight at once across all users and
+	// posts; 0 = no limit (MAX_GLOBAL_PUBLISH_CONCURRENCY)
+	MaxGlobalPublishConcurrency int
+
+	// Scheduled-post retries
+	PublishRetrySchedule    []time.Duration // Delay before each retry of a failed scheduled post (PUBLISH_RETRY_SCHEDULE)
+	PublishRetryMaxAttempts int             // Retries after which a failed post is left as failed
+
+	// Publish failure metrics
+	PublishFailureSummaryInterval time.Duration // How often failures by platform/category are summarized in the logs
+
+	// Publish results retention
+	PublishResultsRetention time.Duration // Older publish results are deleted daily, except the latest per post and platform (PUBLISH_RESULTS_RETENTION_DAYS)
 
 	// CORS
 	CORSAllowedOrigins []string // Comma-separated list via CORS_ALLOWED_ORIGINS env var
+	CORSExposedHeaders []string // Response headers readable by browser clients (CORS_EXPOSED_HEADERS)
 
 	// Rate limiting
 	RateLimitRPS         float64       // Sustained requests per second (global, per IP)
@@ -50,6 +157,9 @@ type Config struct {
 	AuthRateLimitRPS     float64       // Sustained RPS for auth endpoints (login/register)
 	AuthRateLimitBurst   float64       // Burst capacity for auth endpoints
 
+	// Admin
+	AdminEmail string // User with this email is given the admin role at startup (ADMIN_EMAIL)
+
 	// Environment
 	Env                  string        // "production", "staging", or "development" (default)
 }
@@ -68,6 +178,8 @@ func Load() *Config {
 		JWTSecret:            []byte(getEnv("JWT_SECRET", "your-secret-key-change-in-production")),
 		Port:                 getEnv("PORT", "3001"),
 		BaseURL:              getEnv("BASE_URL", "http://localhost:3001"),
+		BaseURLExplicit:      os.Getenv("BASE_URL") != "",
+		TrustedProxies:       getEnvList("TRUSTED_PROXIES", nil),
 		UploadDir:            getEnv("UPLOAD_DIR", "./uploads"),
 		MaxUploadSize:        100 << 20,                           // 100 MB (overall form limit)
 		MaxImageUploadSize:   10 << 20,                            // 10 MB
@@ -80,6 +192,13 @@ func Load() *Config {
 		InstagramRedirectURI: getEnv("INSTAGRAM_REDIRECT_URI", ""),
 		FacebookVersion:      getEnv("FACEBOOK_VERSION", "v25.0"),
 		InstagramVersion:     getEnv("INSTAGRAM_VERSION", "v25.0"),
+		LinkedInClientID:     getEnv("LINKEDIN_CLIENT_ID", ""),
+		LinkedInClientSecret: getEnv("LINKEDIN_CLIENT_SECRET", ""),
+		LinkedInRedirectURI:  getEnv("LINKEDIN_REDIRECT_URI", ""),
+		ThreadsAppID:         getEnv("THREADS_APP_ID", getEnv("FACEBOOK_APP_ID", "")),
+		ThreadsAppSecret:     getEnv("THREADS_APP_SECRET", getEnv("FACEBOOK_APP_SECRET", "")),
+		ThreadsRedirectURI:   getEnv("THREADS_REDIRECT_URI", ""),
+		ThreadsVersion:       getEnv("THREADS_VERSION", "v1.0"),
 		TikTokClientKey:      getEnv("TIKTOK_CLIENT_KEY", ""),
 		TikTokClientSecret:   getEnv("TIKTOK_CLIENT_SECRET", ""),
 		TikTokRedirectURI:    getEnv("TIKTOK_REDIRECT_URI", ""),
@@ -89,20 +208,97 @@ func Load() *Config {
 		YouTubeClientID:      getEnv("YOUTUBE_CLIENT_ID", ""),
 		YouTubeClientSecret:  getEnv("YOUTUBE_CLIENT_SECRET", ""),
 		YouTubeRedirectURI:   getEnv("YOUTUBE_REDIRECT_URI", ""),
+		YouTubeRegionCode:    strings.ToUpper(getEnv("YOUTUBE_REGION_CODE", "US")),
 		TokenEncryptionKey:   []byte(getEnv("TOKEN_ENCRYPTION_KEY", "your-secret-token-encryption-key-change-in-production")),
 		TLSEnabled:           getEnv("TLS_ENABLED", "false") == "true",
 		TLSCertFile:          getEnv("TLS_CERT_FILE", "./certs/server.crt"),
 		TLSKeyFile:           getEnv("TLS_KEY_FILE", "./certs/server.key"),
+		TLSCipherSuites:      getEnvList("TLS_CIPHER_SUITES", nil),
+		TLSOCSPStapleFile:    getEnv("TLS_OCSP_STAPLE_FILE", ""),
 		MediaSigningKey:      []byte(getEnv("MEDIA_SIGNING_KEY", getEnv("JWT_SECRET", "your-secret-key-change-in-production"))),
 		MediaURLExpiry:       getEnvDuration("MEDIA_URL_EXPIRY_HOURS", 1),
+		MediaCDNBase:         getEnvURL("MEDIA_CDN_BASE", ""),
+
+		ResumableUploadDir:    getEnv("RESUMABLE_UPLOAD_DIR", "./uploads-partial"),
+		ResumableUploadExpiry: getEnvDuration("RESUMABLE_UPLOAD_EXPIRY_HOURS", 24),
+
+		InstagramCarouselConcurrency:     getEnvInt("INSTAGRAM_CAROUSEL_CONCURRENCY", 4),
+		InstagramCarouselChildRetries:    getEnvInt("INSTAGRAM_CAROUSEL_CHILD_RETRIES", 2),
+		FacebookReelTitleMaxLength:       getEnvInt("FACEBOOK_REEL_TITLE_MAX_LENGTH", 255),
+		FacebookReelDescriptionMaxLength: getEnvInt("FACEBOOK_REEL_DESCRIPTION_MAX_LENGTH", 2200),
+
+		InstagramContainerPollAttempts:      getEnvInt("INSTAGRAM_CONTAINER_POLL_ATTEMPTS", 10),
+		InstagramContainerPollInterval:      time.Duration(getEnvInt("INSTAGRAM_CONTAINER_POLL_INTERVAL_SECONDS", 2)) * time.Second,
+		InstagramContainerTimeout:           time.Duration(getEnvInt("INSTAGRAM_CONTAINER_TIMEOUT_SECONDS", 300)) * time.Second,
+		InstagramVideoContainerPollAttempts: getEnvInt("INSTAGRAM_VIDEO_CONTAINER_POLL_ATTEMPTS", 40),
+		InstagramVideoContainerTimeout:      time.Duration(getEnvInt("INSTAGRAM_VIDEO_CONTAINER_TIMEOUT_SECONDS", 900)) * time.Second,
+
+		PlatformRetryAttempts: getEnvInt("PLATFORM_RETRY_ATTEMPTS", 3),
+		PlatformRetryMaxWait:  time.Duration(getEnvInt("PLATFORM_RETRY_MAX_WAIT_SECONDS", 30)) * time.Second,
+
+		FacebookGraphBase:  getEnvURL("FACEBOOK_GRAPH_BASE", "https://graph.facebook.com"),
+		InstagramGraphBase: getEnvURL("INSTAGRAM_GRAPH_BASE", "https://graph.instagram.com"),
+		LinkedInAPIBase:    getEnvURL("LINKEDIN_API_BASE", "https://api.linkedin.com"),
+		ThreadsGraphBase:   getEnvURL("THREADS_GRAPH_BASE", "https://graph.threads.net"),
+		TikTokAPIBase:      getEnvURL("TIKTOK_API_BASE", "https://open.tiktokapis.com"),
+		TwitterAPIBase:     getEnvURL("TWITTER_API_BASE", "https://api.x.com"),
+		TwitterUploadBase:  getEnvURL("TWITTER_UPLOAD_BASE", "https://upload.x.com"),
+		YouTubeAPIBase:     getEnvURL("YOUTUBE_API_BASE", "https://www.googleapis.com"),
+		GoogleOAuthBase:    getEnvURL("GOOGLE_OAUTH_BASE", "https://oauth2.googleapis.com"),
+
+		RequestTimeout: time.Duration(getEnvInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second,
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "no-reply@localhost"),
+
+		PostRetryURL:       getEnv("POST_RETRY_URL", ""),
+		TokenExpiryWarning: time.Duration(getEnvNonNegInt("TOKEN_EXPIRY_WARNING_DAYS", 3)) * 24 * time.Hour,
+
+		PasswordResetURL: getEnv("PASSWORD_RESET_URL", ""),
+		PasswordResetTTL: time.Duration(getEnvInt("PASSWORD_RESET_TTL_MINUTES", 60)) * time.Minute,
+
+		CaptionMaxLength:    getEnvInt("MAX_CAPTION_LENGTH", 0),
+		VideoMaxResolution:  getEnvIntMap("VIDEO_MAX_RESOLUTION"),
+		VideoMaxBitrateKbps: getEnvIntMap("VIDEO_MAX_BITRATE_KBPS"),
+
+		NormalPostPlatforms: getEnvList("POST_TYPE_NORMAL_PLATFORMS", nil),
+		ShortPostPlatforms:  getEnvList("POST_TYPE_SHORT_PLATFORMS", nil),
+		StoryPostPlatforms:  getEnvList("POST_TYPE_STORY_PLATFORMS", nil),
+
+		CompressionMinSize: getEnvInt("COMPRESSION_MIN_SIZE", 1024),
+
+		OAuthHTTPTimeout: time.Duration(getEnvInt("OAUTH_HTTP_TIMEOUT_SECONDS", 15)) * time.Second,
+		HTTPUserAgent:    getEnv("HTTP_USER_AGENT", "SocialMediaAPI/1.0"),
+
+		OAuthRedirectAllowlist: getEnvList("OAUTH_REDIRECT_ALLOWLIST", nil),
+
+		SchedulerBatchSize: getEnvInt("SCHEDULER_BATCH_SIZE", 50),
+
+		PublishWorkers:       getEnvInt("PUBLISH_WORKERS", 4),
+		PublishRecoveryAfter: time.Duration(getEnvInt("PUBLISH_RECOVERY_AFTER_MINUTES", 30)) * time.Minute,
+
+		MaxGlobalPublishConcurrency: getEnvNonNegInt("MAX_GLOBAL_PUBLISH_CONCURRENCY", 32),
+
+		PublishRetrySchedule:    getEnvDurationList("PUBLISH_RETRY_SCHEDULE", []time.Duration{5 * time.Minute, 30 * time.Minute, 2 * time.Hour}),
+		PublishRetryMaxAttempts: getEnvInt("PUBLISH_RETRY_MAX_ATTEMPTS", 3),
+
+		PublishFailureSummaryInterval: getEnvDuration("PUBLISH_FAILURE_SUMMARY_HOURS", 1),
+
+		PublishResultsRetention: time.Duration(getEnvInt("PUBLISH_RESULTS_RETENTION_DAYS", 90)) * 24 * time.Hour,
 
 		CORSAllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS", nil),
+		CORSExposedHeaders: getEnvList("CORS_EXPOSED_HEADERS", nil),
 
 		RateLimitRPS:       getEnvFloat("RATE_LIMIT_RPS", 10),
 		RateLimitBurst:     getEnvFloat("RATE_LIMIT_BURST", 20),
 		AuthRateLimitRPS:   getEnvFloat("AUTH_RATE_LIMIT_RPS", 1),
 		AuthRateLimitBurst: getEnvFloat("AUTH_RATE_LIMIT_BURST", 5),
 
+		AdminEmail: strings.ToLower(strings.TrimSpace(getEnv("ADMIN_EMAIL", ""))),
+
 		Env: strings.ToLower(getEnv("GO_ENV", "development")),
 	}
 
@@ -116,6 +312,16 @@ type SecretIssue struct {
 	Message string // human-readable description of the problem
 }
 
+// MediaBaseURL returns the base that media URLs are built on: the CDN when
+// MEDIA_CDN_BASE is set, otherwise this server. Paths are the same on both,
+// so the CDN can pass requests through to /uploads/ on the origin.
+func (c *Config) MediaBaseURL() string {
+	if c.MediaCDNBase != "" {
+		return c.MediaCDNBase
+	}
+	return c.BaseURL
+}
+
 // AuditSecrets checks all critical secrets and returns a list of issues found.
 // This is the public API that callers (e.g. main.go) use to decide whether to
 // warn or fatally exit, using whatever logger they prefer.
@@ -170,6 +376,12 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvURL reads a base URL from the environment with any trailing slash
+// removed, so callers can append paths directly.
+func getEnvURL(key, defaultValue string) string {
+	return strings.TrimRight(getEnv(key, defaultValue), "/")
+}
+
 // getEnvList reads a comma-separated environment variable into a string slice.
 // Leading/trailing whitespace around each element is trimmed. Empty entries are
 // discarded. Returns defaultVal when the variable is unset or empty.
@@ -192,6 +404,23 @@ func getEnvList(key string, defaultVal []string) []string {
 	return out
 }
 
+// getEnvIntMap reads a comma-separated list of key=value pairs with
+// non-negative integer values (e.g. "tiktok=1920,youtube=0"). Invalid
+// entries are skipped with a warning. Returns an empty map when unset.
+func getEnvIntMap(key string) map[string]int {
+	out := map[string]int{}
+	for _, p := range getEnvList(key, nil) {
+		k, v, ok := strings.Cut(p, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if !ok || strings.TrimSpace(k) == "" || err != nil || n < 0 {
+			log.Printf("WARNING: invalid %s entry %q, ignoring it", key, p)
+			continue
+		}
+		out[strings.ToLower(strings.TrimSpace(k))] = n
+	}
+	return out
+}
+
 // getEnvFloat reads an environment variable as a float64.
 // Falls back to defaultVal when unset or invalid.
 func getEnvFloat(key string, defaultVal float64) float64 {
@@ -203,6 +432,29 @@ func getEnvFloat(key string, defaultVal float64) float64 {
 	return defaultVal
 }
 
+// getEnvInt reads an environment variable as a positive integer.
+// Falls back to defaultVal when unset or invalid.
+func getEnvInt(key string, defaultVal int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultVal
+}
+
+// getEnvNonNegInt reads an environment variable as a non-negative integer,
+// for settings where 0 means "disabled" or "no limit". Falls back to
+// defaultVal when unset, negative or invalid.
+func getEnvNonNegInt(key string, defaultVal int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return defaultVal
+}
+
 // getEnvDuration reads an environment variable as an integer number of hours
 // and returns a time.Duration. Falls back to defaultHours when unset or invalid.
 func getEnvDuration(key string, defaultHours int) time.Duration {
@@ -213,3 +465,23 @@ func getEnvDuration(key string, defaultHours int) time.Duration {
 	}
 	return time.Duration(defaultHours) * time.Hour
 }
+
+// getEnvDurationList reads a comma-separated list of Go durations (e.g.
+// "5m,30m,2h"). Falls back to defaultVal when unset or when any entry is
+// invalid or not positive.
+func getEnvDurationList(key string, defaultVal []time.Duration) []time.Duration {
+	parts := getEnvList(key, nil)
+	if len(parts) == 0 {
+		return defaultVal
+	}
+	out := make([]time.Duration, 0, len(parts))
+	for _, p := range parts {
+		d, err := time.ParseDuration(p)
+		if err != nil || d <= 0 {
+			log.Printf("WARNING: invalid %s entry %q, using default schedule", key, p)
+			return defaultVal
+		}
+		out = append(out, d)
+	}
+	return out
+}
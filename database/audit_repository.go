@@ -0,0 +1,49 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"time"
+)
+
+// RecordAudit appends an entry to the audit log. Audit writes must never
+// block the action being audited, so failures are logged, not returned.
+func (d *Database) RecordAudit(entry models.AuditEntry) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	query := `INSERT INTO audit_log (user_id, actor_id, action, platform, post_id, success, details, ip, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := d.DB.Exec(query, entry.UserID, entry.ActorID, entry.Action, entry.Platform, entry.PostID,
+		entry.Success, entry.Details, entry.IP, entry.CreatedAt)
+	if err != nil {
+		utils.Errorf("failed to write audit entry user_id=%s action=%s err=%v", entry.UserID, entry.Action, err)
+	}
+}
+
+// GetAuditLog returns the newest audit entries, most recent first. An empty
+// userID returns entries for all users.
+func (d *Database) GetAuditLog(userID string, limit int) ([]*models.AuditEntry, error) {
+	query := `SELECT id, user_id, actor_id, action, platform, post_id, success, details, ip, created_at
+			  FROM audit_log WHERE ($1 = '' OR user_id = $1)
+			  ORDER BY created_at DESC, id DESC LIMIT $2`
+
+	rows, err := d.DB.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		e := &models.AuditEntry{}
+		if err := rows.Scan(&e.ID, &e.UserID, &e.ActorID, &e.Action, &e.Platform, &e.PostID,
+			&e.Success, &e.Details, &e.IP, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
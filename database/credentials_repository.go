@@ -4,9 +4,26 @@ import (
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+// SaveCredentials inserts or updates the user's credential for a platform
+// account (platform user and page ID), so connecting another account of the
+// same platform adds a credential. On reconnect only the tokens, account
+// names and updated_at change: the row keeps its original id and created_at
+// (the connection date shown in /credentials/status), and cred is updated
+// with those stored values.
 func (d *Database) SaveCredentials(cred *models.PlatformCredentials) error {
+	now := time.Now()
+	if cred.CreatedAt.IsZero() {
+		cred.CreatedAt = now
+	}
+	if cred.UpdatedAt.IsZero() {
+		cred.UpdatedAt = now
+	}
+
 	// Encrypt sensitive tokens before storing
 	encryptedAccessToken, err := utils.EncryptToken(cred.AccessToken)
 	if err != nil {
@@ -30,32 +47,35 @@ func (d *Database) SaveCredentials(cred *models.PlatformCredentials) error {
 	}
 
 	query := `INSERT INTO credentials (id, user_id, platform, access_token, refresh_token, secret, token_type, expires_at, 
-			  platform_user_id, platform_page_id, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-			  ON CONFLICT (user_id, platform) 
+			  platform_user_id, platform_page_id, platform_username, platform_display_name, created_at, updated_at,
+			  granted_scopes)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			  ON CONFLICT (user_id, platform, platform_user_id, platform_page_id)
 			  DO UPDATE SET access_token = $4, refresh_token = $5, secret = $6, token_type = $7, expires_at = $8, 
-			  platform_user_id = $9, platform_page_id = $10, updated_at = $12`
+			  platform_username = $11, platform_display_name = $12, granted_scopes = $15,
+			  expiry_notified_at = NULL, updated_at = $14
+			  RETURNING id, created_at`
 
-	_, err = d.DB.Exec(query, cred.ID, cred.UserID, cred.Platform,
+	return d.DB.QueryRow(query, cred.ID, cred.UserID, cred.Platform,
 		encryptedAccessToken, encryptedRefreshToken, encryptedSecret, cred.TokenType, cred.ExpiresAt,
-		cred.PlatformUserID, cred.PlatformPageID, cred.CreatedAt, cred.UpdatedAt)
-	return err
+		cred.PlatformUserID, cred.PlatformPageID, cred.PlatformUsername, cred.PlatformDisplayName,
+		cred.CreatedAt, cred.UpdatedAt, pq.Array(cred.GrantedScopes)).Scan(&cred.ID, &cred.CreatedAt)
 }
 
-func (d *Database) GetCredentials(userID string, platform models.Platform) (*models.PlatformCredentials, error) {
-	cred := &models.PlatformCredentials{}
-	query := `SELECT id, user_id, platform, access_token, refresh_token, secret, token_type, expires_at,
-			  platform_user_id, platform_page_id, created_at, updated_at
-			  FROM credentials WHERE user_id = $1 AND platform = $2`
+// credentialColumns is the column list shared by every query that loads a
+// full credential. Keep it in sync with scanCredentials.
+const credentialColumns = `id, user_id, platform, access_token, refresh_token, secret, token_type, expires_at,
+			  platform_user_id, platform_page_id, platform_username, platform_display_name,
+			  granted_scopes, COALESCE(organization_id, ''), created_at, updated_at`
 
-	err := d.DB.QueryRow(query, userID, platform).Scan(&cred.ID, &cred.UserID,
+// scanCredentials reads a row selected with credentialColumns and decrypts its tokens.
+func scanCredentials(row rowScanner) (*models.PlatformCredentials, error) {
+	cred := &models.PlatformCredentials{}
+	err := row.Scan(&cred.ID, &cred.UserID,
 		&cred.Platform, &cred.AccessToken, &cred.RefreshToken, &cred.Secret, &cred.TokenType, &cred.ExpiresAt,
-		&cred.PlatformUserID, &cred.PlatformPageID, &cred.CreatedAt, &cred.UpdatedAt)
-
+		&cred.PlatformUserID, &cred.PlatformPageID, &cred.PlatformUsername, &cred.PlatformDisplayName,
+		pq.Array(&cred.GrantedScopes), &cred.OrganizationID, &cred.CreatedAt, &cred.UpdatedAt)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
 		return nil, err
 	}
 
@@ -85,11 +105,219 @@ func (d *Database) GetCredentials(userID string, platform models.Platform) (*mod
 	return cred, nil
 }
 
+// GetCredentials returns the user's primary credential for a platform: the
+// first account they connected. When the user has none, it falls back to a
+// credential shared with one of the user's organizations (most recently
+// updated first).
+func (d *Database) GetCredentials(userID string, platform models.Platform) (*models.PlatformCredentials, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials WHERE user_id = $1 AND platform = $2
+			  ORDER BY created_at, id LIMIT 1`
+
+	cred, err := scanCredentials(d.DB.QueryRow(query, userID, platform))
+	if err == nil {
+		return cred, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	sharedQuery := `SELECT ` + credentialColumns + ` FROM credentials
+			  WHERE platform = $2 AND organization_id IN (
+				  SELECT organization_id FROM organization_members WHERE user_id = $1
+			  )
+			  ORDER BY updated_at DESC LIMIT 1`
+
+	cred, err = scanCredentials(d.DB.QueryRow(sharedQuery, userID, platform))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cred, nil
+}
+
+// GetCredentialsByID returns the credential with the given ID when it is the
+// user's own or shared with one of the user's organizations, or nil.
+func (d *Database) GetCredentialsByID(userID, id string) (*models.PlatformCredentials, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials
+			  WHERE id = $2 AND (user_id = $1 OR organization_id IN (
+				  SELECT organization_id FROM organization_members WHERE user_id = $1
+			  ))`
+
+	cred, err := scanCredentials(d.DB.QueryRow(query, userID, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cred, nil
+}
+
+// ListPlatformCredentials returns the user's own credentials for a platform,
+// one per connected account, primary first.
+func (d *Database) ListPlatformCredentials(userID string, platform models.Platform) ([]*models.PlatformCredentials, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials WHERE user_id = $1 AND platform = $2
+			  ORDER BY created_at, id`
+
+	rows, err := d.DB.Query(query, userID, platform)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	creds := []*models.PlatformCredentials{}
+	for rows.Next() {
+		cred, err := scanCredentials(rows)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, cred)
+	}
+	return creds, rows.Err()
+}
+
+// ShareCredentials sets (or, with an empty orgID, clears) the organization a
+// user's own credentials for a platform, every connected account, are shared
+// with. It returns false when the user has no credential for the platform.
+func (d *Database) ShareCredentials(userID string, platform models.Platform, orgID string) (bool, error) {
+	var org interface{}
+	if orgID != "" {
+		org = orgID
+	}
+	result, err := d.DB.Exec(`UPDATE credentials SET organization_id = $1, updated_at = $2 WHERE user_id = $3 AND platform = $4`,
+		org, time.Now(), userID, platform)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// PlatformAccountConnected reports whether any credential, of any user, is
+// still stored for the platform account platformUserID. Several credentials
+// can share one account (e.g. one per Facebook Page), and revoking the
+// account's grant would break them all.
+func (d *Database) PlatformAccountConnected(platform models.Platform, platformUserID string) (bool, error) {
+	var exists bool
+	err := d.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM credentials WHERE platform = $1 AND platform_user_id = $2)`,
+		platform, platformUserID).Scan(&exists)
+	return exists, err
+}
+
+// ListUserCredentials returns the connected platforms of a user without
+// decrypting any tokens.
+func (d *Database) ListUserCredentials(userID string) ([]*models.PlatformCredentials, error) {
+	query := `SELECT id, user_id, platform, token_type, expires_at, platform_user_id, platform_page_id,
+			  platform_username, platform_display_name, COALESCE(organization_id, ''), created_at, updated_at
+			  FROM credentials WHERE user_id = $1 ORDER BY platform, created_at, id`
+
+	rows, err := d.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	creds := []*models.PlatformCredentials{}
+	for rows.Next() {
+		cred := &models.PlatformCredentials{}
+		if err := rows.Scan(&cred.ID, &cred.UserID, &cred.Platform, &cred.TokenType, &cred.ExpiresAt,
+			&cred.PlatformUserID, &cred.PlatformPageID, &cred.PlatformUsername, &cred.PlatformDisplayName,
+			&cred.OrganizationID, &cred.CreatedAt, &cred.UpdatedAt); err != nil {
+			return nil, err
+		}
+		creds = append(creds, cred)
+	}
+
+	return creds, rows.Err()
+}
+
+// GetCredentialsExpiringBefore returns the credentials, without decrypted
+// tokens, that expire before the given time and whose owner has not been
+// warned about it yet. Already expired credentials are included.
+func (d *Database) GetCredentialsExpiringBefore(before time.Time) ([]*models.PlatformCredentials, error) {
+	query := `SELECT id, user_id, platform, token_type, expires_at, platform_user_id, platform_page_id,
+			  platform_username, platform_display_name, COALESCE(organization_id, ''), created_at, updated_at
+			  FROM credentials WHERE expires_at IS NOT NULL AND expires_at < $1 AND expiry_notified_at IS NULL
+			  ORDER BY user_id, expires_at`
+
+	rows, err := d.DB.Query(query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	creds := []*models.PlatformCredentials{}
+	for rows.Next() {
+		cred := &models.PlatformCredentials{}
+		if err := rows.Scan(&cred.ID, &cred.UserID, &cred.Platform, &cred.TokenType, &cred.ExpiresAt,
+			&cred.PlatformUserID, &cred.PlatformPageID, &cred.PlatformUsername, &cred.PlatformDisplayName,
+			&cred.OrganizationID, &cred.CreatedAt, &cred.UpdatedAt); err != nil {
+			return nil, err
+		}
+		creds = append(creds, cred)
+	}
+
+	return creds, rows.Err()
+}
+
+// MarkCredentialsExpiryNotified records that the owners of the given
+// credentials were warned about their expiry. Reconnecting a platform clears
+// the mark.
+func (d *Database) MarkCredentialsExpiryNotified(ids []string) error {
+	_, err := d.DB.Exec(`UPDATE credentials SET expiry_notified_at = $1 WHERE id = ANY($2)`, time.Now(), pq.Array(ids))
+	return err
+}
+
+// DeleteAllCredentials removes every platform credential of a user and
+// returns the platforms that were disconnected.
+func (d *Database) DeleteAllCredentials(userID string) ([]models.Platform, error) {
+	rows, err := d.DB.Query(`DELETE FROM credentials WHERE user_id = $1 RETURNING platform`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	platforms := []models.Platform{}
+	for rows.Next() {
+		var platform string
+		if err := rows.Scan(&platform); err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, models.Platform(platform))
+	}
+	return platforms, rows.Err()
+}
+
 func (d *Database) SavePublishResult(postID string, result models.PublishResult) error {
-	query := `INSERT INTO publish_results (post_id, platform, success, message, external_post_id)
-			  VALUES ($1, $2, $3, $4, $5)`
+	query := `INSERT INTO publish_results (post_id, platform, success, message, external_post_id, needs_reauth, error_code,
+			  error_category)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
 	_, err := d.DB.Exec(query, postID, result.Platform, result.Success,
-		result.Message, result.PostID)
+		result.Message, result.PostID, result.NeedsReauth, result.ErrorCode, result.ErrorCategory)
 	return err
+}
+
+// DeleteOldPublishResults removes publish results created before olderThan
+// and returns how many were removed. The latest result and the latest
+// successful result of each post and platform are always kept, so the
+// outcome and external post ID stay available.
+func (d *Database) DeleteOldPublishResults(olderThan time.Time) (int64, error) {
+	result, err := d.DB.Exec(`DELETE FROM publish_results
+			  WHERE created_at < $1
+			  AND id NOT IN (
+				  SELECT DISTINCT ON (post_id, platform) id FROM publish_results
+				  ORDER BY post_id, platform, created_at DESC, id DESC
+			  )
+			  AND id NOT IN (
+				  SELECT DISTINCT ON (post_id, platform) id FROM publish_results
+				  WHERE success = true
+				  ORDER BY post_id, platform, created_at DESC, id DESC
+			  )`, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
\ No newline at end of file
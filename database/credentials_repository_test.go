@@ -0,0 +1,57 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"SocialMediaAPI/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSaveCredentialsReconnectKeepsConnectionDate(t *testing.T) {
+	db := openTestDB(t)
+
+	user := &models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x", Name: "reconnect test", CreatedAt: time.Now()}
+	if err := db.CreateUser(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+	connect := func(token string) *models.PlatformCredentials {
+		cred := &models.PlatformCredentials{
+			ID:             uuid.New().String(),
+			UserID:         user.ID,
+			Platform:       models.Twitter,
+			AccessToken:    token,
+			TokenType:      "Bearer",
+			PlatformUserID: "12345",
+			PlatformPageID: "",
+		}
+		if err := db.SaveCredentials(cred); err != nil {
+			t.Fatalf("save credentials: %v", err)
+		}
+		stored, err := db.GetCredentialsByID(user.ID, cred.ID)
+		if err != nil {
+			t.Fatalf("load credentials: %v", err)
+		}
+		return stored
+	}
+
+	first := connect("first-token")
+	time.Sleep(10 * time.Millisecond)
+	second := connect("second-token")
+
+	if second.ID != first.ID {
+		t.Errorf("id changed on reconnect: %s -> %s", first.ID, second.ID)
+	}
+	if !second.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("created_at changed on reconnect: %v -> %v", first.CreatedAt, second.CreatedAt)
+	}
+	if !second.UpdatedAt.After(first.UpdatedAt) {
+		t.Errorf("updated_at did not advance: %v -> %v", first.UpdatedAt, second.UpdatedAt)
+	}
+	if second.AccessToken != "second-token" {
+		t.Errorf("access token = %q, want the reconnected token", second.AccessToken)
+	}
+}
@@ -37,6 +37,18 @@ func (d *Database) createTables() error {
 			name VARCHAR(255) NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+		// Migration: add role column to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='users' AND column_name='role') THEN
+				ALTER TABLE users ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user';
+			END IF;
+		END $$;`,
+		// Migration: add tokens_revoked_at column (session revocation) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='users' AND column_name='tokens_revoked_at') THEN
+				ALTER TABLE users ADD COLUMN tokens_revoked_at TIMESTAMP;
+			END IF;
+		END $$;`,
 		`CREATE TABLE IF NOT EXISTS media (
 			id VARCHAR(255) PRIMARY KEY,
 			user_id VARCHAR(255) NOT NULL,
@@ -49,6 +61,32 @@ func (d *Database) createTables() error {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
+		// Migration: add alt_text column (accessibility description) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='media' AND column_name='alt_text') THEN
+				ALTER TABLE media ADD COLUMN alt_text TEXT NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add twitter_media_category column (upload category override) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='media' AND column_name='twitter_media_category') THEN
+				ALTER TABLE media ADD COLUMN twitter_media_category VARCHAR(50) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add is_animated column (animated WebP detection) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='media' AND column_name='is_animated') THEN
+				ALTER TABLE media ADD COLUMN is_animated BOOLEAN NOT NULL DEFAULT false;
+			END IF;
+		END $$;`,
+		// Migration: add video resolution and bitrate columns to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='media' AND column_name='width') THEN
+				ALTER TABLE media ADD COLUMN width INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE media ADD COLUMN height INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE media ADD COLUMN bitrate_kbps INTEGER NOT NULL DEFAULT 0;
+			END IF;
+		END $$;`,
 		`CREATE TABLE IF NOT EXISTS posts (
 			id VARCHAR(255) PRIMARY KEY,
 			user_id VARCHAR(255) NOT NULL,
@@ -63,7 +101,7 @@ func (d *Database) createTables() error {
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
-		// Migration: add post_type column to existing tables
+		// Migration: add post_type column to existing tables; existing rows become 'normal' posts
 		`DO $$ BEGIN
 			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='post_type') THEN
 				ALTER TABLE posts ADD COLUMN post_type VARCHAR(50) NOT NULL DEFAULT 'normal';
@@ -81,6 +119,77 @@ func (d *Database) createTables() error {
 				ALTER TABLE posts ADD COLUMN privacy_level VARCHAR(50) NOT NULL DEFAULT 'public';
 			END IF;
 		END $$;`,
+		// Migration: add category_id column (YouTube video category) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='category_id') THEN
+				ALTER TABLE posts ADD COLUMN category_id VARCHAR(50) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add subtitles columns (SRT captions for video posts) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='subtitles') THEN
+				ALTER TABLE posts ADD COLUMN subtitles TEXT NOT NULL DEFAULT '';
+				ALTER TABLE posts ADD COLUMN subtitle_language VARCHAR(20) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add retry tracking columns (automatic retry of failed scheduled posts) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='retry_count') THEN
+				ALTER TABLE posts ADD COLUMN retry_count INTEGER NOT NULL DEFAULT 0;
+				ALTER TABLE posts ADD COLUMN next_retry_at TIMESTAMP;
+			END IF;
+		END $$;`,
+		// Migration: add allow_truncation column (cut content to platform limits) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='allow_truncation') THEN
+				ALTER TABLE posts ADD COLUMN allow_truncation BOOLEAN NOT NULL DEFAULT false;
+			END IF;
+		END $$;`,
+		// Migration: add made_for_kids column (YouTube audience declaration; NULL = not declared) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='made_for_kids') THEN
+				ALTER TABLE posts ADD COLUMN made_for_kids BOOLEAN;
+			END IF;
+		END $$;`,
+		// Migration: add YouTube tags and language columns to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='tags') THEN
+				ALTER TABLE posts ADD COLUMN tags TEXT[];
+				ALTER TABLE posts ADD COLUMN default_language VARCHAR(20) NOT NULL DEFAULT '';
+				ALTER TABLE posts ADD COLUMN default_audio_language VARCHAR(20) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add thumbnail_media_id column (Facebook Reel cover) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='thumbnail_media_id') THEN
+				ALTER TABLE posts ADD COLUMN thumbnail_media_id VARCHAR(255) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add test mode columns (publish to a single test platform) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='test_mode') THEN
+				ALTER TABLE posts ADD COLUMN test_mode BOOLEAN NOT NULL DEFAULT false;
+				ALTER TABLE posts ADD COLUMN test_platform VARCHAR(50) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add accounts column (connected account chosen per platform) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='accounts') THEN
+				ALTER TABLE posts ADD COLUMN accounts JSONB;
+			END IF;
+		END $$;`,
+		// Migration: add platform_content column (per-platform content overrides) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='platform_content') THEN
+				ALTER TABLE posts ADD COLUMN platform_content JSONB;
+			END IF;
+		END $$;`,
+		// Migration: add threading_enabled column (long content as a Twitter thread) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='posts' AND column_name='threading_enabled') THEN
+				ALTER TABLE posts ADD COLUMN threading_enabled BOOLEAN NOT NULL DEFAULT false;
+			END IF;
+		END $$;`,
 		`CREATE TABLE IF NOT EXISTS credentials (
 			id VARCHAR(255) PRIMARY KEY,
 			user_id VARCHAR(255) NOT NULL,
@@ -97,6 +206,60 @@ func (d *Database) createTables() error {
 			UNIQUE(user_id, platform),
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
+		`CREATE TABLE IF NOT EXISTS organizations (
+			id VARCHAR(255) PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			owner_id VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS organization_members (
+			organization_id VARCHAR(255) NOT NULL,
+			user_id VARCHAR(255) NOT NULL,
+			role VARCHAR(50) NOT NULL DEFAULT 'member',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (organization_id, user_id),
+			FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		// Migration: add organization_id column (org-shared credentials) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='credentials' AND column_name='organization_id') THEN
+				ALTER TABLE credentials ADD COLUMN organization_id VARCHAR(255) REFERENCES organizations(id) ON DELETE SET NULL;
+			END IF;
+		END $$;`,
+		// Migration: add platform_username/platform_display_name columns (connected account identity) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='credentials' AND column_name='platform_username') THEN
+				ALTER TABLE credentials ADD COLUMN platform_username VARCHAR(255) NOT NULL DEFAULT '';
+				ALTER TABLE credentials ADD COLUMN platform_display_name VARCHAR(255) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add expiry_notified_at column (token-expiry reminder emails) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='credentials' AND column_name='expiry_notified_at') THEN
+				ALTER TABLE credentials ADD COLUMN expiry_notified_at TIMESTAMP;
+			END IF;
+		END $$;`,
+		// Migration: add granted_scopes column (scopes reported on connect); NULL = not reported
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='credentials' AND column_name='granted_scopes') THEN
+				ALTER TABLE credentials ADD COLUMN granted_scopes TEXT[];
+			END IF;
+		END $$;`,
+		// Migration: one credential per connected account instead of per platform, so a second
+		// Page or channel no longer overwrites the first
+		`DO $$ BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.table_constraints WHERE table_name='credentials' AND constraint_name='credentials_user_id_platform_key') THEN
+				UPDATE credentials SET platform_user_id = '' WHERE platform_user_id IS NULL;
+				UPDATE credentials SET platform_page_id = '' WHERE platform_page_id IS NULL;
+				ALTER TABLE credentials ALTER COLUMN platform_user_id SET DEFAULT '', ALTER COLUMN platform_user_id SET NOT NULL,
+					ALTER COLUMN platform_page_id SET DEFAULT '', ALTER COLUMN platform_page_id SET NOT NULL;
+				ALTER TABLE credentials DROP CONSTRAINT credentials_user_id_platform_key;
+				ALTER TABLE credentials ADD CONSTRAINT credentials_user_id_platform_account_key
+					UNIQUE (user_id, platform, platform_user_id, platform_page_id);
+			END IF;
+		END $$;`,
 		`CREATE TABLE IF NOT EXISTS publish_results (
 			id SERIAL PRIMARY KEY,
 			post_id VARCHAR(255) NOT NULL,
@@ -107,6 +270,127 @@ func (d *Database) createTables() error {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
 		)`,
+		// Migration: add needs_reauth/error_code columns to existing publish_results tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='publish_results' AND column_name='needs_reauth') THEN
+				ALTER TABLE publish_results ADD COLUMN needs_reauth BOOLEAN NOT NULL DEFAULT FALSE;
+				ALTER TABLE publish_results ADD COLUMN error_code VARCHAR(100) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		// Migration: add error_category column to existing publish_results tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='publish_results' AND column_name='error_category') THEN
+				ALTER TABLE publish_results ADD COLUMN error_category VARCHAR(50) NOT NULL DEFAULT '';
+			END IF;
+		END $$;`,
+		`CREATE INDEX IF NOT EXISTS idx_publish_results_post_platform ON publish_results (post_id, platform, created_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS publish_jobs (
+			id SERIAL PRIMARY KEY,
+			post_id VARCHAR(255) NOT NULL,
+			platform VARCHAR(50) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (post_id, platform),
+			FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_publish_jobs_due ON publish_jobs (status, next_attempt_at)`,
+		// post_platform_status is filled from publish_results when it is created,
+		// so posts published before it keep their outcome
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name='post_platform_status') THEN
+				CREATE TABLE post_platform_status (
+					post_id VARCHAR(255) NOT NULL,
+					platform VARCHAR(50) NOT NULL,
+					status VARCHAR(20) NOT NULL,
+					external_id VARCHAR(255) NOT NULL DEFAULT '',
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (post_id, platform),
+					FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+				);
+				INSERT INTO post_platform_status (post_id, platform, status, external_id, updated_at)
+				SELECT DISTINCT ON (post_id, platform) post_id, platform,
+					CASE WHEN success THEN 'published' ELSE 'failed' END,
+					COALESCE(external_post_id, ''), created_at
+				FROM publish_results
+				ORDER BY post_id, platform, success DESC, created_at DESC, id DESC;
+			END IF;
+		END $$;`,
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id SERIAL PRIMARY KEY,
+			user_id VARCHAR(255) NOT NULL,
+			actor_id VARCHAR(255) NOT NULL DEFAULT '',
+			action VARCHAR(100) NOT NULL,
+			platform VARCHAR(50) NOT NULL DEFAULT '',
+			post_id VARCHAR(255) NOT NULL DEFAULT '',
+			success BOOLEAN NOT NULL DEFAULT true,
+			details TEXT NOT NULL DEFAULT '',
+			ip VARCHAR(100) NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_user_created ON audit_log (user_id, created_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS user_settings (
+			user_id VARCHAR(255) PRIMARY KEY,
+			publish_window_start VARCHAR(5) NOT NULL DEFAULT '',
+			publish_window_end VARCHAR(5) NOT NULL DEFAULT '',
+			timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		// Migration: add notify_publish_failures column (scheduled-post failure emails) to existing tables
+		`DO $$ BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name='user_settings' AND column_name='notify_publish_failures') THEN
+				ALTER TABLE user_settings ADD COLUMN notify_publish_failures BOOLEAN NOT NULL DEFAULT false;
+			END IF;
+		END $$;`,
+		`CREATE TABLE IF NOT EXISTS media_uploads (
+			id VARCHAR(255) PRIMARY KEY,
+			user_id VARCHAR(255) NOT NULL,
+			filename VARCHAR(255) NOT NULL,
+			upload_length BIGINT NOT NULL,
+			upload_offset BIGINT NOT NULL DEFAULT 0,
+			alt_text TEXT NOT NULL DEFAULT '',
+			twitter_media_category VARCHAR(50) NOT NULL DEFAULT '',
+			media_id VARCHAR(255) NOT NULL DEFAULT '',
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_media_uploads_expires ON media_uploads (expires_at)`,
+		`CREATE TABLE IF NOT EXISTS templates (
+			id VARCHAR(255) PRIMARY KEY,
+			user_id VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			content TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_templates_user ON templates (user_id)`,
+		`CREATE TABLE IF NOT EXISTS password_resets (
+			id VARCHAR(255) PRIMARY KEY,
+			user_id VARCHAR(255) NOT NULL,
+			token_hash VARCHAR(64) NOT NULL UNIQUE,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_password_resets_user ON password_resets (user_id)`,
+		`CREATE TABLE IF NOT EXISTS oauth_states (
+			state VARCHAR(64) PRIMARY KEY,
+			user_id VARCHAR(255) NOT NULL,
+			platform VARCHAR(50) NOT NULL,
+			code_verifier TEXT NOT NULL DEFAULT '',
+			used_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_oauth_states_created ON oauth_states (created_at)`,
 	}
 
 	for _, query := range queries {
@@ -0,0 +1,24 @@
+package database
+
+import (
+	"os"
+	"testing"
+)
+
+// openTestDB connects to the database in DATABASE_URL, creating the schema,
+// and skips the test when it is not set. Tests create their own rows with
+// random ids and delete them afterwards, but should still be pointed at a
+// dedicated database.
+func openTestDB(t *testing.T) *Database {
+	t.Helper()
+	connStr := os.Getenv("DATABASE_URL")
+	if connStr == "" {
+		t.Skip("DATABASE_URL not set")
+	}
+	db, err := NewDatabase(connStr)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Close() })
+	return db
+}
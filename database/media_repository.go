@@ -2,73 +2,96 @@ package database
 
 import (
 	"SocialMediaAPI/models"
+	"time"
 
 	"github.com/lib/pq"
 )
 
-func (d *Database) CreateMedia(media *models.Media) error {
-	query := `INSERT INTO media (id, user_id, filename, path, url, type, size, mime_type, created_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := d.DB.Exec(query, media.ID, media.UserID, media.Filename, media.Path,
-		media.URL, media.Type, media.Size, media.MimeType, media.CreatedAt)
-	return err
-}
+// mediaColumns is the column list shared by every query that loads media.
+// Keep it in sync with scanMedia.
+const mediaColumns = `id, user_id, filename, path, url, type, size, mime_type, alt_text, twitter_media_category, is_animated, width, height, bitrate_kbps, created_at`
 
-func (d *Database) GetMedia(id string) (*models.Media, error) {
+func scanMedia(row rowScanner) (*models.Media, error) {
 	media := &models.Media{}
-	query := `SELECT id, user_id, filename, path, url, type, size, mime_type, created_at
-			  FROM media WHERE id = $1`
-	err := d.DB.QueryRow(query, id).Scan(&media.ID, &media.UserID, &media.Filename,
-		&media.Path, &media.URL, &media.Type, &media.Size, &media.MimeType, &media.CreatedAt)
+	err := row.Scan(&media.ID, &media.UserID, &media.Filename, &media.Path,
+		&media.URL, &media.Type, &media.Size, &media.MimeType, &media.AltText, &media.TwitterMediaCategory, &media.IsAnimated, &media.Width, &media.Height, &media.BitrateKbps, &media.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return media, nil
 }
 
-func (d *Database) GetMediaByIDs(ids []string) ([]*models.Media, error) {
-	if len(ids) == 0 {
-		return []*models.Media{}, nil
-	}
+func (d *Database) CreateMedia(media *models.Media) error {
+	query := `INSERT INTO media (id, user_id, filename, path, url, type, size, mime_type, alt_text, twitter_media_category, is_animated, width, height, bitrate_kbps, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+	_, err := d.DB.Exec(query, media.ID, media.UserID, media.Filename, media.Path,
+		media.URL, media.Type, media.Size, media.MimeType, media.AltText, media.TwitterMediaCategory, media.IsAnimated, media.Width, media.Height, media.BitrateKbps, media.CreatedAt)
+	return err
+}
 
-	query := `SELECT id, user_id, filename, path, url, type, size, mime_type, created_at
-			  FROM media WHERE id = ANY($1)`
+func (d *Database) GetMedia(id string) (*models.Media, error) {
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE id = $1`
+	return scanMedia(d.DB.QueryRow(query, id))
+}
 
-	rows, err := d.DB.Query(query, pq.Array(ids))
+// mediaFilter is the WHERE clause of GetUserMediaFiltered: $1 user ID,
+// $2 media type or '', $3/$4 created_at range or NULL.
+const mediaFilter = `WHERE user_id = $1
+			  AND ($2 = '' OR type = $2)
+			  AND ($3::timestamp IS NULL OR created_at >= $3)
+			  AND ($4::timestamp IS NULL OR created_at < $4)`
+
+// GetUserMediaFiltered returns a page of userID's media, newest first,
+// along with the total number of items matching the filters. An empty
+// mediaType, nil from/to and a zero limit each disable that filter.
+func (d *Database) GetUserMediaFiltered(userID string, mediaType models.MediaType, from, to *time.Time, limit, offset int) ([]*models.Media, int, error) {
+	query := `SELECT ` + mediaColumns + `, COUNT(*) OVER () FROM media ` + mediaFilter + `
+			  ORDER BY created_at DESC
+			  LIMIT NULLIF($5, 0) OFFSET $6`
+
+	rows, err := d.DB.Query(query, userID, string(mediaType), from, to, limit, offset)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
 	mediaList := []*models.Media{}
+	total := 0
 	for rows.Next() {
 		media := &models.Media{}
 		err := rows.Scan(&media.ID, &media.UserID, &media.Filename, &media.Path,
-			&media.URL, &media.Type, &media.Size, &media.MimeType, &media.CreatedAt)
+			&media.URL, &media.Type, &media.Size, &media.MimeType, &media.AltText, &media.TwitterMediaCategory, &media.IsAnimated, &media.Width, &media.Height, &media.BitrateKbps, &media.CreatedAt, &total)
 		if err != nil {
-			continue
+			return nil, 0, err
 		}
 		mediaList = append(mediaList, media)
 	}
-
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return mediaList, nil
-}
+	// An offset past the end returns no rows, and so no window count
+	if len(mediaList) == 0 && offset > 0 {
+		err := d.DB.QueryRow(`SELECT COUNT(*) FROM media `+mediaFilter, userID, string(mediaType), from, to).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
 
-func (d *Database) DeleteMedia(id string) error {
-	query := `DELETE FROM media WHERE id = $1`
-	_, err := d.DB.Exec(query, id)
-	return err
+	return mediaList, total, nil
 }
 
-func (d *Database) GetUserMedia(userID string) ([]*models.Media, error) {
-	query := `SELECT id, user_id, filename, path, url, type, size, mime_type, created_at
-			  FROM media WHERE user_id = $1 ORDER BY created_at DESC`
+func (d *Database) GetMediaByIDs(ids []string) ([]*models.Media, error) {
+	if len(ids) == 0 {
+		return []*models.Media{}, nil
+	}
+
+	// Return rows in the order of ids so carousels and albums keep the
+	// order the user attached the media in.
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE id = ANY($1)
+			  ORDER BY array_position($1::text[], id::text)`
 
-	rows, err := d.DB.Query(query, userID)
+	rows, err := d.DB.Query(query, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
@@ -76,14 +99,30 @@ func (d *Database) GetUserMedia(userID string) ([]*models.Media, error) {
 
 	mediaList := []*models.Media{}
 	for rows.Next() {
-		media := &models.Media{}
-		err := rows.Scan(&media.ID, &media.UserID, &media.Filename, &media.Path,
-			&media.URL, &media.Type, &media.Size, &media.MimeType, &media.CreatedAt)
+		media, err := scanMedia(rows)
 		if err != nil {
 			continue
 		}
 		mediaList = append(mediaList, media)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return mediaList, nil
-}
\ No newline at end of file
+}
+
+// UpdateMediaMetadata saves the user-editable fields of a media item.
+func (d *Database) UpdateMediaMetadata(media *models.Media) error {
+	query := `UPDATE media SET alt_text = $1, twitter_media_category = $2 WHERE id = $3`
+	_, err := d.DB.Exec(query, media.AltText, media.TwitterMediaCategory, media.ID)
+	return err
+}
+
+func (d *Database) DeleteMedia(id string) error {
+	query := `DELETE FROM media WHERE id = $1`
+	_, err := d.DB.Exec(query, id)
+	return err
+}
+
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"database/sql"
+	"time"
+)
+
+func (d *Database) CreateOAuthState(state *models.OAuthState) error {
+	query := `INSERT INTO oauth_states (state, user_id, platform, code_verifier, created_at)
+			  VALUES ($1, $2, $3, $4, $5)`
+	_, err := d.DB.Exec(query, state.State, state.UserID, state.Platform, state.CodeVerifier, state.CreatedAt)
+	return err
+}
+
+// SetOAuthCodeVerifier attaches a PKCE code verifier to an unused state.
+func (d *Database) SetOAuthCodeVerifier(state, codeVerifier string) error {
+	_, err := d.DB.Exec(`UPDATE oauth_states SET code_verifier = $1 WHERE state = $2 AND used_at IS NULL`,
+		codeVerifier, state)
+	return err
+}
+
+// ConsumeOAuthState atomically marks the unused state created after
+// notBefore as used and returns it. It returns nil when there is no such
+// state, so a state can never be redeemed twice. The row itself is kept
+// until its code verifier has been taken with TakeOAuthCodeVerifier.
+func (d *Database) ConsumeOAuthState(state string, notBefore time.Time) (*models.OAuthState, error) {
+	query := `UPDATE oauth_states SET used_at = $1
+			  WHERE state = $2 AND used_at IS NULL AND created_at > $3
+			  RETURNING state, user_id, platform, code_verifier, created_at, used_at`
+
+	s := &models.OAuthState{}
+	err := d.DB.QueryRow(query, time.Now(), state, notBefore).Scan(&s.State, &s.UserID, &s.Platform,
+		&s.CodeVerifier, &s.CreatedAt, &s.UsedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// TakeOAuthCodeVerifier deletes a state and returns its code verifier, or ""
+// when the state does not exist.
+func (d *Database) TakeOAuthCodeVerifier(state string) (string, error) {
+	var codeVerifier string
+	err := d.DB.QueryRow(`DELETE FROM oauth_states WHERE state = $1 RETURNING code_verifier`, state).Scan(&codeVerifier)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return codeVerifier, err
+}
+
+// DeleteOAuthState removes a state, used or not.
+func (d *Database) DeleteOAuthState(state string) error {
+	_, err := d.DB.Exec(`DELETE FROM oauth_states WHERE state = $1`, state)
+	return err
+}
+
+// DeleteExpiredOAuthStates removes states created before the given time and
+// returns how many were removed.
+func (d *Database) DeleteExpiredOAuthStates(before time.Time) (int64, error) {
+	result, err := d.DB.Exec(`DELETE FROM oauth_states WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"database/sql"
+	"time"
+)
+
+// CreateOrganization inserts the organization and adds its owner as the first
+// member in a single transaction.
+func (d *Database) CreateOrganization(org *models.Organization) error {
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO organizations (id, name, owner_id, created_at) VALUES ($1, $2, $3, $4)`,
+		org.ID, org.Name, org.OwnerID, org.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role, created_at) VALUES ($1, $2, $3, $4)`,
+		org.ID, org.OwnerID, models.OrgRoleOwner, org.CreatedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetUserOrganizations returns the organizations the user belongs to, with
+// Role set to the user's membership role.
+func (d *Database) GetUserOrganizations(userID string) ([]*models.Organization, error) {
+	query := `SELECT o.id, o.name, o.owner_id, m.role, o.created_at
+			  FROM organizations o
+			  JOIN organization_members m ON m.organization_id = o.id
+			  WHERE m.user_id = $1
+			  ORDER BY o.created_at`
+
+	rows, err := d.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []*models.Organization{}
+	for rows.Next() {
+		org := &models.Organization{}
+		if err := rows.Scan(&org.ID, &org.Name, &org.OwnerID, &org.Role, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
+// GetMembershipRole returns the user's role in the organization, or "" when
+// the user is not a member.
+func (d *Database) GetMembershipRole(orgID, userID string) (string, error) {
+	var role string
+	err := d.DB.QueryRow(`SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2`,
+		orgID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+func (d *Database) GetOrganizationMembers(orgID string) ([]*models.OrganizationMember, error) {
+	query := `SELECT m.organization_id, m.user_id, u.email, u.name, m.role, m.created_at
+			  FROM organization_members m
+			  JOIN users u ON u.id = m.user_id
+			  WHERE m.organization_id = $1
+			  ORDER BY m.created_at`
+
+	rows, err := d.DB.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.OrganizationMember{}
+	for rows.Next() {
+		m := &models.OrganizationMember{}
+		if err := rows.Scan(&m.OrganizationID, &m.UserID, &m.Email, &m.Name, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// AddOrganizationMember adds a user to the organization. Adding an existing
+// member is a no-op.
+func (d *Database) AddOrganizationMember(orgID, userID, role string) error {
+	query := `INSERT INTO organization_members (organization_id, user_id, role, created_at)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (organization_id, user_id) DO NOTHING`
+	_, err := d.DB.Exec(query, orgID, userID, role, time.Now())
+	return err
+}
+
+// RemoveOrganizationMember removes a user from the organization and stops
+// sharing that user's credentials with it.
+func (d *Database) RemoveOrganizationMember(orgID, userID string) (bool, error) {
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`UPDATE credentials SET organization_id = NULL WHERE organization_id = $1 AND user_id = $2`,
+		orgID, userID); err != nil {
+		return false, err
+	}
+
+	n, _ := result.RowsAffected()
+	return n > 0, tx.Commit()
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"database/sql"
+	"time"
+)
+
+func (d *Database) CreatePasswordReset(reset *models.PasswordReset) error {
+	query := `INSERT INTO password_resets (id, user_id, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5)`
+	_, err := d.DB.Exec(query, reset.ID, reset.UserID, reset.TokenHash, reset.ExpiresAt, reset.CreatedAt)
+	return err
+}
+
+// ConsumePasswordReset atomically marks the unused, unexpired reset with the
+// given token hash as used and returns it. It returns nil when there is no
+// such reset, so a token can never be redeemed twice.
+func (d *Database) ConsumePasswordReset(tokenHash string) (*models.PasswordReset, error) {
+	query := `UPDATE password_resets SET used_at = $1
+			  WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+			  RETURNING id, user_id, token_hash, expires_at, used_at, created_at`
+
+	reset := &models.PasswordReset{}
+	err := d.DB.QueryRow(query, time.Now(), tokenHash).Scan(&reset.ID, &reset.UserID, &reset.TokenHash,
+		&reset.ExpiresAt, &reset.UsedAt, &reset.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reset, nil
+}
+
+// DeletePasswordResets removes every reset of a user, so only the most
+// recently requested token stays valid.
+func (d *Database) DeletePasswordResets(userID string) error {
+	_, err := d.DB.Exec(`DELETE FROM password_resets WHERE user_id = $1`, userID)
+	return err
+}
+
+// DeleteExpiredPasswordResets removes resets that expired before the given
+// time and returns how many were removed.
+func (d *Database) DeleteExpiredPasswordResets(before time.Time) (int64, error) {
+	result, err := d.DB.Exec(`DELETE FROM password_resets WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
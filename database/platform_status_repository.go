@@ -0,0 +1,42 @@
+package database
+
+import "SocialMediaAPI/models"
+
+// SetPlatformStatus records the outcome of an attempt to publish a post to a
+// platform. A platform stays published once it succeeded, so a later failed
+// attempt (e.g. a duplicate run of its job) doesn't hide the post there.
+func (d *Database) SetPlatformStatus(postID string, result models.PublishResult) error {
+	status := models.StatusFailed
+	if result.Success {
+		status = models.StatusPublished
+	}
+
+	query := `INSERT INTO post_platform_status (post_id, platform, status, external_id, updated_at)
+			  VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+			  ON CONFLICT (post_id, platform) DO UPDATE
+			  SET status = EXCLUDED.status, external_id = EXCLUDED.external_id, updated_at = EXCLUDED.updated_at
+			  WHERE post_platform_status.status <> $5 OR EXCLUDED.status = $5`
+	_, err := d.DB.Exec(query, postID, result.Platform, status, result.PostID, models.StatusPublished)
+	return err
+}
+
+// GetPlatformStatuses returns the outcome of publishing a post to each
+// platform attempted so far, by platform.
+func (d *Database) GetPlatformStatuses(postID string) ([]models.PostPlatformStatus, error) {
+	rows, err := d.DB.Query(`SELECT post_id, platform, status, external_id, updated_at FROM post_platform_status
+			  WHERE post_id = $1 ORDER BY platform`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := []models.PostPlatformStatus{}
+	for rows.Next() {
+		var s models.PostPlatformStatus
+		if err := rows.Scan(&s.PostID, &s.Platform, &s.Status, &s.ExternalID, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, rows.Err()
+}
@@ -2,29 +2,105 @@ package database
 
 import (
 	"SocialMediaAPI/models"
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
 )
 
+// postColumns is the column list shared by every query that loads a full post.
+// Keep it in sync with scanPost.
+const postColumns = `id, user_id, content, post_type, privacy_level, is_sponsored, allow_truncation, made_for_kids, media_ids, platforms, status,
+			  category_id, tags, default_language, default_audio_language, thumbnail_media_id, subtitles, subtitle_language, scheduled_for,
+			  published_at, retry_count, next_retry_at, test_mode, test_platform, accounts, platform_content, threading_enabled, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPost reads a row selected with postColumns into a Post and loads its media.
+func (d *Database) scanPost(row rowScanner) (*models.Post, error) {
+	post := &models.Post{}
+	var platforms []string
+	var mediaIDs []string
+	var accounts []byte
+	var platformContent []byte
+
+	err := row.Scan(&post.ID, &post.UserID, &post.Content, &post.PostType, &post.PrivacyLevel, &post.IsSponsored, &post.AllowTruncation, &post.MadeForKids,
+		pq.Array(&mediaIDs), pq.Array(&platforms), &post.Status, &post.CategoryID,
+		pq.Array(&post.Tags), &post.DefaultLanguage, &post.DefaultAudioLanguage, &post.ThumbnailMediaID,
+		&post.Subtitles, &post.SubtitleLanguage, &post.ScheduledFor, &post.PublishedAt, &post.RetryCount, &post.NextRetryAt,
+		&post.TestMode, &post.TestPlatform, &accounts, &platformContent, &post.ThreadingEnabled, &post.CreatedAt, &post.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if accounts != nil {
+		if err := json.Unmarshal(accounts, &post.Accounts); err != nil {
+			return nil, err
+		}
+	}
+	if platformContent != nil {
+		if err := json.Unmarshal(platformContent, &post.PlatformContent); err != nil {
+			return nil, err
+		}
+	}
+
+	post.Platforms = make([]models.Platform, len(platforms))
+	for i, p := range platforms {
+		post.Platforms[i] = models.Platform(p)
+	}
+
+	if mediaIDs != nil {
+		post.MediaIDs = mediaIDs
+		post.Media, _ = d.GetMediaByIDs(mediaIDs)
+	}
+
+	if post.ThumbnailMediaID != "" {
+		post.Thumbnail, _ = d.GetMedia(post.ThumbnailMediaID)
+	}
+
+	return post, nil
+}
+
+// platformMapJSON encodes a per-platform map of a post (its accounts or
+// platform_content) for a JSONB column, NULL when it is empty.
+func platformMapJSON(m map[models.Platform]string) interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(m)
+	return string(data)
+}
+
 func (d *Database) CreatePost(post *models.Post) error {
-	query := `INSERT INTO posts (id, user_id, content, post_type, privacy_level, is_sponsored, media_ids, platforms, status, scheduled_for, created_at, updated_at)
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	query := `INSERT INTO posts (id, user_id, content, post_type, privacy_level, is_sponsored, allow_truncation, made_for_kids, media_ids, platforms, status,
+			  category_id, tags, default_language, default_audio_language, thumbnail_media_id, subtitles, subtitle_language, scheduled_for,
+			  test_mode, test_platform, accounts, platform_content, threading_enabled, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26)`
 
 	platforms := make([]string, len(post.Platforms))
 	for i, p := range post.Platforms {
 		platforms[i] = string(p)
 	}
 
-	_, err := d.DB.Exec(query, post.ID, post.UserID, post.Content, post.PostType, post.PrivacyLevel, post.IsSponsored, pq.Array(post.MediaIDs),
-		pq.Array(platforms), post.Status, post.ScheduledFor, post.CreatedAt, post.UpdatedAt)
+	_, err := d.DB.Exec(query, post.ID, post.UserID, post.Content, post.PostType, post.PrivacyLevel, post.IsSponsored, post.AllowTruncation, post.MadeForKids,
+		pq.Array(post.MediaIDs), pq.Array(platforms), post.Status, post.CategoryID,
+		pq.Array(post.Tags), post.DefaultLanguage, post.DefaultAudioLanguage, post.ThumbnailMediaID, post.Subtitles, post.SubtitleLanguage, post.ScheduledFor,
+		post.TestMode, post.TestPlatform, platformMapJSON(post.Accounts), platformMapJSON(post.PlatformContent), post.ThreadingEnabled, post.CreatedAt, post.UpdatedAt)
 	return err
 }
 
 func (d *Database) UpdatePost(post *models.Post) error {
-	query := `UPDATE posts SET content = $1, post_type = $2, privacy_level = $3, is_sponsored = $4, media_ids = $5, platforms = $6, 
-			  status = $7, scheduled_for = $8, published_at = $9, updated_at = $10
-			  WHERE id = $11`
+	query := `UPDATE posts SET content = $1, post_type = $2, privacy_level = $3, is_sponsored = $4, media_ids = $5, platforms = $6,
+			  status = $7, category_id = $8, subtitles = $9, subtitle_language = $10, scheduled_for = $11, published_at = $12,
+			  retry_count = $13, next_retry_at = $14, updated_at = $15, allow_truncation = $16, made_for_kids = $17,
+			  tags = $18, default_language = $19, default_audio_language = $20, thumbnail_media_id = $21,
+			  test_mode = $22, test_platform = $23, accounts = $24, platform_content = $25,
+			  threading_enabled = $26
+			  WHERE id = $27`
 
 	platforms := make([]string, len(post.Platforms))
 	for i, p := range post.Platforms {
@@ -32,44 +108,51 @@ func (d *Database) UpdatePost(post *models.Post) error {
 	}
 
 	_, err := d.DB.Exec(query, post.Content, post.PostType, post.PrivacyLevel, post.IsSponsored, pq.Array(post.MediaIDs), pq.Array(platforms),
-		post.Status, post.ScheduledFor, post.PublishedAt, post.UpdatedAt, post.ID)
+		post.Status, post.CategoryID, post.Subtitles, post.SubtitleLanguage, post.ScheduledFor, post.PublishedAt,
+		post.RetryCount, post.NextRetryAt, post.UpdatedAt, post.AllowTruncation, post.MadeForKids,
+		pq.Array(post.Tags), post.DefaultLanguage, post.DefaultAudioLanguage, post.ThumbnailMediaID,
+		post.TestMode, post.TestPlatform, platformMapJSON(post.Accounts), platformMapJSON(post.PlatformContent), post.ThreadingEnabled, post.ID)
 	return err
 }
 
-func (d *Database) GetPost(id string) (*models.Post, error) {
-	post := &models.Post{}
-	var platforms []string
-	var mediaIDs []string
-
-	query := `SELECT id, user_id, content, post_type, privacy_level, is_sponsored, media_ids, platforms, status, 
-			  scheduled_for, published_at, created_at, updated_at 
-			  FROM posts WHERE id = $1`
+// UpdateEditablePost saves an edited post only while it is still a draft
+// or scheduled, so an edit can't overwrite a post the scheduler claimed for
+// publishing in the meantime. It reports whether the post was updated.
+func (d *Database) UpdateEditablePost(post *models.Post) (bool, error) {
+	query := `UPDATE posts SET content = $1, post_type = $2, privacy_level = $3, is_sponsored = $4, media_ids = $5, platforms = $6,
+			  status = $7, category_id = $8, subtitles = $9, subtitle_language = $10, scheduled_for = $11, updated_at = $12,
+			  allow_truncation = $13, made_for_kids = $14, tags = $15, default_language = $16, default_audio_language = $17,
+			  thumbnail_media_id = $18, test_mode = $19, test_platform = $20, accounts = $21, platform_content = $22,
+			  threading_enabled = $23
+			  WHERE id = $24 AND status IN ($25, $26)`
 
-	err := d.DB.QueryRow(query, id).Scan(&post.ID, &post.UserID, &post.Content,
-		&post.PostType, &post.PrivacyLevel, &post.IsSponsored, pq.Array(&mediaIDs), pq.Array(&platforms), &post.Status, &post.ScheduledFor,
-		&post.PublishedAt, &post.CreatedAt, &post.UpdatedAt)
-
-	if err != nil {
-		return nil, err
+	platforms := make([]string, len(post.Platforms))
+	for i, p := range post.Platforms {
+		platforms[i] = string(p)
 	}
 
-	post.Platforms = make([]models.Platform, len(platforms))
-	for i, p := range platforms {
-		post.Platforms[i] = models.Platform(p)
+	res, err := d.DB.Exec(query, post.Content, post.PostType, post.PrivacyLevel, post.IsSponsored, pq.Array(post.MediaIDs), pq.Array(platforms),
+		post.Status, post.CategoryID, post.Subtitles, post.SubtitleLanguage, post.ScheduledFor, post.UpdatedAt,
+		post.AllowTruncation, post.MadeForKids, pq.Array(post.Tags), post.DefaultLanguage, post.DefaultAudioLanguage,
+		post.ThumbnailMediaID, post.TestMode, post.TestPlatform, platformMapJSON(post.Accounts), platformMapJSON(post.PlatformContent), post.ThreadingEnabled, post.ID,
+		models.StatusDraft, models.StatusScheduled)
+	if err != nil {
+		return false, err
 	}
-
-	if mediaIDs != nil {
-		post.MediaIDs = mediaIDs
-		post.Media, _ = d.GetMediaByIDs(mediaIDs)
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
 	}
+	return n > 0, nil
+}
 
-	return post, nil
+func (d *Database) GetPost(id string) (*models.Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE id = $1`
+	return d.scanPost(d.DB.QueryRow(query, id))
 }
 
 func (d *Database) GetUserPosts(userID string) ([]*models.Post, error) {
-	query := `SELECT id, user_id, content, post_type, privacy_level, is_sponsored, media_ids, platforms, status, 
-			  scheduled_for, published_at, created_at, updated_at 
-			  FROM posts WHERE user_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + postColumns + ` FROM posts WHERE user_id = $1 ORDER BY created_at DESC`
 
 	rows, err := d.DB.Query(query, userID)
 	if err != nil {
@@ -79,28 +162,10 @@ func (d *Database) GetUserPosts(userID string) ([]*models.Post, error) {
 
 	posts := []*models.Post{}
 	for rows.Next() {
-		post := &models.Post{}
-		var platforms []string
-		var mediaIDs []string
-
-		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.PostType, &post.PrivacyLevel, &post.IsSponsored, pq.Array(&mediaIDs),
-			pq.Array(&platforms), &post.Status, &post.ScheduledFor, &post.PublishedAt,
-			&post.CreatedAt, &post.UpdatedAt)
-
+		post, err := d.scanPost(rows)
 		if err != nil {
 			continue
 		}
-
-		post.Platforms = make([]models.Platform, len(platforms))
-		for i, p := range platforms {
-			post.Platforms[i] = models.Platform(p)
-		}
-
-		if mediaIDs != nil {
-			post.MediaIDs = mediaIDs
-			post.Media, _ = d.GetMediaByIDs(mediaIDs)
-		}
-
 		posts = append(posts, post)
 	}
 
@@ -108,9 +173,7 @@ func (d *Database) GetUserPosts(userID string) ([]*models.Post, error) {
 }
 
 func (d *Database) GetScheduledPosts() ([]*models.Post, error) {
-	query := `SELECT id, user_id, content, post_type, privacy_level, is_sponsored, media_ids, platforms, status, 
-			  scheduled_for, published_at, created_at, updated_at 
-			  FROM posts WHERE status = $1 AND scheduled_for <= $2`
+	query := `SELECT ` + postColumns + ` FROM posts WHERE status = $1 AND scheduled_for <= $2`
 
 	rows, err := d.DB.Query(query, models.StatusScheduled, time.Now())
 	if err != nil {
@@ -120,47 +183,97 @@ func (d *Database) GetScheduledPosts() ([]*models.Post, error) {
 
 	posts := []*models.Post{}
 	for rows.Next() {
-		post := &models.Post{}
-		var platforms []string
-		var mediaIDs []string
-
-		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.PostType, &post.PrivacyLevel, &post.IsSponsored, pq.Array(&mediaIDs),
-			pq.Array(&platforms), &post.Status, &post.ScheduledFor, &post.PublishedAt,
-			&post.CreatedAt, &post.UpdatedAt)
-
+		post, err := d.scanPost(rows)
 		if err != nil {
 			continue
 		}
+		posts = append(posts, post)
+	}
 
-		post.Platforms = make([]models.Platform, len(platforms))
-		for i, p := range platforms {
-			post.Platforms[i] = models.Platform(p)
-		}
+	return posts, nil
+}
+
+// GetPostCountsByStatus returns how many posts userID has in each status.
+// Statuses without posts are absent from the map.
+func (d *Database) GetPostCountsByStatus(userID string) (map[models.PostStatus]int, error) {
+	rows, err := d.DB.Query(`SELECT status, COUNT(*) FROM posts WHERE user_id = $1 GROUP BY status`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-		if mediaIDs != nil {
-			post.MediaIDs = mediaIDs
-			post.Media, _ = d.GetMediaByIDs(mediaIDs)
+	counts := make(map[models.PostStatus]int)
+	for rows.Next() {
+		var status models.PostStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
 		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
 
+// ClaimScheduledPosts atomically transitions up to limit due scheduled posts,
+// oldest first, to "publishing" status and returns them. This prevents
+// duplicate publishes when the scheduler fires again before the previous batch
+// finishes; SKIP LOCKED lets concurrent claims split the backlog instead of
+// waiting on each other. Posts of excludeUserIDs (users outside their publish
+// window) stay scheduled.
+func (d *Database) ClaimScheduledPosts(excludeUserIDs []string, limit int) ([]*models.Post, error) {
+	query := `UPDATE posts
+			  SET status = $1, updated_at = $2
+			  WHERE id IN (
+				  SELECT id FROM posts
+				  WHERE status = $3 AND scheduled_for <= $4 AND NOT (user_id = ANY($5))
+				  ORDER BY scheduled_for
+				  LIMIT $6
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING ` + postColumns
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []string{}
+	}
+	now := time.Now()
+	rows, err := d.DB.Query(query, models.StatusPublishing, now, models.StatusScheduled, now, pq.Array(excludeUserIDs), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*models.Post{}
+	for rows.Next() {
+		post, err := d.scanPost(rows)
+		if err != nil {
+			continue
+		}
 		posts = append(posts, post)
 	}
 
 	return posts, nil
 }
 
-// ClaimScheduledPosts atomically transitions due scheduled posts to "publishing"
-// status and returns them. This prevents duplicate publishes when the scheduler
-// fires again before the previous batch finishes.
-func (d *Database) ClaimScheduledPosts() ([]*models.Post, error) {
+// ClaimRetryPosts atomically transitions up to limit failed posts whose retry
+// is due back to "publishing", bumping retry_count, and returns them. Posts of
+// excludeUserIDs are left for a later tick.
+func (d *Database) ClaimRetryPosts(excludeUserIDs []string, limit int) ([]*models.Post, error) {
 	query := `UPDATE posts
-			  SET status = $1, updated_at = $2
-			  WHERE status = $3 AND scheduled_for <= $4
-			  RETURNING id, user_id, content, post_type, privacy_level, is_sponsored,
-			            media_ids, platforms, status, scheduled_for, published_at,
-			            created_at, updated_at`
-
+			  SET status = $1, retry_count = retry_count + 1, next_retry_at = NULL, updated_at = $2
+			  WHERE id IN (
+				  SELECT id FROM posts
+				  WHERE status = $3 AND next_retry_at <= $4 AND NOT (user_id = ANY($5))
+				  ORDER BY next_retry_at
+				  LIMIT $6
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING ` + postColumns
+
+	if excludeUserIDs == nil {
+		excludeUserIDs = []string{}
+	}
 	now := time.Now()
-	rows, err := d.DB.Query(query, models.StatusPublishing, now, models.StatusScheduled, now)
+	rows, err := d.DB.Query(query, models.StatusPublishing, now, models.StatusFailed, now, pq.Array(excludeUserIDs), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -168,31 +281,146 @@ func (d *Database) ClaimScheduledPosts() ([]*models.Post, error) {
 
 	posts := []*models.Post{}
 	for rows.Next() {
-		post := &models.Post{}
-		var platforms []string
-		var mediaIDs []string
+		post, err := d.scanPost(rows)
+		if err != nil {
+			continue
+		}
+		posts = append(posts, post)
+	}
 
-		err := rows.Scan(&post.ID, &post.UserID, &post.Content, &post.PostType,
-			&post.PrivacyLevel, &post.IsSponsored, pq.Array(&mediaIDs),
-			pq.Array(&platforms), &post.Status, &post.ScheduledFor, &post.PublishedAt,
-			&post.CreatedAt, &post.UpdatedAt)
+	return posts, nil
+}
 
+// ClaimStalePublishingPosts atomically takes over up to limit posts that have
+// been "publishing" since before staleBefore, touching updated_at so no other
+// instance claims them too, and returns them. These are publishes whose
+// process stopped before finishing them.
+func (d *Database) ClaimStalePublishingPosts(staleBefore time.Time, limit int) ([]*models.Post, error) {
+	query := `UPDATE posts
+			  SET updated_at = $1
+			  WHERE id IN (
+				  SELECT id FROM posts
+				  WHERE status = $2 AND updated_at < $3
+				  ORDER BY updated_at
+				  LIMIT $4
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING ` + postColumns
+
+	rows, err := d.DB.Query(query, time.Now(), models.StatusPublishing, staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*models.Post{}
+	for rows.Next() {
+		post, err := d.scanPost(rows)
 		if err != nil {
 			continue
 		}
+		posts = append(posts, post)
+	}
 
-		post.Platforms = make([]models.Platform, len(platforms))
-		for i, p := range platforms {
-			post.Platforms[i] = models.Platform(p)
+	return posts, nil
+}
+
+// ClaimFailedPost atomically transitions a failed post to "publishing" and
+// clears its pending automatic retry, so a manual retry never races the
+// scheduler. It returns nil when the post is not in the failed state.
+func (d *Database) ClaimFailedPost(id string) (*models.Post, error) {
+	query := `UPDATE posts
+			  SET status = $1, next_retry_at = NULL, updated_at = $2
+			  WHERE id = $3 AND status = $4
+			  RETURNING ` + postColumns
+
+	post, err := d.scanPost(d.DB.QueryRow(query, models.StatusPublishing, time.Now(), id, models.StatusFailed))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return post, err
+}
+
+// ClaimScheduledPost atomically transitions a scheduled post to "publishing"
+// and clears its schedule, so publishing it early never races the scheduler.
+// It returns nil when the post is not in the scheduled state.
+func (d *Database) ClaimScheduledPost(id string) (*models.Post, error) {
+	query := `UPDATE posts
+			  SET status = $1, scheduled_for = NULL, updated_at = $2
+			  WHERE id = $3 AND status = $4
+			  RETURNING ` + postColumns
+
+	post, err := d.scanPost(d.DB.QueryRow(query, models.StatusPublishing, time.Now(), id, models.StatusScheduled))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return post, err
+}
+
+// GetPublishedPlatforms returns the platforms a post has already been
+// published to successfully, so a retry can skip them.
+func (d *Database) GetPublishedPlatforms(postID string) ([]models.Platform, error) {
+	rows, err := d.DB.Query(`SELECT platform FROM post_platform_status WHERE post_id = $1 AND status = $2`,
+		postID, models.StatusPublished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	platforms := []models.Platform{}
+	for rows.Next() {
+		var platform string
+		if err := rows.Scan(&platform); err != nil {
+			return nil, err
 		}
+		platforms = append(platforms, models.Platform(platform))
+	}
+	return platforms, rows.Err()
+}
 
-		if mediaIDs != nil {
-			post.MediaIDs = mediaIDs
-			post.Media, _ = d.GetMediaByIDs(mediaIDs)
+// GetPublishResults returns every stored publish attempt of a post, oldest
+// first, so retries show up as a history per platform.
+func (d *Database) GetPublishResults(postID string) ([]models.PublishAttempt, error) {
+	rows, err := d.DB.Query(`SELECT id, platform, success, COALESCE(message, ''), COALESCE(external_post_id, ''),
+			  needs_reauth, error_code, error_category, created_at
+			  FROM publish_results WHERE post_id = $1
+			  ORDER BY created_at, id`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []models.PublishAttempt{}
+	for rows.Next() {
+		var a models.PublishAttempt
+		var platform string
+		if err := rows.Scan(&a.ID, &platform, &a.Success, &a.Message, &a.PostID,
+			&a.NeedsReauth, &a.ErrorCode, &a.ErrorCategory, &a.CreatedAt); err != nil {
+			return nil, err
 		}
+		a.Platform = models.Platform(platform)
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
 
-		posts = append(posts, post)
+// GetExternalPostIDs returns, per platform, the external post ID a post got
+// when it was published there.
+func (d *Database) GetExternalPostIDs(postID string) (map[models.Platform]string, error) {
+	rows, err := d.DB.Query(`SELECT platform, external_id FROM post_platform_status
+			  WHERE post_id = $1 AND status = $2 AND external_id <> ''`, postID, models.StatusPublished)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return posts, nil
-}
\ No newline at end of file
+	ids := make(map[models.Platform]string)
+	for rows.Next() {
+		var platform, externalID string
+		if err := rows.Scan(&platform, &externalID); err != nil {
+			return nil, err
+		}
+		ids[models.Platform(platform)] = externalID
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// EnqueuePublishJobs makes the jobs publishing a post to platforms pending
+// from at, creating the ones that don't exist yet. Jobs that are done are
+// left alone, so a platform is never queued again once published, and so
+// are running ones (see RequeueStalePublishJobs).
+func (d *Database) EnqueuePublishJobs(postID string, platforms []models.Platform, at time.Time) error {
+	names := make([]string, len(platforms))
+	for i, p := range platforms {
+		names[i] = string(p)
+	}
+
+	query := `INSERT INTO publish_jobs (post_id, platform, status, next_attempt_at)
+			  SELECT $1, platform, $2, $3 FROM unnest($4::text[]) AS platform
+			  ON CONFLICT (post_id, platform) DO UPDATE
+			  SET status = EXCLUDED.status, next_attempt_at = EXCLUDED.next_attempt_at, updated_at = CURRENT_TIMESTAMP
+			  WHERE publish_jobs.status NOT IN ($5, $6)`
+	_, err := d.DB.Exec(query, postID, models.JobPending, at, pq.Array(names), models.JobDone, models.JobRunning)
+	return err
+}
+
+// publishJobColumns is the column list scanned by scanPublishJobs.
+const publishJobColumns = `id, post_id, platform, status, attempts, next_attempt_at, last_error, created_at, updated_at`
+
+func scanPublishJobs(rows *sql.Rows) ([]models.PublishJob, error) {
+	defer rows.Close()
+
+	jobs := []models.PublishJob{}
+	for rows.Next() {
+		var j models.PublishJob
+		if err := rows.Scan(&j.ID, &j.PostID, &j.Platform, &j.Status, &j.Attempts, &j.NextAttemptAt,
+			&j.LastError, &j.CreatedAt, &j.UpdatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// ClaimPublishJobs atomically marks the due pending jobs of up to limit
+// posts as running and returns them; the posts that have waited longest go
+// first. Only posts in the "publishing" status are picked, so a failed post's
+// jobs wait until the scheduler claims its retry. Both the posts and their
+// jobs are selected with SKIP LOCKED, so any number of workers and instances
+// can claim at once: each gets different posts and none gets the same job
+// twice. All jobs of a post are normally claimed together.
+func (d *Database) ClaimPublishJobs(limit int) ([]models.PublishJob, error) {
+	query := `WITH due AS (
+				  SELECT p.id FROM posts p
+				  WHERE p.status = $4 AND EXISTS (
+					  SELECT 1 FROM publish_jobs j
+					  WHERE j.post_id = p.id AND j.status = $3 AND j.next_attempt_at <= $2
+				  )
+				  ORDER BY (
+					  SELECT MIN(j.next_attempt_at) FROM publish_jobs j
+					  WHERE j.post_id = p.id AND j.status = $3
+				  )
+				  LIMIT $5
+				  FOR NO KEY UPDATE OF p SKIP LOCKED
+			  )
+			  UPDATE publish_jobs
+			  SET status = $1, updated_at = $2
+			  WHERE id IN (
+				  SELECT id FROM publish_jobs
+				  WHERE status = $3 AND next_attempt_at <= $2 AND post_id IN (SELECT id FROM due)
+				  FOR UPDATE SKIP LOCKED
+			  )
+			  RETURNING ` + publishJobColumns
+
+	rows, err := d.DB.Query(query, models.JobRunning, time.Now(), models.JobPending, models.StatusPublishing, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanPublishJobs(rows)
+}
+
+// RequeueStalePublishJobs makes jobs that have been running since before
+// staleBefore pending again, as their worker stopped without finishing them,
+// and returns how many there were. A job may thus run twice (at least once
+// delivery); platforms that already have a successful result are skipped.
+func (d *Database) RequeueStalePublishJobs(staleBefore time.Time) (int64, error) {
+	result, err := d.DB.Exec(`UPDATE publish_jobs SET status = $1, next_attempt_at = $2, updated_at = $2
+			  WHERE status = $3 AND updated_at < $4`, models.JobPending, time.Now(), models.JobRunning, staleBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// StartPublishJob marks the job publishing a post to platform as running and
+// counts the attempt. A publish that was never queued (an interactive
+// publish) gets its job created here.
+func (d *Database) StartPublishJob(postID string, platform models.Platform) error {
+	query := `INSERT INTO publish_jobs (post_id, platform, status, attempts, next_attempt_at)
+			  VALUES ($1, $2, $3, 1, CURRENT_TIMESTAMP)
+			  ON CONFLICT (post_id, platform) DO UPDATE
+			  SET status = EXCLUDED.status, attempts = publish_jobs.attempts + 1, updated_at = CURRENT_TIMESTAMP`
+	_, err := d.DB.Exec(query, postID, platform, models.JobRunning)
+	return err
+}
+
+// FinishPublishJob records the outcome of a job's attempt: done on success,
+// failed with lastError otherwise.
+func (d *Database) FinishPublishJob(postID string, platform models.Platform, success bool, lastError string) error {
+	status := models.JobFailed
+	if success {
+		status = models.JobDone
+		lastError = ""
+	}
+	_, err := d.DB.Exec(`UPDATE publish_jobs SET status = $1, last_error = $2, updated_at = CURRENT_TIMESTAMP
+			  WHERE post_id = $3 AND platform = $4`, status, lastError, postID, platform)
+	return err
+}
+
+// RetryPublishJobs makes a post's failed jobs pending again from at.
+func (d *Database) RetryPublishJobs(postID string, at time.Time) error {
+	_, err := d.DB.Exec(`UPDATE publish_jobs SET status = $1, next_attempt_at = $2, updated_at = CURRENT_TIMESTAMP
+			  WHERE post_id = $3 AND status = $4`, models.JobPending, at, postID, models.JobFailed)
+	return err
+}
+
+// GetPublishJobs returns the jobs of a post, by platform.
+func (d *Database) GetPublishJobs(postID string) ([]models.PublishJob, error) {
+	rows, err := d.DB.Query(`SELECT `+publishJobColumns+` FROM publish_jobs WHERE post_id = $1 ORDER BY platform`, postID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPublishJobs(rows)
+}
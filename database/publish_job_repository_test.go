@@ -0,0 +1,88 @@
+package database
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"SocialMediaAPI/models"
+
+	"github.com/google/uuid"
+)
+
+func TestClaimPublishJobsConcurrent(t *testing.T) {
+	db := openTestDB(t)
+
+	user := &models.User{ID: uuid.New().String(), Email: uuid.New().String() + "@example.com", Password: "x", Name: "claim test", CreatedAt: time.Now()}
+	if err := db.CreateUser(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	t.Cleanup(func() { db.DB.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+	const posts = 20
+	platforms := []models.Platform{models.Twitter, models.Facebook, models.LinkedIn}
+	ours := map[string]bool{}
+	due := time.Now().Add(-time.Minute)
+	for i := 0; i < posts; i++ {
+		post := &models.Post{
+			ID:        uuid.New().String(),
+			UserID:    user.ID,
+			Content:   "claim test",
+			PostType:  models.PostTypeNormal,
+			Platforms: platforms,
+			Status:    models.StatusPublishing,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := db.CreatePost(post); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+		if err := db.EnqueuePublishJobs(post.ID, platforms, due); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+		ours[post.ID] = true
+	}
+
+	const workers = 8
+	var (
+		mu      sync.Mutex
+		claimed = map[int]int{}
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for {
+				jobs, err := db.ClaimPublishJobs(3)
+				if err != nil {
+					t.Errorf("claim: %v", err)
+					return
+				}
+				if len(jobs) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, j := range jobs {
+					if ours[j.PostID] {
+						claimed[j.ID]++
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	for id, n := range claimed {
+		if n > 1 {
+			t.Errorf("job %d claimed %d times", id, n)
+		}
+	}
+	if want := posts * len(platforms); len(claimed) != want {
+		t.Errorf("claimed %d jobs, want %d", len(claimed), want)
+	}
+}
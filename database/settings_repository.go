@@ -0,0 +1,60 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"database/sql"
+	"time"
+)
+
+// GetUserSettings returns the user's settings, or the defaults (no publish
+// window, UTC) when none were saved yet.
+func (d *Database) GetUserSettings(userID string) (*models.UserSettings, error) {
+	settings := &models.UserSettings{UserID: userID, Timezone: "UTC"}
+	query := `SELECT publish_window_start, publish_window_end, timezone, notify_publish_failures, updated_at
+			  FROM user_settings WHERE user_id = $1`
+
+	err := d.DB.QueryRow(query, userID).Scan(&settings.PublishWindowStart, &settings.PublishWindowEnd,
+		&settings.Timezone, &settings.NotifyPublishFailures, &settings.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (d *Database) SaveUserSettings(settings *models.UserSettings) error {
+	settings.UpdatedAt = time.Now()
+	query := `INSERT INTO user_settings (user_id, publish_window_start, publish_window_end, timezone, notify_publish_failures, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  ON CONFLICT (user_id)
+			  DO UPDATE SET publish_window_start = $2, publish_window_end = $3, timezone = $4, notify_publish_failures = $5, updated_at = $6`
+
+	_, err := d.DB.Exec(query, settings.UserID, settings.PublishWindowStart, settings.PublishWindowEnd,
+		settings.Timezone, settings.NotifyPublishFailures, settings.UpdatedAt)
+	return err
+}
+
+// GetPublishWindowSettings returns the settings of every user who configured
+// a publish window.
+func (d *Database) GetPublishWindowSettings() ([]*models.UserSettings, error) {
+	query := `SELECT user_id, publish_window_start, publish_window_end, timezone, notify_publish_failures, updated_at
+			  FROM user_settings WHERE publish_window_start <> '' AND publish_window_end <> ''`
+
+	rows, err := d.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := []*models.UserSettings{}
+	for rows.Next() {
+		s := &models.UserSettings{}
+		if err := rows.Scan(&s.UserID, &s.PublishWindowStart, &s.PublishWindowEnd, &s.Timezone, &s.NotifyPublishFailures, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		settings = append(settings, s)
+	}
+	return settings, rows.Err()
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+)
+
+// templateColumns is the column list shared by every query that loads a
+// template. Keep it in sync with scanTemplate.
+const templateColumns = `id, user_id, name, content, created_at, updated_at`
+
+func scanTemplate(row rowScanner) (*models.Template, error) {
+	t := &models.Template{}
+	if err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Content, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	t.Variables = utils.TemplateVariables(t.Content)
+	return t, nil
+}
+
+func (d *Database) CreateTemplate(t *models.Template) error {
+	query := `INSERT INTO templates (id, user_id, name, content, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := d.DB.Exec(query, t.ID, t.UserID, t.Name, t.Content, t.CreatedAt, t.UpdatedAt)
+	return err
+}
+
+func (d *Database) GetTemplate(id string) (*models.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM templates WHERE id = $1`
+	return scanTemplate(d.DB.QueryRow(query, id))
+}
+
+func (d *Database) GetUserTemplates(userID string) ([]*models.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM templates WHERE user_id = $1 ORDER BY name`
+
+	rows, err := d.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*models.Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+func (d *Database) UpdateTemplate(t *models.Template) error {
+	query := `UPDATE templates SET name = $1, content = $2, updated_at = $3 WHERE id = $4`
+	_, err := d.DB.Exec(query, t.Name, t.Content, t.UpdatedAt, t.ID)
+	return err
+}
+
+func (d *Database) DeleteTemplate(id string) error {
+	_, err := d.DB.Exec(`DELETE FROM templates WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"SocialMediaAPI/models"
+	"time"
+)
+
+// uploadColumns is the column list shared by every query that loads a
+// resumable upload. Keep it in sync with scanUpload.
+const uploadColumns = `id, user_id, filename, upload_length, upload_offset, alt_text, twitter_media_category,
+			  media_id, expires_at, created_at, updated_at`
+
+func scanUpload(row rowScanner) (*models.MediaUpload, error) {
+	upload := &models.MediaUpload{}
+	err := row.Scan(&upload.ID, &upload.UserID, &upload.Filename, &upload.Length, &upload.Offset,
+		&upload.AltText, &upload.TwitterMediaCategory, &upload.MediaID, &upload.ExpiresAt,
+		&upload.CreatedAt, &upload.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (d *Database) CreateUpload(upload *models.MediaUpload) error {
+	query := `INSERT INTO media_uploads (id, user_id, filename, upload_length, upload_offset, alt_text,
+			  twitter_media_category, expires_at, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := d.DB.Exec(query, upload.ID, upload.UserID, upload.Filename, upload.Length, upload.Offset,
+		upload.AltText, upload.TwitterMediaCategory, upload.ExpiresAt, upload.CreatedAt, upload.UpdatedAt)
+	return err
+}
+
+func (d *Database) GetUpload(id string) (*models.MediaUpload, error) {
+	query := `SELECT ` + uploadColumns + ` FROM media_uploads WHERE id = $1`
+	return scanUpload(d.DB.QueryRow(query, id))
+}
+
+// UpdateUploadOffset records how many bytes of the upload have been received.
+func (d *Database) UpdateUploadOffset(id string, offset int64) error {
+	_, err := d.DB.Exec(`UPDATE media_uploads SET upload_offset = $1, updated_at = $2 WHERE id = $3`,
+		offset, time.Now(), id)
+	return err
+}
+
+// CompleteUpload links a finished upload to the media item created from it.
+func (d *Database) CompleteUpload(id, mediaID string) error {
+	_, err := d.DB.Exec(`UPDATE media_uploads SET media_id = $1, updated_at = $2 WHERE id = $3`,
+		mediaID, time.Now(), id)
+	return err
+}
+
+func (d *Database) DeleteUpload(id string) error {
+	_, err := d.DB.Exec(`DELETE FROM media_uploads WHERE id = $1`, id)
+	return err
+}
+
+// DeleteExpiredUploads removes uploads that expired before the given time and
+// returns their IDs so the caller can remove the partial files.
+func (d *Database) DeleteExpiredUploads(before time.Time) ([]string, error) {
+	rows, err := d.DB.Query(`DELETE FROM media_uploads WHERE expires_at < $1 RETURNING id`, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
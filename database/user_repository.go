@@ -1,30 +1,98 @@
 package database
 
-import "SocialMediaAPI/models"
+import (
+	"SocialMediaAPI/models"
+	"time"
+)
 
-func (d *Database) CreateUser(user *models.User) error {
-	query := `INSERT INTO users (id, email, password, name, created_at) 
-			  VALUES ($1, $2, $3, $4, $5)`
-	_, err := d.DB.Exec(query, user.ID, user.Email, user.Password, user.Name, user.CreatedAt)
-	return err
-}
+// userColumns is the column list shared by every query that loads a user.
+// Keep it in sync with scanUser.
+const userColumns = `id, email, password, name, role, tokens_revoked_at, created_at`
 
-func (d *Database) GetUserByEmail(email string) (*models.User, error) {
+func scanUser(row rowScanner) (*models.User, error) {
 	user := &models.User{}
-	query := `SELECT id, email, password, name, created_at FROM users WHERE email = $1`
-	err := d.DB.QueryRow(query, email).Scan(&user.ID, &user.Email, &user.Password, &user.Name, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Email, &user.Password, &user.Name, &user.Role, &user.TokensRevokedAt, &user.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
+func (d *Database) CreateUser(user *models.User) error {
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	query := `INSERT INTO users (id, email, password, name, role, created_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := d.DB.Exec(query, user.ID, user.Email, user.Password, user.Name, user.Role, user.CreatedAt)
+	return err
+}
+
+func (d *Database) GetUserByEmail(email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return scanUser(d.DB.QueryRow(query, email))
+}
+
 func (d *Database) GetUserByID(id string) (*models.User, error) {
-	user := &models.User{}
-	query := `SELECT id, email, password, name, created_at FROM users WHERE id = $1`
-	err := d.DB.QueryRow(query, id).Scan(&user.ID, &user.Email, &user.Password, &user.Name, &user.CreatedAt)
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return scanUser(d.DB.QueryRow(query, id))
+}
+
+// firstAdminLock is the advisory lock key that serializes PromoteFirstUser
+// across concurrent registrations.
+const firstAdminLock = 0x61646d6e // "admn"
+
+// PromoteFirstUser makes the user admin when no admin exists yet and the user
+// is the earliest registered account. Concurrent calls are serialized with an
+// advisory lock, so at most one user is promoted. It returns whether the user
+// was promoted.
+func (d *Database) PromoteFirstUser(id string) (bool, error) {
+	tx, err := d.DB.Begin()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	return user, nil
-}
\ No newline at end of file
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, firstAdminLock); err != nil {
+		return false, err
+	}
+	result, err := tx.Exec(`UPDATE users SET role = $1
+			  WHERE id = $2
+			    AND NOT EXISTS (SELECT 1 FROM users WHERE role = $1)
+			    AND NOT EXISTS (
+			        SELECT 1 FROM users o, users u
+			        WHERE u.id = $2 AND (o.created_at, o.id) < (u.created_at, u.id))`,
+		models.RoleAdmin, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, tx.Commit()
+}
+
+// SetUserRoleByEmail sets the role of the user with the given email. It
+// returns false when no such user exists.
+func (d *Database) SetUserRoleByEmail(email, role string) (bool, error) {
+	result, err := d.DB.Exec(`UPDATE users SET role = $1 WHERE LOWER(email) = LOWER($2)`, role, email)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// UpdateUserPassword replaces the user's bcrypt password hash.
+func (d *Database) UpdateUserPassword(id, passwordHash string) error {
+	_, err := d.DB.Exec(`UPDATE users SET password = $1 WHERE id = $2`, passwordHash, id)
+	return err
+}
+
+// RevokeUserTokens invalidates every JWT issued to the user up to now.
+func (d *Database) RevokeUserTokens(id string) error {
+	query := `UPDATE users SET tokens_revoked_at = $1 WHERE id = $2`
+	_, err := d.DB.Exec(query, time.Now(), id)
+	return err
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminListUserCredentials lists the platforms a user has connected.
+func (h *Handler) AdminListUserCredentials(w http.ResponseWriter, r *http.Request) {
+	targetUserID := mux.Vars(r)["id"]
+	if !h.adminUserExists(w, targetUserID) {
+		return
+	}
+
+	creds, err := h.db.ListUserCredentials(targetUserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching credentials")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"user_id":     targetUserID,
+		"credentials": creds,
+	})
+}
+
+// AdminRevokeUserCredentials deletes every platform credential of a user and
+// revokes the tokens with the platforms, as DisconnectAllPlatforms does.
+func (h *Handler) AdminRevokeUserCredentials(w http.ResponseWriter, r *http.Request) {
+	targetUserID := mux.Vars(r)["id"]
+	if !h.adminUserExists(w, targetUserID) {
+		return
+	}
+
+	revoke := h.allCredentialsToRevoke(targetUserID)
+
+	platforms, err := h.db.DeleteAllCredentials(targetUserID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error revoking credentials")
+		return
+	}
+	h.revokeCredentials(revoke)
+
+	removed := len(platforms)
+	adminID, _ := r.Context().Value("userID").(string)
+	utils.Warnf("admin revoked credentials admin_id=%s user_id=%s removed=%d", adminID, targetUserID, removed)
+	h.db.RecordAudit(models.AuditEntry{
+		UserID:  targetUserID,
+		ActorID: adminID,
+		Action:  models.AuditCredentialsRevoked,
+		Success: true,
+		Details: fmt.Sprintf("removed=%d", removed),
+		IP:      utils.TrustedClientIP(r),
+	})
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Credentials revoked successfully",
+		"removed": removed,
+	})
+}
+
+// AdminRevokeUserSessions invalidates every JWT issued to a user so far.
+// The user has to log in again to get a new token.
+func (h *Handler) AdminRevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	targetUserID := mux.Vars(r)["id"]
+	if !h.adminUserExists(w, targetUserID) {
+		return
+	}
+
+	if err := h.db.RevokeUserTokens(targetUserID); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error revoking sessions")
+		return
+	}
+
+	adminID, _ := r.Context().Value("userID").(string)
+	utils.Warnf("admin revoked sessions admin_id=%s user_id=%s", adminID, targetUserID)
+	h.db.RecordAudit(models.AuditEntry{
+		UserID:  targetUserID,
+		ActorID: adminID,
+		Action:  models.AuditSessionsRevoked,
+		Success: true,
+		IP:      utils.TrustedClientIP(r),
+	})
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Sessions revoked successfully",
+	})
+}
+
+// adminUserExists writes a 404 (or 500) response and returns false when the
+// target user cannot be loaded.
+func (h *Handler) adminUserExists(w http.ResponseWriter, userID string) bool {
+	if _, err := h.db.GetUserByID(userID); err != nil {
+		if err == sql.ErrNoRows {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found")
+		} else {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching user")
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+// GetAuditLog returns the authenticated user's audit trail. Admins may pass
+// ?user_id= to view another user's log, or ?all=true to view every user's.
+func (h *Handler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	role, _ := r.Context().Value("role").(string)
+
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if n > maxAuditLimit {
+			n = maxAuditLimit
+		}
+		limit = n
+	}
+
+	targetUserID := userID
+	otherUser := r.URL.Query().Get("user_id")
+	all := r.URL.Query().Get("all") == "true"
+	if (otherUser != "" && otherUser != userID) || all {
+		if role != models.RoleAdmin {
+			utils.RespondWithError(w, http.StatusForbidden, "Only admins can view other users' audit logs")
+			return
+		}
+		targetUserID = otherUser
+		if all {
+			targetUserID = ""
+		}
+	}
+
+	entries, err := h.db.GetAuditLog(targetUserID, limit)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching audit log")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, entries)
+}
@@ -2,15 +2,17 @@ package handlers
 
 import (
 	"SocialMediaAPI/models"
+	"SocialMediaAPI/services"
 	"SocialMediaAPI/utils"
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	var req models.RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "request.invalid_payload"))
 		return
 	}
 
@@ -22,7 +24,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 
 	token, err := h.authService.GenerateToken(user)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error generating token")
+		utils.RespondWithError(w, http.StatusInternalServerError, utils.LocalizeRequest(r, "auth.token_generation_failed"))
 		return
 	}
 
@@ -32,11 +34,15 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req models.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "request.invalid_payload"))
 		return
 	}
 
 	user, err := h.authService.Login(req)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		utils.RespondWithError(w, http.StatusUnauthorized, utils.LocalizeRequest(r, "auth.invalid_credentials"))
+		return
+	}
 	if err != nil {
 		utils.RespondWithError(w, http.StatusUnauthorized, err.Error())
 		return
@@ -44,9 +50,58 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	token, err := h.authService.GenerateToken(user)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error generating token")
+		utils.RespondWithError(w, http.StatusInternalServerError, utils.LocalizeRequest(r, "auth.token_generation_failed"))
 		return
 	}
 
 	utils.RespondWithJSON(w, http.StatusOK, models.AuthResponse{Token: token, User: *user})
-}
\ No newline at end of file
+}
+
+// ForgotPassword emails a password reset token to the account with the given
+// email. The response is the same whether or not the email is registered, and
+// the email is sent in the background so the response time does not tell
+// either.
+func (h *Handler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "request.invalid_payload"))
+		return
+	}
+
+	go func() {
+		if err := h.authService.RequestPasswordReset(req.Email); err != nil {
+			utils.Errorf("password reset request failed err=%v", err)
+		}
+	}()
+
+	utils.RespondWithJSON(w, http.StatusAccepted, map[string]string{
+		"message": utils.LocalizeRequest(r, "auth.reset_email_sent"),
+	})
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword.
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "request.invalid_payload"))
+		return
+	}
+
+	err := h.authService.ResetPassword(req.Token, req.Password)
+	switch {
+	case errors.Is(err, services.ErrPasswordTooShort):
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "auth.password_too_short", services.MinPasswordLength))
+		return
+	case errors.Is(err, services.ErrInvalidResetToken):
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "auth.invalid_reset_token"))
+		return
+	case err != nil:
+		utils.Errorf("password reset failed err=%v", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, utils.LocalizeRequest(r, "auth.reset_failed"))
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
+		"message": utils.LocalizeRequest(r, "auth.password_reset"),
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/publishers"
+	"SocialMediaAPI/utils"
+	"encoding/json"
+	"net/http"
+)
+
+// AnalyzeContent reports, per platform, how the given content counts against
+// that platform's limit without creating a post.
+func (h *Handler) AnalyzeContent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content   string            `json:"content"`
+		Platforms []models.Platform `json:"platforms"`
+		PostType  models.PostType   `json:"post_type"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "request.invalid_payload"))
+		return
+	}
+
+	if len(req.Platforms) == 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, utils.LocalizeRequest(r, "post.platform_required"))
+		return
+	}
+	if req.PostType == "" {
+		req.PostType = models.PostTypeNormal
+	}
+
+	results, err := publishers.AnalyzeContent(req.Content, req.Platforms, req.PostType)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"content_length": utils.RuneLen(req.Content),
+		"platforms":      results,
+	})
+}
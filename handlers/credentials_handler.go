@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // SaveCredentials saves platform credentials for the authenticated user
@@ -19,29 +21,79 @@ func (h *Handler) SaveCredentials(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var cred models.PlatformCredentials
-	if err := json.NewDecoder(r.Body).Decode(&cred); err != nil {
+	// Tokens are never serialized from PlatformCredentials, so they are
+	// decoded here
+	var req struct {
+		Platform       models.Platform `json:"platform"`
+		AccessToken    string          `json:"access_token"`
+		RefreshToken   string          `json:"refresh_token"`
+		Secret         string          `json:"secret"`
+		ExpiresAt      *time.Time      `json:"expires_at"`
+		TokenType      string          `json:"token_type"`
+		PlatformUserID string          `json:"platform_user_id"`
+		PlatformPageID string          `json:"platform_page_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
 		return
 	}
 
-	if cred.Platform == "" || cred.AccessToken == "" {
+	if req.Platform == "" || req.AccessToken == "" {
 		utils.RespondWithError(w, http.StatusBadRequest, "Platform and access_token are required")
 		return
 	}
 
-	cred.ID = uuid.New().String()
-	cred.UserID = userID
-	cred.CreatedAt = time.Now()
+	// A credential for a platform without a publisher could never be used
+	req.Platform = models.Platform(strings.ToLower(strings.TrimSpace(string(req.Platform))))
+	supported := h.publisher.SupportedPlatforms()
+	if !hasPlatform(supported, req.Platform) {
+		names := make([]string, len(supported))
+		for i, p := range supported {
+			names[i] = string(p)
+		}
+		utils.RespondWithError(w, http.StatusBadRequest,
+			fmt.Sprintf("Unknown platform %q. Supported platforms: %s", req.Platform, strings.Join(names, ", ")))
+		return
+	}
+
+	cred := models.PlatformCredentials{
+		ID:             uuid.New().String(),
+		UserID:         userID,
+		Platform:       req.Platform,
+		AccessToken:    req.AccessToken,
+		RefreshToken:   req.RefreshToken,
+		Secret:         req.Secret,
+		ExpiresAt:      req.ExpiresAt,
+		TokenType:      req.TokenType,
+		PlatformUserID: req.PlatformUserID,
+		PlatformPageID: req.PlatformPageID,
+		CreatedAt:      time.Now(),
+	}
+
+	// Bind the token to its account as the OAuth callbacks do. A failed
+	// lookup still saves the credentials, but publishing may fail without
+	// the IDs, so the response says so.
+	response := map[string]string{"message": "Credentials saved successfully"}
+	if err := h.identities.ResolveIdentity(r.Context(), &cred); err != nil {
+		utils.Warnf("manual credentials identity lookup failed user_id=%s platform=%s err=%v", userID, cred.Platform, err)
+		response["warning"] = fmt.Sprintf("Could not look up the %s account for this token (%v). Set platform_user_id and platform_page_id if publishing fails", cred.Platform, err)
+	}
 
 	if err := h.db.SaveCredentials(&cred); err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Error saving credentials")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
-		"message": "Credentials saved successfully",
+	h.db.RecordAudit(models.AuditEntry{
+		UserID:   userID,
+		Action:   models.AuditPlatformConnected,
+		Platform: string(cred.Platform),
+		Success:  true,
+		Details:  "manual",
+		IP:       utils.TrustedClientIP(r),
 	})
+
+	utils.RespondWithJSON(w, http.StatusOK, response)
 }
 
 // GetConnectedPlatforms returns which platforms the user has connected
@@ -52,7 +104,8 @@ func (h *Handler) GetConnectedPlatforms(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	query := `SELECT platform, created_at, expires_at FROM credentials WHERE user_id = $1`
+	query := `SELECT id, platform, created_at, expires_at, platform_username, platform_display_name, granted_scopes
+			  FROM credentials WHERE user_id = $1 ORDER BY created_at, id`
 
 	rows, err := h.db.DB.Query(query, userID)
 	if err != nil {
@@ -61,58 +114,95 @@ func (h *Handler) GetConnectedPlatforms(w http.ResponseWriter, r *http.Request)
 	}
 	defer rows.Close()
 
+	type ConnectedAccount struct {
+		ID          string     `json:"id"` // Credential ID, used to choose the account in a post's accounts
+		Primary     bool       `json:"primary"`
+		CreatedAt   time.Time  `json:"created_at"`
+		ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+		IsExpired   bool       `json:"is_expired"`
+		Username    string     `json:"platform_username,omitempty"`
+		DisplayName string     `json:"platform_display_name,omitempty"`
+		Scopes      []string   `json:"granted_scopes,omitempty"` // Scopes granted on connect, when the platform reports them
+	}
+
 	type ConnectedPlatform struct {
 		Platform  string     `json:"platform"`
 		Connected bool       `json:"connected"`
 		CreatedAt time.Time  `json:"created_at,omitempty"`
 		ExpiresAt *time.Time `json:"expires_at,omitempty"`
 		IsExpired bool       `json:"is_expired"`
+		// Connected account, e.g. "Connected as @handle / Page Name"
+		Username    string   `json:"platform_username,omitempty"`
+		DisplayName string   `json:"platform_display_name,omitempty"`
+		Scopes      []string `json:"granted_scopes,omitempty"`
+		// Every connected account of the platform, primary first
+		Accounts []ConnectedAccount `json:"accounts,omitempty"`
 	}
 
 	type credentialInfo struct {
-		createdAt time.Time
-		expiresAt *time.Time
+		id          string
+		createdAt   time.Time
+		expiresAt   *time.Time
+		username    string
+		displayName string
+		scopes      []string
 	}
 
-	connectedMap := make(map[string]credentialInfo)
+	// Accounts are read oldest first, so the first of each platform is its primary
+	connectedMap := make(map[string][]credentialInfo)
 	for rows.Next() {
-		var platform string
+		var id, platform string
 		var createdAt time.Time
 		var expiresAt *time.Time
-		if err := rows.Scan(&platform, &createdAt, &expiresAt); err != nil {
+		var username, displayName string
+		var scopes []string
+		if err := rows.Scan(&id, &platform, &createdAt, &expiresAt, &username, &displayName, pq.Array(&scopes)); err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Error reading credentials")
 			return
 		}
-		connectedMap[platform] = credentialInfo{createdAt: createdAt, expiresAt: expiresAt}
+		connectedMap[platform] = append(connectedMap[platform],
+			credentialInfo{id: id, createdAt: createdAt, expiresAt: expiresAt, username: username, displayName: displayName, scopes: scopes})
 	}
 	if err := rows.Err(); err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Error reading credentials")
 		return
 	}
 
-	// All platforms
-	allPlatforms := []models.Platform{
-		models.Twitter,
-		models.Facebook,
-		models.LinkedIn,
-		models.Instagram,
-		models.TikTok,
-	}
+	// Every platform with a publisher, so the list can't drift from what is publishable
+	allPlatforms := h.publisher.SupportedPlatforms()
 
 	platforms := []ConnectedPlatform{}
 	for _, platform := range allPlatforms {
-		if credInfo, connected := connectedMap[string(platform)]; connected {
-			isExpired := false
-			if credInfo.expiresAt != nil {
-				buffer := 5 * time.Minute
-				isExpired = time.Now().Add(buffer).After(*credInfo.expiresAt)
+		if infos, connected := connectedMap[string(platform)]; connected {
+			accounts := make([]ConnectedAccount, len(infos))
+			for i, info := range infos {
+				isExpired := false
+				if info.expiresAt != nil {
+					buffer := 5 * time.Minute
+					isExpired = time.Now().Add(buffer).After(*info.expiresAt)
+				}
+				accounts[i] = ConnectedAccount{
+					ID:          info.id,
+					Primary:     i == 0,
+					CreatedAt:   info.createdAt,
+					ExpiresAt:   info.expiresAt,
+					IsExpired:   isExpired,
+					Username:    info.username,
+					DisplayName: info.displayName,
+					Scopes:      info.scopes,
+				}
 			}
+			primary := accounts[0]
 			platforms = append(platforms, ConnectedPlatform{
-				Platform:  string(platform),
-				Connected: true,
-				CreatedAt: credInfo.createdAt,
-				ExpiresAt: credInfo.expiresAt,
-				IsExpired: isExpired,
+				Platform:    string(platform),
+				Connected:   true,
+				CreatedAt:   primary.CreatedAt,
+				ExpiresAt:   primary.ExpiresAt,
+				IsExpired:   primary.IsExpired,
+				Username:    primary.Username,
+				DisplayName: primary.DisplayName,
+				Scopes:      primary.Scopes,
+				Accounts:    accounts,
 			})
 		} else {
 			platforms = append(platforms, ConnectedPlatform{
@@ -137,7 +227,8 @@ func (h *Handler) DisconnectPlatform(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Platform string `json:"platform"`
+		Platform  string `json:"platform"`
+		AccountID string `json:"account_id"` // Disconnect only this account; empty = every account of the platform
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -145,8 +236,12 @@ func (h *Handler) DisconnectPlatform(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `DELETE FROM credentials WHERE user_id = $1 AND platform = $2`
-	result, err := h.db.DB.Exec(query, userID, req.Platform)
+	// Keep the tokens so they can be revoked with the platform once the
+	// rows are gone: disconnecting severs our access, not just our copy of it
+	revoke := h.credentialsToRevoke(userID, []models.Platform{models.Platform(req.Platform)}, req.AccountID)
+
+	query := `DELETE FROM credentials WHERE user_id = $1 AND platform = $2 AND ($3 = '' OR id = $3)`
+	result, err := h.db.DB.Exec(query, userID, req.Platform, req.AccountID)
 
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "Error disconnecting platform")
@@ -158,8 +253,128 @@ func (h *Handler) DisconnectPlatform(w http.ResponseWriter, r *http.Request) {
 		utils.RespondWithError(w, http.StatusNotFound, "Platform was not connected")
 		return
 	}
+	h.revokeCredentials(revoke)
+
+	h.db.RecordAudit(models.AuditEntry{
+		UserID:   userID,
+		Action:   models.AuditPlatformDisconnected,
+		Platform: req.Platform,
+		Success:  true,
+		IP:       utils.TrustedClientIP(r),
+	})
 
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
 		"message": fmt.Sprintf("%s disconnected successfully", req.Platform),
 	})
 }
+
+// DisconnectAllPlatforms removes all of the authenticated user's platform
+// credentials and returns the platforms that were disconnected.
+func (h *Handler) DisconnectAllPlatforms(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	revoke := h.allCredentialsToRevoke(userID)
+
+	platforms, err := h.db.DeleteAllCredentials(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error disconnecting platforms")
+		return
+	}
+	h.revokeCredentials(revoke)
+
+	ip := utils.TrustedClientIP(r)
+	for _, platform := range platforms {
+		h.db.RecordAudit(models.AuditEntry{
+			UserID:   userID,
+			Action:   models.AuditPlatformDisconnected,
+			Platform: string(platform),
+			Success:  true,
+			Details:  "bulk",
+			IP:       ip,
+		})
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"message":      fmt.Sprintf("%d platform(s) disconnected", len(platforms)),
+		"disconnected": platforms,
+	})
+}
+
+// credentialsToRevoke loads the user's own credentials for platforms, with
+// their tokens, ahead of deleting them: the account with accountID, or every
+// account when it is empty. A failure is only logged, since the credentials
+// are deleted locally either way.
+func (h *Handler) credentialsToRevoke(userID string, platforms []models.Platform, accountID string) []*models.PlatformCredentials {
+	var revoke []*models.PlatformCredentials
+	for _, platform := range platforms {
+		creds, err := h.db.ListPlatformCredentials(userID, platform)
+		if err != nil {
+			utils.Warnf("token revoke skipped: failed to load credentials user_id=%s platform=%s err=%v", userID, platform, err)
+			continue
+		}
+		for _, cred := range creds {
+			if accountID == "" || cred.ID == accountID {
+				revoke = append(revoke, cred)
+			}
+		}
+	}
+	return revoke
+}
+
+// allCredentialsToRevoke is credentialsToRevoke for every platform the user
+// has connected.
+func (h *Handler) allCredentialsToRevoke(userID string) []*models.PlatformCredentials {
+	connected, err := h.db.ListUserCredentials(userID)
+	if err != nil {
+		utils.Warnf("token revoke skipped: failed to load credentials user_id=%s err=%v", userID, err)
+		return nil
+	}
+	var platforms []models.Platform
+	seen := make(map[models.Platform]bool)
+	for _, cred := range connected {
+		if !seen[cred.Platform] {
+			seen[cred.Platform] = true
+			platforms = append(platforms, cred.Platform)
+		}
+	}
+	return h.credentialsToRevoke(userID, platforms, "")
+}
+
+// revokeCredentials revokes the tokens of credentials that were just deleted
+// with their platforms. It runs in the background: platform calls can take
+// far longer than the request timeout, and the disconnect is already done.
+// Revocation withdraws the whole grant of the platform account (Facebook
+// deletes every permission of the user, Google every token of the grant), so
+// it is skipped while another credential of the same account is stored, such
+// as another Page of the same Facebook user.
+func (h *Handler) revokeCredentials(creds []*models.PlatformCredentials) {
+	if len(creds) == 0 {
+		return
+	}
+	go func() {
+		for _, cred := range creds {
+			stillConnected := false
+			if cred.PlatformUserID != "" {
+				var err error
+				stillConnected, err = h.db.PlatformAccountConnected(cred.Platform, cred.PlatformUserID)
+				if err != nil {
+					utils.Warnf("token revoke skipped: failed to check account user_id=%s platform=%s credential_id=%s err=%v", cred.UserID, cred.Platform, cred.ID, err)
+					continue
+				}
+			}
+			if stillConnected {
+				utils.Infof("token revoke skipped, account still connected user_id=%s platform=%s credential_id=%s", cred.UserID, cred.Platform, cred.ID)
+				continue
+			}
+			if err := h.publisher.RevokeCredentials(cred); err != nil {
+				utils.Warnf("token revoke failed after disconnect user_id=%s platform=%s credential_id=%s err=%v", cred.UserID, cred.Platform, cred.ID, err)
+				continue
+			}
+			utils.Infof("token revoked user_id=%s platform=%s credential_id=%s", cred.UserID, cred.Platform, cred.ID)
+		}
+	}()
+}
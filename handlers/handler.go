@@ -2,21 +2,42 @@ package handlers
 
 import (
 	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
 	"SocialMediaAPI/services"
+	"context"
+	"sync/atomic"
 )
 
+// IdentityResolver fills in the platform identity of credentials from their
+// access token; oauth.OAuthHandler implements it.
+type IdentityResolver interface {
+	ResolveIdentity(ctx context.Context, cred *models.PlatformCredentials) error
+}
+
 type Handler struct {
 	db          *database.Database
 	publisher   *services.PublisherService
+	queue       *services.PublishQueue
 	authService *services.AuthService
 	storage     *services.StorageService
+	uploads     *services.UploadService
+
+	youtubeCategories *services.YouTubeCategoryService
+	identities        IdentityResolver
+
+	// ready is reported by the readiness probe; see SetReady.
+	ready atomic.Bool
 }
 
-func NewHandler(db *database.Database, publisher *services.PublisherService, authService *services.AuthService, storage *services.StorageService) *Handler {
+func NewHandler(db *database.Database, publisher *services.PublisherService, queue *services.PublishQueue, authService *services.AuthService, storage *services.StorageService, uploads *services.UploadService, youtubeCategories *services.YouTubeCategoryService, identities IdentityResolver) *Handler {
 	return &Handler{
-		db:          db,
-		publisher:   publisher,
-		authService: authService,
-		storage:     storage,
+		db:                db,
+		publisher:         publisher,
+		queue:             queue,
+		authService:       authService,
+		storage:           storage,
+		uploads:           uploads,
+		youtubeCategories: youtubeCategories,
+		identities:        identities,
 	}
 }
\ No newline at end of file
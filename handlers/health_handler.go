@@ -1,10 +1,124 @@
 package handlers
 
 import (
+	"SocialMediaAPI/config"
 	"SocialMediaAPI/utils"
+	"context"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 )
 
+// readinessTimeout bounds each dependency check of the readiness probe.
+const readinessTimeout = 2 * time.Second
+
+// SetReady marks the server as ready (or not) to receive traffic. main sets it
+// once startup completes and clears it as soon as shutdown begins, so load
+// balancers stop routing new requests before connections are drained.
+func (h *Handler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// HealthCheck is kept for existing monitors; it behaves like Liveness.
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	h.Liveness(w, r)
+}
+
+// Liveness reports that the process is up and serving HTTP. It checks no
+// dependencies, so a database outage does not get the process restarted.
+func (h *Handler) Liveness(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"status": "healthy"})
-}
\ No newline at end of file
+}
+
+// Readiness reports whether the server can serve requests: startup finished
+// and not shutting down, database reachable, upload directory writable and
+// required configuration present. It answers 503 when any check fails.
+func (h *Handler) Readiness(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{}
+	ready := true
+	fail := func(name, reason string) {
+		checks[name] = reason
+		ready = false
+	}
+
+	if h.ready.Load() {
+		checks["server"] = "ok"
+	} else {
+		fail("server", "starting or shutting down")
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	if err := h.db.DB.PingContext(ctx); err != nil {
+		utils.Warnf("readiness database check failed err=%v", err)
+		fail("database", "unreachable")
+	} else {
+		checks["database"] = "ok"
+	}
+
+	cfg := config.Load()
+	if err := checkDirWritable(cfg.UploadDir); err != nil {
+		utils.Warnf("readiness upload dir check failed dir=%s err=%v", cfg.UploadDir, err)
+		fail("uploads", "upload directory is not writable")
+	} else {
+		checks["uploads"] = "ok"
+	}
+
+	if missing := missingRequiredConfig(cfg); len(missing) > 0 {
+		fail("config", "missing "+strings.Join(missing, ", "))
+	} else {
+		checks["config"] = "ok"
+	}
+
+	status := http.StatusOK
+	body := map[string]interface{}{"status": "ready", "checks": checks}
+	if !ready {
+		status = http.StatusServiceUnavailable
+		body["status"] = "not_ready"
+	}
+	utils.RespondWithJSON(w, status, body)
+}
+
+// Metrics exposes operational counters in the Prometheus text format.
+func (h *Handler) Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	h.publisher.Metrics().WritePrometheus(w)
+	utils.OutboundMetrics.WritePrometheus(w)
+}
+
+// checkDirWritable creates and removes a probe file in dir.
+func checkDirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".ready-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// missingRequiredConfig returns the env vars the server cannot work without
+// that are empty.
+func missingRequiredConfig(cfg *config.Config) []string {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"DATABASE_URL", cfg.DatabaseURL},
+		{"JWT_SECRET", string(cfg.JWTSecret)},
+		{"TOKEN_ENCRYPTION_KEY", string(cfg.TokenEncryptionKey)},
+		{"MEDIA_SIGNING_KEY", string(cfg.MediaSigningKey)},
+		{"BASE_URL", cfg.BaseURL},
+		{"UPLOAD_DIR", cfg.UploadDir},
+	}
+
+	var missing []string
+	for _, c := range required {
+		if c.value == "" {
+			missing = append(missing, c.name)
+		}
+	}
+	return missing
+}
@@ -5,11 +5,16 @@ import (
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/services"
 	"SocialMediaAPI/utils"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -20,6 +25,20 @@ var allowedUploadExtensions = map[string]bool{
 	".gif": true, ".webp": true, ".mp4": true,
 }
 
+// maxAltTextLength is the longest accessibility description accepted (in
+// characters), matching Instagram's alt_text limit.
+const maxAltTextLength = 1000
+
+// maxUploadFieldSize caps the text fields read alongside an upload.
+const maxUploadFieldSize = 64 << 10
+
+// maxMediaLimit caps the page size of GET /api/media.
+const maxMediaLimit = 200
+
+// minSignedURLExpiry is the shortest expiry POST /api/media/{id}/sign issues;
+// MEDIA_URL_EXPIRY_HOURS is the longest.
+const minSignedURLExpiry = time.Minute
+
 func (h *Handler) UploadMedia(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
 	if !ok || userID == "" {
@@ -35,68 +54,251 @@ func (h *Handler) UploadMedia(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := r.ParseMultipartForm(cfg.MaxUploadSize); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "File too large or malformed multipart request")
+	// Stream the multipart body part by part instead of parsing the whole
+	// form, so large videos go straight to disk rather than memory/temp files.
+	mr, err := r.MultipartReader()
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Expected a multipart/form-data request")
 		return
 	}
 
-	file, header, err := r.FormFile("file")
-	if err != nil {
+	var media *models.Media
+	var altText, twitterMediaCategory string
+	fail := func(status int, message string) {
+		if media != nil {
+			h.storage.DeleteFile(media)
+		}
+		utils.RespondWithError(w, status, message)
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fail(http.StatusBadRequest, "File too large or malformed multipart request")
+			return
+		}
+
+		switch part.FormName() {
+		case "file":
+			if media != nil {
+				break // only the first file is stored
+			}
+			var status int
+			media, status, err = h.saveUploadPart(part, userID, utils.MediaBaseURL(r))
+			if err != nil {
+				part.Close()
+				fail(status, err.Error())
+				return
+			}
+		case "alt_text", "twitter_media_category":
+			value, err := io.ReadAll(io.LimitReader(part, maxUploadFieldSize))
+			if err != nil {
+				part.Close()
+				fail(http.StatusBadRequest, "File too large or malformed multipart request")
+				return
+			}
+			if part.FormName() == "alt_text" {
+				altText = strings.TrimSpace(string(value))
+			} else {
+				twitterMediaCategory = strings.TrimSpace(string(value))
+			}
+		}
+		part.Close()
+	}
+
+	if media == nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "Error retrieving file: ensure the field name is 'file'")
 		return
 	}
-	defer file.Close()
 
+	if utils.RuneLen(altText) > maxAltTextLength {
+		fail(http.StatusBadRequest, fmt.Sprintf("alt_text must be at most %d characters", maxAltTextLength))
+		return
+	}
+
+	if twitterMediaCategory != "" && !models.TwitterMediaCategories[twitterMediaCategory] {
+		fail(http.StatusBadRequest,
+			"Invalid twitter_media_category. Must be 'tweet_image', 'tweet_gif', 'tweet_video', or 'amplify_video'")
+		return
+	}
+
+	media.AltText = altText
+	media.TwitterMediaCategory = twitterMediaCategory
+
+	if err := h.db.CreateMedia(media); err != nil {
+		fail(http.StatusInternalServerError, "Error saving media")
+		return
+	}
+
+	// Return a signed URL so the client (and platform APIs) can fetch the file.
+	utils.RespondWithJSON(w, http.StatusCreated, models.UploadResponse{Media: media})
+}
+
+// saveUploadPart validates a streamed "file" part and writes it to storage.
+// On failure it returns the HTTP status to answer with.
+func (h *Handler) saveUploadPart(part *multipart.Part, userID, baseURL string) (*models.Media, int, error) {
 	// Quick extension check for fast rejection.
-	ext := strings.ToLower(filepath.Ext(header.Filename))
+	ext := strings.ToLower(filepath.Ext(part.FileName()))
 	if !allowedUploadExtensions[ext] {
-		utils.RespondWithError(w, http.StatusBadRequest,
-			"File type not allowed; accepted extensions: .jpg, .jpeg, .png, .gif, .webp, .mp4")
-		return
+		return nil, http.StatusBadRequest,
+			fmt.Errorf("File type not allowed; accepted extensions: .jpg, .jpeg, .png, .gif, .webp, .mp4")
 	}
 
 	// Magic-number content verification — reject disguised/spoofed files early.
+	file := services.NewFileTypeReader(part)
 	kind, err := services.DetectFileType(file)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Unable to verify file type: "+err.Error())
-		return
+		return nil, http.StatusBadRequest, fmt.Errorf("Unable to verify file type: %w", err)
 	}
 	if !services.IsAllowedMIME(kind.MIME.Value) {
-		utils.RespondWithError(w, http.StatusUnsupportedMediaType,
-			fmt.Sprintf("File content type %s is not allowed; accepted: JPEG, PNG, GIF, WebP images and MP4 video", kind.MIME.Value))
+		return nil, http.StatusUnsupportedMediaType,
+			fmt.Errorf("File content type %s is not allowed; accepted: JPEG, PNG, GIF, WebP images and MP4 video", kind.MIME.Value)
+	}
+
+	media, err := h.storage.SaveStream(file, part.FileName(), userID, baseURL)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return media, 0, nil
+}
+
+func (h *Handler) GetMedia(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	q := r.URL.Query()
+
+	mediaType := models.MediaType(q.Get("type"))
+	if mediaType != "" && mediaType != models.MediaImage && mediaType != models.MediaVideo {
+		utils.RespondWithError(w, http.StatusBadRequest, "type must be 'image' or 'video'")
 		return
 	}
 
-	media, err := h.storage.SaveFile(file, header, userID)
+	from, err := parseMediaDate(q.Get("from"), false)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		utils.RespondWithError(w, http.StatusBadRequest, "from must be an RFC 3339 time or a YYYY-MM-DD date")
+		return
+	}
+	to, err := parseMediaDate(q.Get("to"), true)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "to must be an RFC 3339 time or a YYYY-MM-DD date")
 		return
 	}
 
-	if err := h.db.CreateMedia(media); err != nil {
-		h.storage.DeleteFile(media)
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error saving media")
+	// No limit returns every match, as before filtering was added
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		if n > maxMediaLimit {
+			n = maxMediaLimit
+		}
+		limit = n
+	}
+	offset := 0
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			utils.RespondWithError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	mediaList, total, err := h.db.GetUserMediaFiltered(userID, mediaType, from, to, limit, offset)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching media")
 		return
 	}
 
-	// Return a signed URL so the client (and platform APIs) can fetch the file.
-	utils.RespondWithJSON(w, http.StatusCreated, models.UploadResponse{Media: media})
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	utils.RespondWithJSON(w, http.StatusOK, mediaList)
 }
 
-func (h *Handler) GetMedia(w http.ResponseWriter, r *http.Request) {
+// parseMediaDate parses a from/to filter given as an RFC 3339 time or a
+// date. A date used as the end of a range covers that whole day.
+func parseMediaDate(v string, endOfRange bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// UpdateMedia updates the editable metadata of a media item: its alt text and
+// Twitter media_category override. Omitted fields are left unchanged.
+func (h *Handler) UpdateMedia(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
 	if !ok || userID == "" {
 		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
 		return
 	}
+	mediaID := mux.Vars(r)["id"]
+
+	var req struct {
+		AltText              *string `json:"alt_text"`
+		TwitterMediaCategory *string `json:"twitter_media_category"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
 
-	mediaList, err := h.db.GetUserMedia(userID)
+	media, err := h.db.GetMedia(mediaID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching media")
+		utils.RespondWithError(w, http.StatusNotFound, "Media not found")
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, mediaList)
+	if media.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	if req.AltText != nil {
+		altText := strings.TrimSpace(*req.AltText)
+		if utils.RuneLen(altText) > maxAltTextLength {
+			utils.RespondWithError(w, http.StatusBadRequest,
+				fmt.Sprintf("alt_text must be at most %d characters", maxAltTextLength))
+			return
+		}
+		media.AltText = altText
+	}
+
+	if req.TwitterMediaCategory != nil {
+		category := strings.TrimSpace(*req.TwitterMediaCategory)
+		if category != "" && !models.TwitterMediaCategories[category] {
+			utils.RespondWithError(w, http.StatusBadRequest,
+				"Invalid twitter_media_category. Must be 'tweet_image', 'tweet_gif', 'tweet_video', or 'amplify_video'")
+			return
+		}
+		media.TwitterMediaCategory = category
+	}
+
+	if err := h.db.UpdateMediaMetadata(media); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error updating media")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, media)
 }
 
 func (h *Handler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
@@ -129,4 +331,52 @@ func (h *Handler) DeleteMedia(w http.ResponseWriter, r *http.Request) {
 	}
 
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Media deleted successfully"})
-}
\ No newline at end of file
+}
+
+// SignMedia returns a freshly signed URL for a media item. expires_in
+// (seconds) is clamped between minSignedURLExpiry and MEDIA_URL_EXPIRY_HOURS,
+// which is also the default.
+func (h *Handler) SignMedia(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	var req struct {
+		ExpiresIn int `json:"expires_in"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	media, err := h.db.GetMedia(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Media not found")
+		return
+	}
+
+	if media.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	maxExpiry := config.Load().MediaURLExpiry
+	expiry := maxExpiry
+	if req.ExpiresIn > 0 {
+		expiry = min(max(time.Duration(req.ExpiresIn)*time.Second, minSignedURLExpiry), maxExpiry)
+	}
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+
+	signedURL, err := utils.SignURL(media.URL, expiresAt)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error signing media URL")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"url":        signedURL,
+		"expires_at": expiresAt.UTC(),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,8 +15,6 @@ import (
 	"github.com/google/uuid"
 )
 
-var facebookHTTPClient = &http.Client{Timeout: 10 * time.Second}
-
 // InitiateFacebookOAuth starts the Facebook OAuth flow
 func (h *OAuthHandler) InitiateFacebookOAuth(w http.ResponseWriter, r *http.Request) {
 	// Get authenticated user ID from JWT (safe type assertion)
@@ -66,8 +65,8 @@ func (h *OAuthHandler) HandleFacebookCallback(w http.ResponseWriter, r *http.Req
 	if errorParam != "" {
 		errorDesc := r.URL.Query().Get("error_description")
 		utils.Warnf("user denied or OAuth error error=%s description=%s", errorParam, errorDesc)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
-			errorParam, url.QueryEscape(errorDesc)), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
+			url.QueryEscape(errorParam), url.QueryEscape(errorDesc)))
 		return
 	}
 
@@ -96,24 +95,31 @@ func (h *OAuthHandler) HandleFacebookCallback(w http.ResponseWriter, r *http.Req
 	userID := oauthState.UserID
 
 	// Exchange code for access token
-	accessToken, expiresIn, err := h.exchangeCodeForFacebookToken(code)
+	accessToken, expiresIn, err := h.exchangeCodeForFacebookToken(r.Context(), code)
 	if err != nil {
 		utils.Errorf("token exchange failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
 	utils.Infof("token exchange success user_id=%s expires_in=%d", userID, expiresIn)
 
 	// Fetch Facebook user ID and page info (bind token to identity)
-	facebookUserID, pageID, err := h.getFacebookUserIdentity(accessToken)
+	facebookUserID, pages, err := h.getFacebookUserIdentity(r.Context(), accessToken)
 	if err != nil {
 		utils.Errorf("identity fetch failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=identity_fetch&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=identity_fetch&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
-	utils.Infof("identity fetch success user_id=%s facebook_user_id=%s page_id=%s", userID, facebookUserID, pageID)
+	utils.Infof("identity fetch success user_id=%s facebook_user_id=%s pages=%d", userID, facebookUserID, len(pages))
+
+	// Facebook doesn't report the granted permissions with the token, and the
+	// user may have unchecked some in the dialog
+	grantedScopes, err := h.getFacebookGrantedPermissions(r.Context(), accessToken)
+	if err != nil {
+		utils.Warnf("facebook permissions fetch failed, scopes not recorded user_id=%s err=%v", userID, err)
+	}
 
 	// Calculate expiration time
 	var expiresAt *time.Time
@@ -122,39 +128,48 @@ func (h *OAuthHandler) HandleFacebookCallback(w http.ResponseWriter, r *http.Req
 		expiresAt = &expTime
 	}
 
-	// Save credentials to database with identity binding
-	cred := &models.PlatformCredentials{
-		ID:             uuid.New().String(),
-		UserID:         userID,
-		Platform:       models.Facebook,
-		AccessToken:    accessToken,
-		TokenType:      "Bearer",
-		ExpiresAt:      expiresAt,
-		PlatformUserID: facebookUserID,
-		PlatformPageID: pageID,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-	}
-
-	if err := h.db.SaveCredentials(cred); err != nil {
-		utils.Errorf("failed to save credentials user_id=%s facebook_user_id=%s page_id=%s err=%v", userID, facebookUserID, pageID, err)
-		http.Redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials",
-			http.StatusFound)
-		return
+	// Save one credential per Page the user granted, so every Page can be
+	// picked as a post's account; a user without Pages gets one without a Page
+	if len(pages) == 0 {
+		pages = []facebookPage{{}}
+	}
+	for _, page := range pages {
+		cred := &models.PlatformCredentials{
+			ID:                  uuid.New().String(),
+			UserID:              userID,
+			Platform:            models.Facebook,
+			AccessToken:         accessToken,
+			TokenType:           "Bearer",
+			ExpiresAt:           expiresAt,
+			PlatformUserID:      facebookUserID,
+			PlatformPageID:      page.ID,
+			PlatformDisplayName: page.Name,
+			GrantedScopes:       grantedScopes,
+			CreatedAt:           time.Now(),
+			UpdatedAt:           time.Now(),
+		}
+
+		if err := h.db.SaveCredentials(cred); err != nil {
+			utils.Errorf("failed to save credentials user_id=%s facebook_user_id=%s page_id=%s err=%v", userID, facebookUserID, page.ID, err)
+			h.redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials")
+			return
+		}
+		utils.Infof("credentials saved user_id=%s platform=%s facebook_user_id=%s page_id=%s", userID, models.Facebook, facebookUserID, page.ID)
 	}
-	utils.Infof("credentials saved user_id=%s platform=%s facebook_user_id=%s page_id=%s", userID, models.Facebook, facebookUserID, pageID)
+	h.recordConnected(r, userID, models.Facebook)
 
 	// Success! Redirect to success page
 	utils.Infof("completed successfully user_id=%s", userID)
-	http.Redirect(w, r, "/oauth/success?platform=facebook", http.StatusFound)
+	h.redirect(w, r, "/oauth/success?platform=facebook")
 }
 
-func (h *OAuthHandler) exchangeCodeForFacebookToken(code string) (string, int, error) {
+func (h *OAuthHandler) exchangeCodeForFacebookToken(ctx context.Context, code string) (string, int, error) {
 	cfg := config.Load()
 	utils.Debugf("facebook token exchange request start")
 
 	tokenURL := fmt.Sprintf(
-		"https://graph.facebook.com/%s/oauth/access_token?client_id=%s&client_secret=%s&redirect_uri=%s&code=%s",
+		"%s/%s/oauth/access_token?client_id=%s&client_secret=%s&redirect_uri=%s&code=%s",
+		cfg.FacebookGraphBase,
 		cfg.FacebookVersion,
 		cfg.FacebookAppID,
 		cfg.FacebookAppSecret,
@@ -162,7 +177,7 @@ func (h *OAuthHandler) exchangeCodeForFacebookToken(code string) (string, int, e
 		code,
 	)
 
-	resp, err := facebookHTTPClient.Get(tokenURL)
+	resp, err := h.get(ctx, tokenURL)
 	if err != nil {
 		utils.Errorf("facebook token exchange http request failed err=%v", err)
 		return "", 0, err
@@ -201,32 +216,39 @@ func (h *OAuthHandler) exchangeCodeForFacebookToken(code string) (string, int, e
 	return tokenResp.AccessToken, tokenResp.ExpiresIn, nil
 }
 
-// getFacebookUserIdentity fetches the Facebook user ID and primary page ID
+// facebookPage is a Page returned by /me/accounts.
+type facebookPage struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// getFacebookUserIdentity fetches the Facebook user ID and the Pages the user
+// granted the app, in the order Facebook lists them.
 // This binds the token to a specific Facebook identity
-func (h *OAuthHandler) getFacebookUserIdentity(accessToken string) (string, string, error) {
+func (h *OAuthHandler) getFacebookUserIdentity(ctx context.Context, accessToken string) (string, []facebookPage, error) {
 	cfg := config.Load()
 	utils.Debugf("facebook identity fetch start")
 
 	// Get the authenticated user's ID
-	userURL := fmt.Sprintf("https://graph.facebook.com/%s/me?access_token=%s", cfg.FacebookVersion, accessToken)
+	userURL := fmt.Sprintf("%s/%s/me?access_token=%s", cfg.FacebookGraphBase, cfg.FacebookVersion, accessToken)
 
-	resp, err := facebookHTTPClient.Get(userURL)
+	resp, err := h.get(ctx, userURL)
 	if err != nil {
 		utils.Errorf("facebook identity fetch user info request failed err=%v", err)
-		return "", "", fmt.Errorf("failed to fetch Facebook user info: %w", err)
+		return "", nil, fmt.Errorf("failed to fetch Facebook user info: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		utils.Errorf("facebook identity fetch user info api status=%d", resp.StatusCode)
-		return "", "", fmt.Errorf("Facebook API error: %s", string(body))
+		return "", nil, fmt.Errorf("Facebook API error: %s", string(body))
 	}
 
 	bodyData, err := io.ReadAll(resp.Body)
 	if err != nil {
 		utils.Errorf("facebook identity fetch user info read body failed err=%v", err)
-		return "", "", fmt.Errorf("failed to read Facebook user response: %w", err)
+		return "", nil, fmt.Errorf("failed to read Facebook user response: %w", err)
 	}
 	var userResp struct {
 		ID string `json:"id"`
@@ -234,49 +256,81 @@ func (h *OAuthHandler) getFacebookUserIdentity(accessToken string) (string, stri
 
 	if err := json.Unmarshal(bodyData, &userResp); err != nil {
 		utils.Errorf("facebook identity fetch user info parse response failed err=%v", err)
-		return "", "", fmt.Errorf("failed to parse Facebook user response: %w", err)
+		return "", nil, fmt.Errorf("failed to parse Facebook user response: %w", err)
 	}
 
 	facebookUserID := userResp.ID
 
-	// Get the user's pages (fetch first page as primary)
-	pagesURL := fmt.Sprintf("https://graph.facebook.com/%s/me/accounts?access_token=%s", cfg.FacebookVersion, accessToken)
+	// Get the user's pages
+	pagesURL := fmt.Sprintf("%s/%s/me/accounts?access_token=%s", cfg.FacebookGraphBase, cfg.FacebookVersion, accessToken)
 
-	resp, err = facebookHTTPClient.Get(pagesURL)
+	resp, err = h.get(ctx, pagesURL)
 	if err != nil {
 		utils.Errorf("facebook identity fetch pages request failed user_id=%s err=%v", facebookUserID, err)
-		return facebookUserID, "", fmt.Errorf("failed to fetch Facebook pages: %w", err)
+		return facebookUserID, nil, fmt.Errorf("failed to fetch Facebook pages: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		utils.Errorf("facebook identity fetch pages api status=%d user_id=%s", resp.StatusCode, facebookUserID)
-		return facebookUserID, "", fmt.Errorf("Facebook pages API error: %s", string(body))
+		return facebookUserID, nil, fmt.Errorf("Facebook pages API error: %s", string(body))
 	}
 
 	var pagesResp struct {
-		Data []struct {
-			ID string `json:"id"`
-		} `json:"data"`
+		Data []facebookPage `json:"data"`
 	}
 
 	bodyData, err = io.ReadAll(resp.Body)
 	if err != nil {
 		utils.Errorf("facebook identity fetch pages read body failed user_id=%s err=%v", facebookUserID, err)
-		return facebookUserID, "", fmt.Errorf("failed to read Facebook pages response: %w", err)
+		return facebookUserID, nil, fmt.Errorf("failed to read Facebook pages response: %w", err)
 	}
 	if err := json.Unmarshal(bodyData, &pagesResp); err != nil {
 		utils.Errorf("facebook identity fetch pages parse response failed user_id=%s err=%v", facebookUserID, err)
-		return facebookUserID, "", fmt.Errorf("failed to parse Facebook pages response: %w", err)
+		return facebookUserID, nil, fmt.Errorf("failed to parse Facebook pages response: %w", err)
+	}
+
+	utils.Debugf("facebook identity fetch success user_id=%s pages=%d", facebookUserID, len(pagesResp.Data))
+
+	return facebookUserID, pagesResp.Data, nil
+}
+
+// getFacebookGrantedPermissions returns the permissions the user granted the
+// app, from /me/permissions; declined and expired ones are left out.
+func (h *OAuthHandler) getFacebookGrantedPermissions(ctx context.Context, accessToken string) ([]string, error) {
+	cfg := config.Load()
+	permissionsURL := fmt.Sprintf("%s/%s/me/permissions?access_token=%s", cfg.FacebookGraphBase, cfg.FacebookVersion, accessToken)
+
+	resp, err := h.get(ctx, permissionsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Facebook permissions: %w", err)
 	}
+	defer resp.Body.Close()
 
-	pageID := ""
-	if len(pagesResp.Data) > 0 {
-		pageID = pagesResp.Data[0].ID
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Facebook permissions response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Facebook permissions API error: %s", string(body))
 	}
 
-	utils.Debugf("facebook identity fetch success user_id=%s page_id=%s", facebookUserID, pageID)
+	var permissionsResp struct {
+		Data []struct {
+			Permission string `json:"permission"`
+			Status     string `json:"status"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &permissionsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Facebook permissions response: %w", err)
+	}
 
-	return facebookUserID, pageID, nil
+	granted := []string{}
+	for _, p := range permissionsResp.Data {
+		if p.Status == "granted" {
+			granted = append(granted, p.Permission)
+		}
+	}
+	return granted, nil
 }
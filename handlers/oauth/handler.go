@@ -1,20 +1,92 @@
 package oauth
 
 import (
+	"SocialMediaAPI/config"
 	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
 	"SocialMediaAPI/services"
+	"SocialMediaAPI/utils"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
 )
 
 // OAuthHandler holds dependencies for all OAuth-related HTTP handlers.
 type OAuthHandler struct {
 	db                *database.Database
 	oauthStateService *services.OAuthStateService
+	client            *http.Client
 }
 
 // NewOAuthHandler creates a new OAuthHandler with the required dependencies.
-func NewOAuthHandler(db *database.Database, oauthStateService *services.OAuthStateService) *OAuthHandler {
+// client is shared by every platform's token and identity requests; if nil,
+// one is built with the configured OAuth timeout.
+func NewOAuthHandler(db *database.Database, oauthStateService *services.OAuthStateService, client *http.Client) *OAuthHandler {
+	if client == nil {
+		client = utils.NewHTTPClient(config.Load().OAuthHTTPTimeout)
+	}
 	return &OAuthHandler{
 		db:                db,
 		oauthStateService: oauthStateService,
+		client:            client,
+	}
+}
+
+// accountIdentity is the connected account as reported by the platform.
+// Username and DisplayName are only used for display ("Connected as @handle").
+type accountIdentity struct {
+	ID          string
+	Username    string
+	DisplayName string
+}
+
+// parseScopes splits the scope of a token response into the granted scopes.
+// Most platforms separate them with spaces, TikTok with commas.
+func parseScopes(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool { return r == ' ' || r == ',' })
+}
+
+// get issues a GET bound to ctx, so the request is abandoned when the
+// client disconnects or the server shuts down.
+func (h *OAuthHandler) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
+	return h.client.Do(req)
+}
+
+// postForm issues a form-encoded POST bound to ctx.
+func (h *OAuthHandler) postForm(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return h.client.Do(req)
+}
+
+// redirect sends the browser to target after checking it against
+// OAUTH_REDIRECT_ALLOWLIST, so a redirect built from provider or user input
+// can never send the user to an arbitrary site. Disallowed targets go to the
+// OAuth error page instead.
+func (h *OAuthHandler) redirect(w http.ResponseWriter, r *http.Request, target string) {
+	if !utils.IsAllowedRedirect(target, config.Load().OAuthRedirectAllowlist) {
+		utils.Warnf("oauth redirect blocked target=%q", target)
+		target = "/oauth/error?error=invalid_redirect&description=Redirect+target+not+allowed"
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// recordConnected writes an audit entry for a platform connected via OAuth.
+func (h *OAuthHandler) recordConnected(r *http.Request, userID string, platform models.Platform) {
+	h.db.RecordAudit(models.AuditEntry{
+		UserID:   userID,
+		Action:   models.AuditPlatformConnected,
+		Platform: string(platform),
+		Success:  true,
+		Details:  "oauth",
+		IP:       utils.TrustedClientIP(r),
+	})
 }
@@ -0,0 +1,62 @@
+package oauth
+
+import (
+	"SocialMediaAPI/models"
+	"context"
+	"fmt"
+)
+
+// ResolveIdentity looks up the account behind cred's access token with the
+// same identity calls the OAuth callbacks make, and fills in the platform
+// user and page IDs, username and display name. Fields already set are
+// kept. Manually entered credentials need this: Facebook, Instagram and
+// LinkedIn cannot publish without the IDs.
+func (h *OAuthHandler) ResolveIdentity(ctx context.Context, cred *models.PlatformCredentials) error {
+	var identity accountIdentity
+	var pageID string
+	var err error
+
+	switch cred.Platform {
+	case models.Facebook:
+		var pages []facebookPage
+		identity.ID, pages, err = h.getFacebookUserIdentity(ctx, cred.AccessToken)
+		// Name the chosen Page, or default to the first one
+		for i, page := range pages {
+			if page.ID == cred.PlatformPageID || (cred.PlatformPageID == "" && i == 0) {
+				pageID, identity.DisplayName = page.ID, page.Name
+				break
+			}
+		}
+	case models.Instagram:
+		identity.ID, pageID, identity.Username, err = h.getInstagramBusinessIdentity(ctx, cred.AccessToken)
+	case models.LinkedIn:
+		identity, err = h.getLinkedInIdentity(ctx, cred.AccessToken)
+	case models.Threads:
+		identity, err = h.getThreadsIdentity(ctx, cred.AccessToken)
+	case models.Twitter:
+		identity, err = h.getTwitterUserIdentity(ctx, cred.AccessToken)
+	case models.YouTube:
+		identity, err = h.getYouTubeChannelIdentity(ctx, cred.AccessToken)
+	case models.TikTok:
+		// The open_id only comes with the token exchange
+		identity.DisplayName, err = h.getTikTokDisplayName(ctx, cred.AccessToken)
+	default:
+		return fmt.Errorf("no identity lookup for platform %s", cred.Platform)
+	}
+	if err != nil {
+		return err
+	}
+
+	fillEmpty(&cred.PlatformUserID, identity.ID)
+	fillEmpty(&cred.PlatformPageID, pageID)
+	fillEmpty(&cred.PlatformUsername, identity.Username)
+	fillEmpty(&cred.PlatformDisplayName, identity.DisplayName)
+	return nil
+}
+
+// fillEmpty sets *field to value unless it is already set.
+func fillEmpty(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,8 +16,6 @@ import (
 	"github.com/google/uuid"
 )
 
-var instagramHTTPClient = &http.Client{Timeout: 10 * time.Second}
-
 // InitiateInstagramOAuth starts the Instagram OAuth flow
 func (h *OAuthHandler) InitiateInstagramOAuth(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
@@ -80,8 +79,8 @@ func (h *OAuthHandler) HandleInstagramCallback(w http.ResponseWriter, r *http.Re
 	if errorParam != "" {
 		errorDesc := r.URL.Query().Get("error_description")
 		utils.Warnf("instagram callback oauth error error=%s description=%s", errorParam, sanitizeMetaError(errorDesc))
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
-			errorParam, url.QueryEscape(errorDesc)), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
+			url.QueryEscape(errorParam), url.QueryEscape(errorDesc)))
 		return
 	}
 
@@ -113,32 +112,32 @@ func (h *OAuthHandler) HandleInstagramCallback(w http.ResponseWriter, r *http.Re
 
 	userID := oauthState.UserID
 
-	shortToken, _, err := h.exchangeCodeForInstagramToken(strings.TrimSuffix(code, "#_"))
+	shortToken, _, err := h.exchangeCodeForInstagramToken(r.Context(), strings.TrimSuffix(code, "#_"))
 	if err != nil {
 		utils.Errorf("instagram token exchange failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
 	utils.Infof("instagram token exchange success user_id=%s", userID)
 
-	longLivedToken, expiresIn, err := h.exchangeInstagramLongLivedToken(shortToken)
+	longLivedToken, expiresIn, err := h.exchangeInstagramLongLivedToken(r.Context(), shortToken)
 	if err != nil {
 		utils.Errorf("instagram long-lived token exchange failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=long_lived_exchange&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=long_lived_exchange&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
 	utils.Infof("instagram long-lived token exchange success user_id=%s expires_in=%d", userID, expiresIn)
 
-	instagramUserID, pageID, err := h.getInstagramBusinessIdentity(longLivedToken)
+	instagramUserID, pageID, username, err := h.getInstagramBusinessIdentity(r.Context(), longLivedToken)
 	if err != nil {
 		utils.Errorf("instagram identity fetch failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=identity_fetch&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=identity_fetch&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
-	utils.Infof("instagram identity fetch success user_id=%s instagram_user_id=%s page_id=%s", userID, instagramUserID, pageID)
+	utils.Infof("instagram identity fetch success user_id=%s instagram_user_id=%s page_id=%s username=%s", userID, instagramUserID, pageID, username)
 
 	var expiresAt *time.Time
 	if expiresIn > 0 {
@@ -147,32 +146,33 @@ func (h *OAuthHandler) HandleInstagramCallback(w http.ResponseWriter, r *http.Re
 	}
 
 	cred := &models.PlatformCredentials{
-		ID:             uuid.New().String(),
-		UserID:         userID,
-		Platform:       models.Instagram,
-		AccessToken:    longLivedToken,
-		TokenType:      "Bearer",
-		ExpiresAt:      expiresAt,
-		PlatformUserID: instagramUserID,
-		PlatformPageID: pageID,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:               uuid.New().String(),
+		UserID:           userID,
+		Platform:         models.Instagram,
+		AccessToken:      longLivedToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		PlatformUserID:   instagramUserID,
+		PlatformPageID:   pageID,
+		PlatformUsername: username,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
 	}
 
 	if err := h.db.SaveCredentials(cred); err != nil {
 		utils.Errorf("instagram save credentials failed user_id=%s instagram_user_id=%s page_id=%s err=%v", userID, instagramUserID, pageID, err)
-		http.Redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials",
-			http.StatusFound)
+		h.redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials")
 		return
 	}
 
 	utils.Infof("instagram credentials saved user_id=%s platform=%s instagram_user_id=%s page_id=%s", userID, models.Instagram, instagramUserID, pageID)
+	h.recordConnected(r, userID, models.Instagram)
 	utils.Infof("instagram callback completed successfully user_id=%s", userID)
 
-	http.Redirect(w, r, "/oauth/success?platform=instagram", http.StatusFound)
+	h.redirect(w, r, "/oauth/success?platform=instagram")
 }
 
-func (h *OAuthHandler) exchangeCodeForInstagramToken(code string) (string, int, error) {
+func (h *OAuthHandler) exchangeCodeForInstagramToken(ctx context.Context, code string) (string, int, error) {
 	cfg := config.Load()
 	utils.Debugf("instagram token exchange request start")
 
@@ -186,7 +186,7 @@ func (h *OAuthHandler) exchangeCodeForInstagramToken(code string) (string, int,
 	form.Set("redirect_uri", cfg.InstagramRedirectURI)
 	form.Set("code", code)
 
-	resp, err := instagramHTTPClient.PostForm(tokenURL, form)
+	resp, err := h.postForm(ctx, tokenURL, form)
 	if err != nil {
 		utils.Errorf("instagram token exchange http request failed err=%v", err)
 		return "", 0, err
@@ -224,18 +224,19 @@ func (h *OAuthHandler) exchangeCodeForInstagramToken(code string) (string, int,
 	return tokenResp.AccessToken, tokenResp.ExpiresIn, nil
 }
 
-func (h *OAuthHandler) exchangeInstagramLongLivedToken(shortToken string) (string, int, error) {
+func (h *OAuthHandler) exchangeInstagramLongLivedToken(ctx context.Context, shortToken string) (string, int, error) {
 	cfg := config.Load()
 	utils.Debugf("instagram long-lived token exchange request start")
 
 	// Instagram Business Login uses ig_exchange_token (not fb_exchange_token), no version prefix
 	exchangeURL := fmt.Sprintf(
-		"https://graph.instagram.com/access_token?grant_type=ig_exchange_token&client_secret=%s&access_token=%s",
+		"%s/access_token?grant_type=ig_exchange_token&client_secret=%s&access_token=%s",
+		cfg.InstagramGraphBase,
 		cfg.InstagramAppSecret,
 		url.QueryEscape(shortToken),
 	)
 
-	resp, err := instagramHTTPClient.Get(exchangeURL)
+	resp, err := h.get(ctx, exchangeURL)
 	if err != nil {
 		utils.Errorf("instagram long-lived exchange http request failed err=%v", err)
 		return "", 0, err
@@ -273,34 +274,36 @@ func (h *OAuthHandler) exchangeInstagramLongLivedToken(shortToken string) (strin
 	return tokenResp.AccessToken, tokenResp.ExpiresIn, nil
 }
 
-// getInstagramBusinessIdentity fetches the Instagram user ID via the Instagram Business Login /me endpoint.
-func (h *OAuthHandler) getInstagramBusinessIdentity(accessToken string) (string, string, error) {
+// getInstagramBusinessIdentity fetches the Instagram user ID and username via the Instagram Business Login /me endpoint.
+// Returns: instagramUserID, pageID, username, error
+func (h *OAuthHandler) getInstagramBusinessIdentity(ctx context.Context, accessToken string) (string, string, string, error) {
 	cfg := config.Load()
 	utils.Debugf("instagram business identity fetch start")
 
 	// Instagram Business Login: /me returns user_id and username directly
 	meURL := fmt.Sprintf(
-		"https://graph.instagram.com/%s/me?fields=user_id,username&access_token=%s",
+		"%s/%s/me?fields=user_id,username&access_token=%s",
+		cfg.InstagramGraphBase,
 		cfg.InstagramVersion,
 		url.QueryEscape(accessToken),
 	)
 
-	resp, err := instagramHTTPClient.Get(meURL)
+	resp, err := h.get(ctx, meURL)
 	if err != nil {
 		utils.Errorf("instagram business identity http request failed err=%v", err)
-		return "", "", fmt.Errorf("failed to fetch Instagram identity: %w", err)
+		return "", "", "", fmt.Errorf("failed to fetch Instagram identity: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		utils.Errorf("instagram business identity read body failed err=%v", err)
-		return "", "", fmt.Errorf("failed to read identity response: %w", err)
+		return "", "", "", fmt.Errorf("failed to read identity response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		utils.Errorf("instagram business identity api status=%d", resp.StatusCode)
-		return "", "", fmt.Errorf("Instagram identity API error: %s", string(body))
+		return "", "", "", fmt.Errorf("Instagram identity API error: %s", string(body))
 	}
 
 	var meResp struct {
@@ -311,7 +314,7 @@ func (h *OAuthHandler) getInstagramBusinessIdentity(accessToken string) (string,
 
 	if err := json.Unmarshal(body, &meResp); err != nil {
 		utils.Errorf("instagram business identity parse response failed err=%v", err)
-		return "", "", fmt.Errorf("failed to parse identity response: %w", err)
+		return "", "", "", fmt.Errorf("failed to parse identity response: %w", err)
 	}
 
 	// user_id is the Instagram-scoped user ID needed for the Content Publishing API
@@ -322,11 +325,11 @@ func (h *OAuthHandler) getInstagramBusinessIdentity(accessToken string) (string,
 
 	if instagramUserID == "" {
 		utils.Warnf("instagram business identity returned empty user_id")
-		return "", "", fmt.Errorf("Instagram identity API returned empty user ID")
+		return "", "", "", fmt.Errorf("Instagram identity API returned empty user ID")
 	}
 
 	utils.Debugf("instagram business identity found user_id=%s username=%s", instagramUserID, meResp.Username)
-	return instagramUserID, "", nil
+	return instagramUserID, "", meResp.Username, nil
 }
 
 func sanitizeMetaError(errMsg string) string {
@@ -0,0 +1,259 @@
+package oauth
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InitiateLinkedInOAuth starts the LinkedIn OAuth 2.0 flow. The openid and
+// profile scopes give access to /v2/userinfo for the member ID;
+// w_member_social allows sharing on the member's behalf.
+func (h *OAuthHandler) InitiateLinkedInOAuth(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.Warnf("linkedin oauth initiate unauthorized: missing user id in context")
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	cfg := config.Load()
+
+	if cfg.LinkedInClientID == "" {
+		utils.Errorf("linkedin oauth initiate config missing: LINKEDIN_CLIENT_ID")
+		utils.RespondWithError(w, http.StatusInternalServerError,
+			"LinkedIn Client ID not configured. Set LINKEDIN_CLIENT_ID environment variable")
+		return
+	}
+
+	if cfg.LinkedInRedirectURI == "" {
+		utils.Errorf("linkedin oauth initiate config missing: LINKEDIN_REDIRECT_URI")
+		utils.RespondWithError(w, http.StatusInternalServerError,
+			"LinkedIn Redirect URI not configured. Set LINKEDIN_REDIRECT_URI environment variable")
+		return
+	}
+
+	state := h.oauthStateService.GenerateState(userID, "linkedin")
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", cfg.LinkedInClientID)
+	params.Set("redirect_uri", cfg.LinkedInRedirectURI)
+	params.Set("scope", strings.Join([]string{
+		"openid",
+		"profile",
+		"w_member_social",
+	}, " "))
+	params.Set("state", state)
+
+	authURL := "https://www.linkedin.com/oauth/v2/authorization?" + params.Encode()
+	utils.Infof("linkedin oauth initiate success user_id=%s", userID)
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
+		"auth_url": authURL,
+		"state":    state,
+	})
+}
+
+// HandleLinkedInCallback handles the OAuth callback from LinkedIn.
+func (h *OAuthHandler) HandleLinkedInCallback(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+	errorParam := r.URL.Query().Get("error")
+
+	utils.Infof("linkedin callback received remote=%s has_code=%t has_state=%t has_error=%t",
+		r.RemoteAddr, code != "", state != "", errorParam != "")
+
+	if errorParam != "" {
+		errorDesc := r.URL.Query().Get("error_description")
+		utils.Warnf("linkedin callback oauth error error=%s description=%s", errorParam, errorDesc)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
+			url.QueryEscape(errorParam), url.QueryEscape(errorDesc)))
+		return
+	}
+
+	if code == "" {
+		utils.Warnf("linkedin callback missing authorization code")
+		utils.RespondWithError(w, http.StatusBadRequest, "Missing authorization code")
+		return
+	}
+
+	if state == "" {
+		utils.Warnf("linkedin callback missing state parameter")
+		utils.RespondWithError(w, http.StatusBadRequest, "Missing state parameter")
+		return
+	}
+
+	oauthState, valid := h.oauthStateService.ValidateState(state)
+	if !valid {
+		utils.Warnf("linkedin callback invalid or expired state")
+		utils.RespondWithError(w, http.StatusBadRequest,
+			"Invalid or expired state token. Please try connecting again.")
+		return
+	}
+
+	if oauthState.Platform != "linkedin" {
+		utils.Warnf("linkedin callback invalid platform in state platform=%s", oauthState.Platform)
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid state for LinkedIn OAuth")
+		return
+	}
+
+	userID := oauthState.UserID
+
+	accessToken, refreshToken, expiresIn, scopes, err := h.exchangeCodeForLinkedInToken(r.Context(), code)
+	if err != nil {
+		utils.Errorf("linkedin token exchange failed user_id=%s err=%v", userID, err)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
+			url.QueryEscape(err.Error())))
+		return
+	}
+	utils.Infof("linkedin token exchange success user_id=%s expires_in=%d", userID, expiresIn)
+
+	// The member URN is the author of every share, so publishing is
+	// impossible without it.
+	identity, err := h.getLinkedInIdentity(r.Context(), accessToken)
+	if err != nil {
+		utils.Errorf("linkedin identity fetch failed user_id=%s err=%v", userID, err)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=identity_fetch&description=%s",
+			url.QueryEscape(err.Error())))
+		return
+	}
+	utils.Infof("linkedin identity fetch success user_id=%s linkedin_urn=%s", userID, identity.ID)
+
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		expTime := time.Now().Add(time.Duration(expiresIn) * time.Second)
+		expiresAt = &expTime
+	}
+
+	cred := &models.PlatformCredentials{
+		ID:                  uuid.New().String(),
+		UserID:              userID,
+		Platform:            models.LinkedIn,
+		AccessToken:         accessToken,
+		RefreshToken:        refreshToken,
+		TokenType:           "Bearer",
+		ExpiresAt:           expiresAt,
+		GrantedScopes:       scopes,
+		PlatformUserID:      identity.ID,
+		PlatformUsername:    identity.Username,
+		PlatformDisplayName: identity.DisplayName,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+	}
+
+	if err := h.db.SaveCredentials(cred); err != nil {
+		utils.Errorf("linkedin save credentials failed user_id=%s linkedin_urn=%s err=%v", userID, identity.ID, err)
+		h.redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials")
+		return
+	}
+
+	utils.Infof("linkedin credentials saved user_id=%s platform=%s linkedin_urn=%s", userID, models.LinkedIn, identity.ID)
+	h.recordConnected(r, userID, models.LinkedIn)
+
+	h.redirect(w, r, "/oauth/success?platform=linkedin")
+}
+
+// exchangeCodeForLinkedInToken exchanges the authorization code for an
+// access token. LinkedIn only issues refresh tokens to approved partner apps,
+// so refreshToken is usually empty.
+// Returns: accessToken, refreshToken, expiresIn, granted scopes, error
+func (h *OAuthHandler) exchangeCodeForLinkedInToken(ctx context.Context, code string) (string, string, int, []string, error) {
+	cfg := config.Load()
+	utils.Debugf("linkedin token exchange request start")
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.LinkedInRedirectURI)
+	form.Set("client_id", cfg.LinkedInClientID)
+	form.Set("client_secret", cfg.LinkedInClientSecret)
+
+	resp, err := h.postForm(ctx, "https://www.linkedin.com/oauth/v2/accessToken", form)
+	if err != nil {
+		return "", "", 0, nil, fmt.Errorf("linkedin token exchange request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", 0, nil, fmt.Errorf("failed to read token response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", "", 0, nil, fmt.Errorf("linkedin token exchange failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+		Scope        string `json:"scope"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", "", 0, nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	if tokenResp.AccessToken == "" {
+		return "", "", 0, nil, fmt.Errorf("linkedin returned empty access token")
+	}
+
+	utils.Debugf("linkedin token exchange success expires_in=%d", tokenResp.ExpiresIn)
+	return tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn, parseScopes(tokenResp.Scope), nil
+}
+
+// getLinkedInIdentity fetches the member behind accessToken from the OpenID
+// Connect userinfo endpoint. The returned ID is the member URN
+// (urn:li:person:{sub}) that shares are authored as.
+func (h *OAuthHandler) getLinkedInIdentity(ctx context.Context, accessToken string) (accountIdentity, error) {
+	utils.Debugf("linkedin identity fetch start")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Load().LinkedInAPIBase+"/v2/userinfo", nil)
+	if err != nil {
+		return accountIdentity{}, fmt.Errorf("failed to create identity request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return accountIdentity{}, fmt.Errorf("linkedin identity request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return accountIdentity{}, fmt.Errorf("failed to read identity response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return accountIdentity{}, fmt.Errorf("linkedin identity API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var userInfo struct {
+		Sub  string `json:"sub"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &userInfo); err != nil {
+		return accountIdentity{}, fmt.Errorf("failed to parse identity response: %w", err)
+	}
+
+	if userInfo.Sub == "" {
+		return accountIdentity{}, fmt.Errorf("linkedin returned empty member ID")
+	}
+
+	// LinkedIn has no public handle to show, so only the display name is set
+	return accountIdentity{
+		ID:          "urn:li:person:" + userInfo.Sub,
+		DisplayName: userInfo.Name,
+	}, nil
+}
@@ -0,0 +1,297 @@
+package oauth
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InitiateThreadsOAuth starts the Threads OAuth flow
+func (h *OAuthHandler) InitiateThreadsOAuth(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.Warnf("threads oauth initiate unauthorized: missing user id in context")
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	cfg := config.Load()
+
+	if cfg.ThreadsAppID == "" {
+		utils.Errorf("threads oauth initiate config missing: THREADS_APP_ID")
+		utils.RespondWithError(w, http.StatusInternalServerError,
+			"Threads App ID not configured. Set THREADS_APP_ID environment variable")
+		return
+	}
+
+	if cfg.ThreadsRedirectURI == "" {
+		utils.Errorf("threads oauth initiate config missing: THREADS_REDIRECT_URI")
+		utils.RespondWithError(w, http.StatusInternalServerError,
+			"Threads Redirect URI not configured. Set THREADS_REDIRECT_URI environment variable")
+		return
+	}
+
+	state := h.oauthStateService.GenerateState(userID, "threads")
+
+	params := url.Values{}
+	params.Set("client_id", cfg.ThreadsAppID)
+	params.Set("redirect_uri", cfg.ThreadsRedirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join([]string{
+		"threads_basic",
+		"threads_content_publish",
+	}, ","))
+	params.Set("state", state)
+
+	authURL := "https://threads.net/oauth/authorize?" + params.Encode()
+	utils.Infof("threads oauth initiate success user_id=%s", userID)
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
+		"auth_url": authURL,
+		"state":    state,
+	})
+}
+
+// HandleThreadsCallback handles the OAuth callback from Threads (Meta)
+func (h *OAuthHandler) HandleThreadsCallback(w http.ResponseWriter, r *http.Request) {
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+	errorParam := r.URL.Query().Get("error")
+
+	utils.Infof("threads callback received remote=%s has_code=%t has_state=%t has_error=%t", r.RemoteAddr, code != "", state != "", errorParam != "")
+
+	if errorParam != "" {
+		errorDesc := r.URL.Query().Get("error_description")
+		utils.Warnf("threads callback oauth error error=%s description=%s", errorParam, sanitizeMetaError(errorDesc))
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
+			url.QueryEscape(errorParam), url.QueryEscape(errorDesc)))
+		return
+	}
+
+	if code == "" {
+		utils.Warnf("threads callback missing authorization code")
+		utils.RespondWithError(w, http.StatusBadRequest, "Missing authorization code")
+		return
+	}
+
+	if state == "" {
+		utils.Warnf("threads callback missing state parameter")
+		utils.RespondWithError(w, http.StatusBadRequest, "Missing state parameter")
+		return
+	}
+
+	oauthState, valid := h.oauthStateService.ValidateState(state)
+	if !valid {
+		utils.Warnf("threads callback invalid or expired state")
+		utils.RespondWithError(w, http.StatusBadRequest,
+			"Invalid or expired state token. Please try connecting again.")
+		return
+	}
+
+	if oauthState.Platform != "threads" {
+		utils.Warnf("threads callback invalid platform in state platform=%s", oauthState.Platform)
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid state for Threads OAuth")
+		return
+	}
+
+	userID := oauthState.UserID
+
+	shortToken, err := h.exchangeCodeForThreadsToken(r.Context(), strings.TrimSuffix(code, "#_"))
+	if err != nil {
+		utils.Errorf("threads token exchange failed user_id=%s err=%v", userID, err)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
+			url.QueryEscape(err.Error())))
+		return
+	}
+	utils.Infof("threads token exchange success user_id=%s", userID)
+
+	longLivedToken, expiresIn, err := h.exchangeThreadsLongLivedToken(r.Context(), shortToken)
+	if err != nil {
+		utils.Errorf("threads long-lived token exchange failed user_id=%s err=%v", userID, err)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=long_lived_exchange&description=%s",
+			url.QueryEscape(err.Error())))
+		return
+	}
+	utils.Infof("threads long-lived token exchange success user_id=%s expires_in=%d", userID, expiresIn)
+
+	identity, err := h.getThreadsIdentity(r.Context(), longLivedToken)
+	if err != nil {
+		utils.Errorf("threads identity fetch failed user_id=%s err=%v", userID, err)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=identity_fetch&description=%s",
+			url.QueryEscape(err.Error())))
+		return
+	}
+	utils.Infof("threads identity fetch success user_id=%s threads_user_id=%s username=%s", userID, identity.ID, identity.Username)
+
+	var expiresAt *time.Time
+	if expiresIn > 0 {
+		expTime := time.Now().Add(time.Duration(expiresIn) * time.Second)
+		expiresAt = &expTime
+	}
+
+	cred := &models.PlatformCredentials{
+		ID:                  uuid.New().String(),
+		UserID:              userID,
+		Platform:            models.Threads,
+		AccessToken:         longLivedToken,
+		TokenType:           "Bearer",
+		ExpiresAt:           expiresAt,
+		PlatformUserID:      identity.ID,
+		PlatformUsername:    identity.Username,
+		PlatformDisplayName: identity.DisplayName,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+	}
+
+	if err := h.db.SaveCredentials(cred); err != nil {
+		utils.Errorf("threads save credentials failed user_id=%s threads_user_id=%s err=%v", userID, identity.ID, err)
+		h.redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials")
+		return
+	}
+
+	utils.Infof("threads credentials saved user_id=%s platform=%s threads_user_id=%s", userID, models.Threads, identity.ID)
+	h.recordConnected(r, userID, models.Threads)
+
+	h.redirect(w, r, "/oauth/success?platform=threads")
+}
+
+func (h *OAuthHandler) exchangeCodeForThreadsToken(ctx context.Context, code string) (string, error) {
+	cfg := config.Load()
+	utils.Debugf("threads token exchange request start")
+
+	form := url.Values{}
+	form.Set("client_id", cfg.ThreadsAppID)
+	form.Set("client_secret", cfg.ThreadsAppSecret)
+	form.Set("grant_type", "authorization_code")
+	form.Set("redirect_uri", cfg.ThreadsRedirectURI)
+	form.Set("code", code)
+
+	resp, err := h.postForm(ctx, cfg.ThreadsGraphBase+"/oauth/access_token", form)
+	if err != nil {
+		utils.Errorf("threads token exchange http request failed err=%v", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		utils.Errorf("threads token exchange read body failed err=%v", err)
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		utils.Errorf("threads token exchange api status=%d", resp.StatusCode)
+		return "", fmt.Errorf("Threads token API error: %s", string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		utils.Errorf("threads token exchange parse response failed err=%v", err)
+		return "", err
+	}
+
+	if tokenResp.AccessToken == "" {
+		utils.Errorf("threads token exchange returned empty access token")
+		return "", fmt.Errorf("Threads token API returned empty access token")
+	}
+
+	utils.Debugf("threads token exchange request success")
+	return tokenResp.AccessToken, nil
+}
+
+// exchangeThreadsLongLivedToken swaps the one-hour token for a 60-day one.
+func (h *OAuthHandler) exchangeThreadsLongLivedToken(ctx context.Context, shortToken string) (string, int, error) {
+	cfg := config.Load()
+	utils.Debugf("threads long-lived token exchange request start")
+
+	params := url.Values{}
+	params.Set("grant_type", "th_exchange_token")
+	params.Set("client_secret", cfg.ThreadsAppSecret)
+	params.Set("access_token", shortToken)
+
+	resp, err := h.get(ctx, cfg.ThreadsGraphBase+"/access_token?"+params.Encode())
+	if err != nil {
+		utils.Errorf("threads long-lived exchange http request failed err=%v", err)
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		utils.Errorf("threads long-lived exchange read body failed err=%v", err)
+		return "", 0, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		utils.Errorf("threads long-lived exchange api status=%d", resp.StatusCode)
+		return "", 0, fmt.Errorf("long-lived token exchange failed: %s", string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		utils.Errorf("threads long-lived exchange parse response failed err=%v", err)
+		return "", 0, err
+	}
+
+	if tokenResp.AccessToken == "" {
+		utils.Errorf("threads long-lived exchange returned empty token")
+		return "", 0, fmt.Errorf("long-lived token exchange returned empty token")
+	}
+
+	utils.Debugf("threads long-lived token exchange request success expires_in=%d", tokenResp.ExpiresIn)
+	return tokenResp.AccessToken, tokenResp.ExpiresIn, nil
+}
+
+// getThreadsIdentity fetches the Threads user ID used by the publishing API,
+// along with the account's username and display name.
+func (h *OAuthHandler) getThreadsIdentity(ctx context.Context, accessToken string) (accountIdentity, error) {
+	cfg := config.Load()
+
+	meURL := fmt.Sprintf("%s/%s/me?fields=id,username,name&access_token=%s",
+		cfg.ThreadsGraphBase, cfg.ThreadsVersion, url.QueryEscape(accessToken))
+
+	resp, err := h.get(ctx, meURL)
+	if err != nil {
+		return accountIdentity{}, fmt.Errorf("failed to fetch Threads identity: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return accountIdentity{}, fmt.Errorf("failed to read identity response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return accountIdentity{}, fmt.Errorf("Threads identity API error: %s", string(body))
+	}
+
+	var meResp struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &meResp); err != nil {
+		return accountIdentity{}, fmt.Errorf("failed to parse identity response: %w", err)
+	}
+
+	if meResp.ID == "" {
+		return accountIdentity{}, fmt.Errorf("Threads identity API returned empty user ID")
+	}
+
+	return accountIdentity{ID: meResp.ID, Username: meResp.Username, DisplayName: meResp.Name}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -19,8 +20,6 @@ import (
 	"github.com/google/uuid"
 )
 
-var tiktokHTTPClient = &http.Client{Timeout: 15 * time.Second}
-
 // InitiateTikTokOAuth starts the TikTok OAuth flow.
 // TikTok uses Login Kit v2 with PKCE (code_verifier / code_challenge).
 func (h *OAuthHandler) InitiateTikTokOAuth(w http.ResponseWriter, r *http.Request) {
@@ -91,8 +90,8 @@ func (h *OAuthHandler) HandleTikTokCallback(w http.ResponseWriter, r *http.Reque
 	if errorParam != "" {
 		errorDesc := r.URL.Query().Get("error_description")
 		utils.Warnf("tiktok callback oauth error error=%s description=%s", errorParam, errorDesc)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
-			errorParam, url.QueryEscape(errorDesc)), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
+			url.QueryEscape(errorParam), url.QueryEscape(errorDesc)))
 		return
 	}
 
@@ -128,15 +127,22 @@ func (h *OAuthHandler) HandleTikTokCallback(w http.ResponseWriter, r *http.Reque
 	codeVerifier := h.oauthStateService.GetCodeVerifier(state)
 
 	// Exchange authorization code for access token
-	accessToken, refreshToken, expiresIn, openID, err := h.exchangeCodeForTikTokToken(code, codeVerifier)
+	accessToken, refreshToken, expiresIn, openID, scopes, err := h.exchangeCodeForTikTokToken(r.Context(), code, codeVerifier)
 	if err != nil {
 		utils.Errorf("tiktok token exchange failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
 	utils.Infof("tiktok token exchange success user_id=%s open_id=%s expires_in=%d", userID, openID, expiresIn)
 
+	// Fetch the display name for the connections screen (user.info.basic scope)
+	displayName, err := h.getTikTokDisplayName(r.Context(), accessToken)
+	if err != nil {
+		utils.Warnf("tiktok identity fetch failed (non-fatal) user_id=%s err=%v", userID, err)
+		displayName = ""
+	}
+
 	var expiresAt *time.Time
 	if expiresIn > 0 {
 		expTime := time.Now().Add(time.Duration(expiresIn) * time.Second)
@@ -144,38 +150,40 @@ func (h *OAuthHandler) HandleTikTokCallback(w http.ResponseWriter, r *http.Reque
 	}
 
 	cred := &models.PlatformCredentials{
-		ID:             uuid.New().String(),
-		UserID:         userID,
-		Platform:       models.TikTok,
-		AccessToken:    accessToken,
-		RefreshToken:   refreshToken,
-		TokenType:      "Bearer",
-		ExpiresAt:      expiresAt,
-		PlatformUserID: openID,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:                  uuid.New().String(),
+		UserID:              userID,
+		Platform:            models.TikTok,
+		AccessToken:         accessToken,
+		RefreshToken:        refreshToken,
+		TokenType:           "Bearer",
+		ExpiresAt:           expiresAt,
+		GrantedScopes:       scopes,
+		PlatformUserID:      openID,
+		PlatformDisplayName: displayName,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
 	}
 
 	if err := h.db.SaveCredentials(cred); err != nil {
 		utils.Errorf("tiktok save credentials failed user_id=%s open_id=%s err=%v", userID, openID, err)
-		http.Redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials",
-			http.StatusFound)
+		h.redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials")
 		return
 	}
 
 	utils.Infof("tiktok credentials saved user_id=%s platform=%s open_id=%s", userID, models.TikTok, openID)
+	h.recordConnected(r, userID, models.TikTok)
 	utils.Infof("tiktok callback completed successfully user_id=%s", userID)
 
-	http.Redirect(w, r, "/oauth/success?platform=tiktok", http.StatusFound)
+	h.redirect(w, r, "/oauth/success?platform=tiktok")
 }
 
 // exchangeCodeForTikTokToken exchanges the auth code for an access token via TikTok's token endpoint.
-// Returns: accessToken, refreshToken, expiresIn, openID, error
-func (h *OAuthHandler) exchangeCodeForTikTokToken(code, codeVerifier string) (string, string, int, string, error) {
+// Returns: accessToken, refreshToken, expiresIn, openID, granted scopes, error
+func (h *OAuthHandler) exchangeCodeForTikTokToken(ctx context.Context, code, codeVerifier string) (string, string, int, string, []string, error) {
 	cfg := config.Load()
 	utils.Debugf("tiktok token exchange request start")
 
-	tokenURL := "https://open.tiktokapis.com/v2/oauth/token/"
+	tokenURL := cfg.TikTokAPIBase + "/v2/oauth/token/"
 
 	form := url.Values{}
 	form.Set("client_key", cfg.TikTokClientKey)
@@ -187,28 +195,28 @@ func (h *OAuthHandler) exchangeCodeForTikTokToken(code, codeVerifier string) (st
 		form.Set("code_verifier", codeVerifier)
 	}
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
 	if err != nil {
-		return "", "", 0, "", err
+		return "", "", 0, "", nil, err
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := tiktokHTTPClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
 		utils.Errorf("tiktok token exchange http request failed err=%v", err)
-		return "", "", 0, "", err
+		return "", "", 0, "", nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		utils.Errorf("tiktok token exchange read body failed err=%v", err)
-		return "", "", 0, "", err
+		return "", "", 0, "", nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		utils.Errorf("tiktok token exchange api status=%d body=%s", resp.StatusCode, string(body))
-		return "", "", 0, "", fmt.Errorf("TikTok token API error (status %d): %s", resp.StatusCode, string(body))
+		return "", "", 0, "", nil, fmt.Errorf("TikTok token API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var tokenResp struct {
@@ -222,16 +230,57 @@ func (h *OAuthHandler) exchangeCodeForTikTokToken(code, codeVerifier string) (st
 
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
 		utils.Errorf("tiktok token exchange parse response failed err=%v", err)
-		return "", "", 0, "", err
+		return "", "", 0, "", nil, err
 	}
 
 	if tokenResp.AccessToken == "" {
 		utils.Errorf("tiktok token exchange returned empty access token")
-		return "", "", 0, "", fmt.Errorf("TikTok token API returned empty access token")
+		return "", "", 0, "", nil, fmt.Errorf("TikTok token API returned empty access token")
 	}
 
 	utils.Debugf("tiktok token exchange request success expires_in=%d open_id=%s", tokenResp.ExpiresIn, tokenResp.OpenID)
-	return tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn, tokenResp.OpenID, nil
+	return tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn, tokenResp.OpenID, parseScopes(tokenResp.Scope), nil
+}
+
+// getTikTokDisplayName fetches the connected account's display name via the user info endpoint.
+func (h *OAuthHandler) getTikTokDisplayName(ctx context.Context, accessToken string) (string, error) {
+	utils.Debugf("tiktok identity fetch start")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Load().TikTokAPIBase+"/v2/user/info/?fields=open_id,display_name", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create user info request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("tiktok user info request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read user info response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("TikTok user info API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var userResp struct {
+		Data struct {
+			User struct {
+				OpenID      string `json:"open_id"`
+				DisplayName string `json:"display_name"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &userResp); err != nil {
+		return "", fmt.Errorf("failed to parse user info response: %w", err)
+	}
+
+	utils.Debugf("tiktok identity fetch success open_id=%s", userResp.Data.User.OpenID)
+	return userResp.Data.User.DisplayName, nil
 }
 
 // generateCodeVerifier generates a random code verifier for PKCE (43-128 chars).
@@ -4,6 +4,7 @@ import (
 	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,8 +16,6 @@ import (
 	"github.com/google/uuid"
 )
 
-var twitterHTTPClient = &http.Client{Timeout: 15 * time.Second}
-
 // InitiateTwitterOAuth starts the Twitter/X OAuth 2.0 flow with PKCE.
 func (h *OAuthHandler) InitiateTwitterOAuth(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
@@ -85,8 +84,8 @@ func (h *OAuthHandler) HandleTwitterCallback(w http.ResponseWriter, r *http.Requ
 	if errorParam != "" {
 		errorDesc := r.URL.Query().Get("error_description")
 		utils.Warnf("twitter callback oauth error error=%s description=%s", errorParam, errorDesc)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
-			errorParam, url.QueryEscape(errorDesc)), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
+			url.QueryEscape(errorParam), url.QueryEscape(errorDesc)))
 		return
 	}
 
@@ -122,13 +121,14 @@ func (h *OAuthHandler) HandleTwitterCallback(w http.ResponseWriter, r *http.Requ
 	codeVerifier := h.oauthStateService.GetCodeVerifier(state)
 
 	// Exchange authorization code for access token
-	accessToken, refreshToken, expiresIn, twitterUserID, err := h.exchangeCodeForTwitterToken(code, codeVerifier)
+	accessToken, refreshToken, expiresIn, identity, scopes, err := h.exchangeCodeForTwitterToken(r.Context(), code, codeVerifier)
 	if err != nil {
 		utils.Errorf("twitter token exchange failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
+	twitterUserID := identity.ID
 	utils.Infof("twitter token exchange success user_id=%s twitter_user_id=%s expires_in=%d", userID, twitterUserID, expiresIn)
 
 	var expiresAt *time.Time
@@ -138,38 +138,41 @@ func (h *OAuthHandler) HandleTwitterCallback(w http.ResponseWriter, r *http.Requ
 	}
 
 	cred := &models.PlatformCredentials{
-		ID:             uuid.New().String(),
-		UserID:         userID,
-		Platform:       models.Twitter,
-		AccessToken:    accessToken,
-		RefreshToken:   refreshToken,
-		TokenType:      "Bearer",
-		ExpiresAt:      expiresAt,
-		PlatformUserID: twitterUserID,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:                  uuid.New().String(),
+		UserID:              userID,
+		Platform:            models.Twitter,
+		AccessToken:         accessToken,
+		RefreshToken:        refreshToken,
+		TokenType:           "Bearer",
+		ExpiresAt:           expiresAt,
+		GrantedScopes:       scopes,
+		PlatformUserID:      twitterUserID,
+		PlatformUsername:    identity.Username,
+		PlatformDisplayName: identity.DisplayName,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
 	}
 
 	if err := h.db.SaveCredentials(cred); err != nil {
 		utils.Errorf("twitter save credentials failed user_id=%s twitter_user_id=%s err=%v", userID, twitterUserID, err)
-		http.Redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials",
-			http.StatusFound)
+		h.redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials")
 		return
 	}
 
 	utils.Infof("twitter credentials saved user_id=%s platform=%s twitter_user_id=%s", userID, models.Twitter, twitterUserID)
+	h.recordConnected(r, userID, models.Twitter)
 	utils.Infof("twitter callback completed successfully user_id=%s", userID)
 
-	http.Redirect(w, r, "/oauth/success?platform=twitter", http.StatusFound)
+	h.redirect(w, r, "/oauth/success?platform=twitter")
 }
 
 // exchangeCodeForTwitterToken exchanges the authorization code for an access token.
-// Returns: accessToken, refreshToken, expiresIn, twitterUserID, error
-func (h *OAuthHandler) exchangeCodeForTwitterToken(code, codeVerifier string) (string, string, int, string, error) {
+// Returns: accessToken, refreshToken, expiresIn, account identity, granted scopes, error
+func (h *OAuthHandler) exchangeCodeForTwitterToken(ctx context.Context, code, codeVerifier string) (string, string, int, accountIdentity, []string, error) {
 	cfg := config.Load()
 	utils.Debugf("twitter token exchange request start")
 
-	tokenURL := "https://api.x.com/2/oauth2/token"
+	tokenURL := cfg.TwitterAPIBase + "/2/oauth2/token"
 
 	form := url.Values{}
 	form.Set("grant_type", "authorization_code")
@@ -180,9 +183,9 @@ func (h *OAuthHandler) exchangeCodeForTwitterToken(code, codeVerifier string) (s
 		form.Set("code_verifier", codeVerifier)
 	}
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
 	if err != nil {
-		return "", "", 0, "", fmt.Errorf("failed to create token request: %w", err)
+		return "", "", 0, accountIdentity{}, nil, fmt.Errorf("failed to create token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	// Twitter requires Basic auth with client_id:client_secret for confidential clients
@@ -190,19 +193,19 @@ func (h *OAuthHandler) exchangeCodeForTwitterToken(code, codeVerifier string) (s
 		req.SetBasicAuth(cfg.TwitterClientID, cfg.TwitterClientSecret)
 	}
 
-	resp, err := twitterHTTPClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
-		return "", "", 0, "", fmt.Errorf("twitter token exchange request failed: %w", err)
+		return "", "", 0, accountIdentity{}, nil, fmt.Errorf("twitter token exchange request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", "", 0, "", fmt.Errorf("failed to read token response body: %w", err)
+		return "", "", 0, accountIdentity{}, nil, fmt.Errorf("failed to read token response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", 0, "", fmt.Errorf("twitter token exchange failed (status %d): %s", resp.StatusCode, string(body))
+		return "", "", 0, accountIdentity{}, nil, fmt.Errorf("twitter token exchange failed (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var tokenResp struct {
@@ -213,49 +216,50 @@ func (h *OAuthHandler) exchangeCodeForTwitterToken(code, codeVerifier string) (s
 		Scope        string `json:"scope"`
 	}
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return "", "", 0, "", fmt.Errorf("failed to parse token response: %w", err)
+		return "", "", 0, accountIdentity{}, nil, fmt.Errorf("failed to parse token response: %w", err)
 	}
 
 	if tokenResp.AccessToken == "" {
-		return "", "", 0, "", fmt.Errorf("twitter returned empty access token")
+		return "", "", 0, accountIdentity{}, nil, fmt.Errorf("twitter returned empty access token")
 	}
 
 	utils.Debugf("twitter token exchange success expires_in=%d", tokenResp.ExpiresIn)
 
 	// Fetch the authenticated user's identity
-	twitterUserID, err := h.getTwitterUserIdentity(tokenResp.AccessToken)
+	identity, err := h.getTwitterUserIdentity(ctx, tokenResp.AccessToken)
 	if err != nil {
 		utils.Warnf("twitter identity fetch failed (non-fatal): %v", err)
 		// Don't fail the whole flow; we still have a valid token
-		twitterUserID = ""
+		identity = accountIdentity{}
 	}
 
-	return tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn, twitterUserID, nil
+	return tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn, identity, parseScopes(tokenResp.Scope), nil
 }
 
-// getTwitterUserIdentity fetches the authenticated user's Twitter/X ID via GET /2/users/me.
-func (h *OAuthHandler) getTwitterUserIdentity(accessToken string) (string, error) {
+// getTwitterUserIdentity fetches the authenticated user's Twitter/X ID, @username and
+// display name via GET /2/users/me.
+func (h *OAuthHandler) getTwitterUserIdentity(ctx context.Context, accessToken string) (accountIdentity, error) {
 	utils.Debugf("twitter identity fetch start")
 
-	req, err := http.NewRequest("GET", "https://api.x.com/2/users/me", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", config.Load().TwitterAPIBase+"/2/users/me", nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create identity request: %w", err)
+		return accountIdentity{}, fmt.Errorf("failed to create identity request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
-	resp, err := twitterHTTPClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("twitter identity request failed: %w", err)
+		return accountIdentity{}, fmt.Errorf("twitter identity request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read identity response: %w", err)
+		return accountIdentity{}, fmt.Errorf("failed to read identity response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("twitter identity API error (status %d): %s", resp.StatusCode, string(body))
+		return accountIdentity{}, fmt.Errorf("twitter identity API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var userResp struct {
@@ -266,13 +270,17 @@ func (h *OAuthHandler) getTwitterUserIdentity(accessToken string) (string, error
 		} `json:"data"`
 	}
 	if err := json.Unmarshal(body, &userResp); err != nil {
-		return "", fmt.Errorf("failed to parse identity response: %w", err)
+		return accountIdentity{}, fmt.Errorf("failed to parse identity response: %w", err)
 	}
 
 	if userResp.Data.ID == "" {
-		return "", fmt.Errorf("twitter returned empty user ID")
+		return accountIdentity{}, fmt.Errorf("twitter returned empty user ID")
 	}
 
 	utils.Debugf("twitter identity fetch success twitter_user_id=%s username=%s", userResp.Data.ID, userResp.Data.Username)
-	return userResp.Data.ID, nil
+	return accountIdentity{
+		ID:          userResp.Data.ID,
+		Username:    userResp.Data.Username,
+		DisplayName: userResp.Data.Name,
+	}, nil
 }
@@ -4,6 +4,7 @@ import (
 	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,8 +16,6 @@ import (
 	"github.com/google/uuid"
 )
 
-var youtubeHTTPClient = &http.Client{Timeout: 15 * time.Second}
-
 // InitiateYouTubeOAuth starts the Google/YouTube OAuth 2.0 flow.
 func (h *OAuthHandler) InitiateYouTubeOAuth(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
@@ -78,8 +77,8 @@ func (h *OAuthHandler) HandleYouTubeCallback(w http.ResponseWriter, r *http.Requ
 	if errorParam != "" {
 		errorDesc := r.URL.Query().Get("error_description")
 		utils.Warnf("youtube callback oauth error error=%s description=%s", errorParam, errorDesc)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
-			errorParam, url.QueryEscape(errorDesc)), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=%s&description=%s",
+			url.QueryEscape(errorParam), url.QueryEscape(errorDesc)))
 		return
 	}
 
@@ -112,21 +111,23 @@ func (h *OAuthHandler) HandleYouTubeCallback(w http.ResponseWriter, r *http.Requ
 	userID := oauthState.UserID
 
 	// Exchange authorization code for access + refresh token
-	accessToken, refreshToken, expiresIn, err := h.exchangeCodeForYouTubeToken(code)
+	accessToken, refreshToken, expiresIn, scopes, err := h.exchangeCodeForYouTubeToken(r.Context(), code)
 	if err != nil {
 		utils.Errorf("youtube token exchange failed user_id=%s err=%v", userID, err)
-		http.Redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
-			url.QueryEscape(err.Error())), http.StatusFound)
+		h.redirect(w, r, fmt.Sprintf("/oauth/error?error=token_exchange&description=%s",
+			url.QueryEscape(err.Error())))
 		return
 	}
 	utils.Infof("youtube token exchange success user_id=%s expires_in=%d", userID, expiresIn)
 
 	// Fetch the YouTube channel identity
-	youtubeChannelID, err := h.getYouTubeChannelIdentity(accessToken)
+	identity, err := h.getYouTubeChannelIdentity(r.Context(), accessToken)
 	if err != nil {
 		utils.Warnf("youtube identity fetch failed (non-fatal) user_id=%s err=%v", userID, err)
-		youtubeChannelID = ""
-	} else {
+		identity = accountIdentity{}
+	}
+	youtubeChannelID := identity.ID
+	if youtubeChannelID != "" {
 		utils.Infof("youtube identity fetch success user_id=%s channel_id=%s", userID, youtubeChannelID)
 	}
 
@@ -137,38 +138,41 @@ func (h *OAuthHandler) HandleYouTubeCallback(w http.ResponseWriter, r *http.Requ
 	}
 
 	cred := &models.PlatformCredentials{
-		ID:             uuid.New().String(),
-		UserID:         userID,
-		Platform:       models.YouTube,
-		AccessToken:    accessToken,
-		RefreshToken:   refreshToken,
-		TokenType:      "Bearer",
-		ExpiresAt:      expiresAt,
-		PlatformUserID: youtubeChannelID,
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
+		ID:                  uuid.New().String(),
+		UserID:              userID,
+		Platform:            models.YouTube,
+		AccessToken:         accessToken,
+		RefreshToken:        refreshToken,
+		TokenType:           "Bearer",
+		ExpiresAt:           expiresAt,
+		GrantedScopes:       scopes,
+		PlatformUserID:      youtubeChannelID,
+		PlatformUsername:    identity.Username,
+		PlatformDisplayName: identity.DisplayName,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
 	}
 
 	if err := h.db.SaveCredentials(cred); err != nil {
 		utils.Errorf("youtube save credentials failed user_id=%s channel_id=%s err=%v", userID, youtubeChannelID, err)
-		http.Redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials",
-			http.StatusFound)
+		h.redirect(w, r, "/oauth/error?error=save_failed&description=Failed+to+save+credentials")
 		return
 	}
 
 	utils.Infof("youtube credentials saved user_id=%s platform=%s channel_id=%s", userID, models.YouTube, youtubeChannelID)
+	h.recordConnected(r, userID, models.YouTube)
 	utils.Infof("youtube callback completed successfully user_id=%s", userID)
 
-	http.Redirect(w, r, "/oauth/success?platform=youtube", http.StatusFound)
+	h.redirect(w, r, "/oauth/success?platform=youtube")
 }
 
 // exchangeCodeForYouTubeToken exchanges the authorization code for tokens via Google's token endpoint.
-// Returns: accessToken, refreshToken, expiresIn, error
-func (h *OAuthHandler) exchangeCodeForYouTubeToken(code string) (string, string, int, error) {
+// Returns: accessToken, refreshToken, expiresIn, granted scopes, error
+func (h *OAuthHandler) exchangeCodeForYouTubeToken(ctx context.Context, code string) (string, string, int, []string, error) {
 	cfg := config.Load()
 	utils.Debugf("youtube token exchange request start")
 
-	tokenURL := "https://oauth2.googleapis.com/token"
+	tokenURL := cfg.GoogleOAuthBase + "/token"
 
 	form := url.Values{}
 	form.Set("code", code)
@@ -177,25 +181,25 @@ func (h *OAuthHandler) exchangeCodeForYouTubeToken(code string) (string, string,
 	form.Set("redirect_uri", cfg.YouTubeRedirectURI)
 	form.Set("grant_type", "authorization_code")
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
 	if err != nil {
-		return "", "", 0, fmt.Errorf("failed to create token request: %w", err)
+		return "", "", 0, nil, fmt.Errorf("failed to create token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := youtubeHTTPClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
-		return "", "", 0, fmt.Errorf("youtube token exchange request failed: %w", err)
+		return "", "", 0, nil, fmt.Errorf("youtube token exchange request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", "", 0, fmt.Errorf("failed to read token response body: %w", err)
+		return "", "", 0, nil, fmt.Errorf("failed to read token response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", 0, fmt.Errorf("youtube token exchange failed (status %d): %s", resp.StatusCode, string(body))
+		return "", "", 0, nil, fmt.Errorf("youtube token exchange failed (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var tokenResp struct {
@@ -206,58 +210,67 @@ func (h *OAuthHandler) exchangeCodeForYouTubeToken(code string) (string, string,
 		Scope        string `json:"scope"`
 	}
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return "", "", 0, fmt.Errorf("failed to parse token response: %w", err)
+		return "", "", 0, nil, fmt.Errorf("failed to parse token response: %w", err)
 	}
 
 	if tokenResp.AccessToken == "" {
-		return "", "", 0, fmt.Errorf("google returned empty access token")
+		return "", "", 0, nil, fmt.Errorf("google returned empty access token")
 	}
 
 	utils.Debugf("youtube token exchange success expires_in=%d has_refresh=%t", tokenResp.ExpiresIn, tokenResp.RefreshToken != "")
-	return tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn, nil
+	return tokenResp.AccessToken, tokenResp.RefreshToken, tokenResp.ExpiresIn, parseScopes(tokenResp.Scope), nil
 }
 
-// getYouTubeChannelIdentity fetches the authenticated user's YouTube channel ID.
-func (h *OAuthHandler) getYouTubeChannelIdentity(accessToken string) (string, error) {
+// getYouTubeChannelIdentity fetches the authenticated user's YouTube channel ID,
+// title and handle (customUrl, e.g. "@channel").
+func (h *OAuthHandler) getYouTubeChannelIdentity(ctx context.Context, accessToken string) (accountIdentity, error) {
 	utils.Debugf("youtube identity fetch start")
 
-	endpoint := "https://www.googleapis.com/youtube/v3/channels?part=id&mine=true"
+	endpoint := config.Load().YouTubeAPIBase + "/youtube/v3/channels?part=id,snippet&mine=true"
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create identity request: %w", err)
+		return accountIdentity{}, fmt.Errorf("failed to create identity request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
-	resp, err := youtubeHTTPClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("youtube identity request failed: %w", err)
+		return accountIdentity{}, fmt.Errorf("youtube identity request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read identity response: %w", err)
+		return accountIdentity{}, fmt.Errorf("failed to read identity response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("youtube channels API error (status %d): %s", resp.StatusCode, string(body))
+		return accountIdentity{}, fmt.Errorf("youtube channels API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var channelResp struct {
 		Items []struct {
-			ID string `json:"id"`
+			ID      string `json:"id"`
+			Snippet struct {
+				Title     string `json:"title"`
+				CustomURL string `json:"customUrl"`
+			} `json:"snippet"`
 		} `json:"items"`
 	}
 	if err := json.Unmarshal(body, &channelResp); err != nil {
-		return "", fmt.Errorf("failed to parse channels response: %w", err)
+		return accountIdentity{}, fmt.Errorf("failed to parse channels response: %w", err)
 	}
 
 	if len(channelResp.Items) == 0 {
-		return "", fmt.Errorf("no YouTube channel found for this account")
+		return accountIdentity{}, fmt.Errorf("no YouTube channel found for this account")
 	}
 
-	channelID := channelResp.Items[0].ID
-	utils.Debugf("youtube identity fetch success channel_id=%s", channelID)
-	return channelID, nil
+	channel := channelResp.Items[0]
+	utils.Debugf("youtube identity fetch success channel_id=%s custom_url=%s", channel.ID, channel.Snippet.CustomURL)
+	return accountIdentity{
+		ID:          channel.ID,
+		Username:    channel.Snippet.CustomURL,
+		DisplayName: channel.Snippet.Title,
+	}, nil
 }
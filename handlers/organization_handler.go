@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// CreateOrganization creates an organization owned by the authenticated user.
+func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	org := &models.Organization{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		OwnerID:   userID,
+		Role:      models.OrgRoleOwner,
+		CreatedAt: time.Now(),
+	}
+	if err := h.db.CreateOrganization(org); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error creating organization")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, org)
+}
+
+// GetOrganizations lists the organizations the authenticated user belongs to.
+func (h *Handler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	orgs, err := h.db.GetUserOrganizations(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching organizations")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, orgs)
+}
+
+// GetOrganizationMembers lists the members of an organization. Only members can see it.
+func (h *Handler) GetOrganizationMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	orgID := mux.Vars(r)["id"]
+
+	if _, ok := h.requireOrgRole(w, orgID, userID, false); !ok {
+		return
+	}
+
+	members, err := h.db.GetOrganizationMembers(orgID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching members")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, members)
+}
+
+// AddOrganizationMember adds a registered user (by email) to an organization.
+// Only the owner can add members.
+func (h *Handler) AddOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	orgID := mux.Vars(r)["id"]
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Email is required")
+		return
+	}
+
+	if _, ok := h.requireOrgRole(w, orgID, userID, true); !ok {
+		return
+	}
+
+	member, err := h.db.GetUserByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found")
+		} else {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching user")
+		}
+		return
+	}
+
+	if err := h.db.AddOrganizationMember(orgID, member.ID, models.OrgRoleMember); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error adding member")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
+		"message": fmt.Sprintf("%s added to organization", member.Email),
+	})
+}
+
+// RemoveOrganizationMember removes a member. The owner can remove anyone but
+// themselves; other members can only remove themselves (leave).
+func (h *Handler) RemoveOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	vars := mux.Vars(r)
+	orgID, memberID := vars["id"], vars["userId"]
+
+	role, ok := h.requireOrgRole(w, orgID, userID, false)
+	if !ok {
+		return
+	}
+	if role != models.OrgRoleOwner && memberID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Only the organization owner can remove other members")
+		return
+	}
+	if role == models.OrgRoleOwner && memberID == userID {
+		utils.RespondWithError(w, http.StatusBadRequest, "The organization owner cannot leave the organization")
+		return
+	}
+
+	removed, err := h.db.RemoveOrganizationMember(orgID, memberID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error removing member")
+		return
+	}
+	if !removed {
+		utils.RespondWithError(w, http.StatusNotFound, "User is not a member of this organization")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{
+		"message": "Member removed successfully",
+	})
+}
+
+// ShareCredentials shares one of the user's connected platforms with an
+// organization they belong to, so every member can publish with it. An empty
+// organization_id stops sharing.
+func (h *Handler) ShareCredentials(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	var req struct {
+		Platform       models.Platform `json:"platform"`
+		OrganizationID string          `json:"organization_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Platform == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "Platform is required")
+		return
+	}
+
+	if req.OrganizationID != "" {
+		if _, ok := h.requireOrgRole(w, req.OrganizationID, userID, false); !ok {
+			return
+		}
+	}
+
+	found, err := h.db.ShareCredentials(userID, req.Platform, req.OrganizationID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error sharing credentials")
+		return
+	}
+	if !found {
+		utils.RespondWithError(w, http.StatusNotFound, "Platform was not connected")
+		return
+	}
+
+	message := fmt.Sprintf("%s shared with organization", req.Platform)
+	if req.OrganizationID == "" {
+		message = fmt.Sprintf("%s is no longer shared", req.Platform)
+	}
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+// requireOrgRole checks that userID is a member of orgID (and its owner when
+// ownerOnly is set). On failure it writes the error response and returns false.
+func (h *Handler) requireOrgRole(w http.ResponseWriter, orgID, userID string, ownerOnly bool) (string, bool) {
+	role, err := h.db.GetMembershipRole(orgID, userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching organization")
+		return "", false
+	}
+	if role == "" {
+		utils.RespondWithError(w, http.StatusNotFound, "Organization not found")
+		return "", false
+	}
+	if ownerOnly && role != models.OrgRoleOwner {
+		utils.RespondWithError(w, http.StatusForbidden, "Only the organization owner can do this")
+		return "", false
+	}
+	return role, true
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
+	"SocialMediaAPI/publishers"
+	"SocialMediaAPI/services"
 	"SocialMediaAPI/utils"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -12,27 +16,219 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// maxSubtitlesSize caps the SRT payload accepted with a post.
+const maxSubtitlesSize = 512 << 10
+
 func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	lang := utils.RequestLanguage(r)
+
 	userID, ok := r.Context().Value("userID").(string)
 	if !ok || userID == "" {
-		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		utils.RespondWithError(w, http.StatusUnauthorized, utils.Localize(lang, "auth.user_not_in_context"))
+		return
+	}
+
+	var req struct {
+		models.Post
+		TemplateID string            `json:"template_id"`
+		Variables  map[string]string `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "request.invalid_payload"))
+		return
+	}
+	post := req.Post
+
+	// A template supplies the content, rendered with the request's variables
+	if req.TemplateID != "" {
+		if post.Content != "" {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.template_and_content"))
+			return
+		}
+		tmpl, err := h.db.GetTemplate(req.TemplateID)
+		if err != nil || tmpl.UserID != userID {
+			utils.RespondWithError(w, http.StatusNotFound, utils.Localize(lang, "post.template_not_found"))
+			return
+		}
+		if missing := utils.MissingTemplateVariables(tmpl.Content, req.Variables); len(missing) > 0 {
+			utils.RespondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error":             utils.Localize(lang, "post.template_missing_vars", strings.Join(missing, ", ")),
+				"missing_variables": missing,
+			})
+			return
+		}
+		content, err := utils.RenderTemplate(tmpl.Content, req.Variables)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		post.Content = content
+	}
+
+	if !h.validatePost(w, lang, userID, &post) {
+		return
+	}
+
+	post.ID = uuid.New().String()
+	post.UserID = userID
+	post.CreatedAt = time.Now()
+	post.UpdatedAt = time.Now()
+
+	if post.ScheduledFor != nil && post.ScheduledFor.After(time.Now()) {
+		post.Status = models.StatusScheduled
+		if err := h.db.CreatePost(&post); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, utils.Localize(lang, "post.create_scheduled_failed"))
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusCreated, post)
+	} else if r.URL.Query().Get("async") == "true" {
+		// Published by the queue workers from its publish jobs
+		post.Status = models.StatusPublishing
+		if err := h.db.CreatePost(&post); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, utils.Localize(lang, "post.create_failed"))
+			return
+		}
+		if err := h.queue.Enqueue(&post); err != nil {
+			utils.Errorf("failed to queue post post_id=%s err=%v", post.ID, err)
+			post.Status = models.StatusDraft
+			if err := h.db.UpdatePost(&post); err != nil {
+				utils.Errorf("failed to save unqueued post as draft post_id=%s err=%v", post.ID, err)
+			}
+			utils.RespondWithError(w, http.StatusInternalServerError, utils.Localize(lang, "post.queue_failed"))
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusAccepted, post)
+	} else {
+		post.Status = models.StatusDraft
+		if err := h.db.CreatePost(&post); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, utils.Localize(lang, "post.create_failed"))
+			return
+		}
+
+		results, err := h.publisher.PublishPost(&post, services.TriggerInteractive)
+		if errors.Is(err, services.ErrPublishBusy) {
+			// The post stays a draft that can be published later
+			w.Header().Set("Retry-After", publishBusyRetryAfter)
+			utils.RespondWithJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"error":   utils.Localize(lang, "publish.busy"),
+				"post_id": post.ID,
+			})
+			return
+		}
+		failedPlatforms := make([]string, 0)
+		for _, result := range results {
+			if !result.Success {
+				failedPlatforms = append(failedPlatforms, string(result.Platform))
+			}
+		}
+
+		response := models.PublishResponse{
+			PostID:  post.ID,
+			Results: results,
+		}
+
+		if len(failedPlatforms) > 0 {
+			utils.RespondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+				"error":             utils.Localize(lang, "publish.failed"),
+				"failed_platforms":  failedPlatforms,
+				"publish_response": response,
+				"message":           utils.Localize(lang, "publish.failed_hint"),
+				"failed_summary":    utils.Localize(lang, "publish.failed_summary", strings.Join(failedPlatforms, ", ")),
+			})
+			return
+		}
+
+		utils.RespondWithJSON(w, http.StatusCreated, response)
+	}
+}
+
+// UpdatePost edits a draft or scheduled post. Fields missing from the body
+// keep their current values; the result is validated like a new post.
+// Setting scheduled_for in the future (re)schedules the post, clearing it or
+// moving it into the past turns it back into a draft.
+func (h *Handler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	lang := utils.RequestLanguage(r)
+
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, utils.Localize(lang, "auth.user_not_in_context"))
+		return
+	}
+
+	existing, err := h.db.GetPost(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, utils.Localize(lang, "post.not_found"))
+		return
+	}
+	if existing.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, utils.Localize(lang, "post.access_denied"))
+		return
+	}
+	if existing.Status != models.StatusDraft && existing.Status != models.StatusScheduled {
+		utils.RespondWithError(w, http.StatusConflict, utils.Localize(lang, "post.not_editable"))
 		return
 	}
 
-	var post models.Post
+	// Decoding over a copy of the stored post leaves omitted fields as they are
+	post := *existing
 	if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "request.invalid_payload"))
+		return
+	}
+	post.ID = existing.ID
+	post.UserID = existing.UserID
+	post.CreatedAt = existing.CreatedAt
+	post.PublishedAt = existing.PublishedAt
+	post.RetryCount = existing.RetryCount
+	post.NextRetryAt = existing.NextRetryAt
+
+	if !h.validatePost(w, lang, userID, &post) {
 		return
 	}
 
-	if post.Content == "" {
-		utils.RespondWithError(w, http.StatusBadRequest, "Content is required")
+	if post.ScheduledFor != nil && post.ScheduledFor.After(time.Now()) {
+		post.Status = models.StatusScheduled
+	} else {
+		post.Status = models.StatusDraft
+	}
+	post.UpdatedAt = time.Now()
+
+	updated, err := h.db.UpdateEditablePost(&post)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, utils.Localize(lang, "post.update_failed"))
+		return
+	}
+	if !updated {
+		// Picked up for publishing since it was loaded
+		utils.RespondWithError(w, http.StatusConflict, utils.Localize(lang, "post.not_editable"))
 		return
 	}
 
+	utils.RespondWithJSON(w, http.StatusOK, post)
+}
+
+// hasPlatform reports whether platforms contains platform.
+func hasPlatform(platforms []models.Platform, platform models.Platform) bool {
+	for _, p := range platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePost checks a new or edited post and resolves its media and
+// thumbnail, filling in defaults. It writes the error response and returns
+// false when the post is rejected.
+func (h *Handler) validatePost(w http.ResponseWriter, lang, userID string, post *models.Post) bool {
+	if post.Content == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.content_required"))
+		return false
+	}
+
 	if len(post.Platforms) == 0 {
-		utils.RespondWithError(w, http.StatusBadRequest, "At least one platform is required")
-		return
+		utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.platform_required"))
+		return false
 	}
 
 	// Default post_type to "normal" if not specified
@@ -43,8 +239,8 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	// Validate post_type value
 	if post.PostType != models.PostTypeNormal && post.PostType != models.PostTypeShort && post.PostType != models.PostTypeStory {
 		utils.RespondWithError(w, http.StatusBadRequest,
-			"Invalid post_type. Must be 'normal', 'short', or 'story'")
-		return
+			utils.Localize(lang, "post.invalid_post_type"))
+		return false
 	}
 
 	// Default privacy_level to "public" if not specified
@@ -61,38 +257,53 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	}
 	if !validPrivacy[post.PrivacyLevel] {
 		utils.RespondWithError(w, http.StatusBadRequest,
-			"Invalid privacy_level. Must be 'public', 'followers', 'friends', or 'private'")
-		return
+			utils.Localize(lang, "post.invalid_privacy_level"))
+		return false
 	}
 
-	// Enforce platform restrictions based on post_type
-	if post.PostType == models.PostTypeNormal {
-		// Normal posts cannot be published to TikTok
-		for _, p := range post.Platforms {
-			if p == models.TikTok {
-				utils.RespondWithError(w, http.StatusBadRequest,
-					"TikTok only supports short-form video posts. Set post_type to 'short' to publish to TikTok")
-				return
+	// Enforce platform restrictions based on post_type (POST_TYPE_*_PLATFORMS)
+	for _, p := range post.Platforms {
+		if !publishers.SupportsPostType(p, post.PostType) {
+			allowed := publishers.PostTypePlatforms(post.PostType)
+			names := make([]string, len(allowed))
+			for i, a := range allowed {
+				names[i] = string(a)
 			}
+			utils.RespondWithError(w, http.StatusBadRequest,
+				utils.Localize(lang, "post.platform_not_for_type", p, post.PostType, strings.Join(names, ", ")))
+			return false
 		}
 	}
 
-	if post.PostType == models.PostTypeShort {
-		// Short posts only support platforms that accept short-form video: Instagram (Reels), Facebook (Reels), TikTok
-		allowedShortPlatforms := map[models.Platform]bool{
-			models.Instagram: true,
-			models.Facebook:  true,
-			models.TikTok:    true,
+	// The test platform must be one of the post's platforms, so it has been
+	// through the same checks
+	if post.TestPlatform != "" && !hasPlatform(post.Platforms, post.TestPlatform) {
+		utils.RespondWithError(w, http.StatusBadRequest,
+			utils.Localize(lang, "post.test_platform_invalid", post.TestPlatform))
+		return false
+	}
+
+	// Each chosen account must be a connected account (own or shared) of a
+	// platform the post targets
+	for platform, accountID := range post.Accounts {
+		cred, err := h.db.GetCredentialsByID(userID, accountID)
+		if err != nil || cred == nil || cred.Platform != platform || !hasPlatform(post.Platforms, platform) {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.account_invalid", platform))
+			return false
 		}
-		for _, p := range post.Platforms {
-			if !allowedShortPlatforms[p] {
-				utils.RespondWithError(w, http.StatusBadRequest,
-					"Short posts only support instagram, facebook, and tiktok platforms")
-				return
-			}
+	}
+
+	// Content overrides only make sense for platforms the post targets
+	for platform := range post.PlatformContent {
+		if !hasPlatform(post.Platforms, platform) {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.content_platform_invalid", platform))
+			return false
 		}
+	}
 
-		// Short posts require at least one video
+	if post.PostType == models.PostTypeShort {
+		// Short posts require at least one video, whether or not any media
+		// was attached
 		hasVideo := false
 		if len(post.MediaIDs) > 0 {
 			mediaList, err := h.db.GetMediaByIDs(post.MediaIDs)
@@ -105,40 +316,97 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 				}
 			}
 		}
-		if !hasVideo && len(post.MediaIDs) > 0 {
+		if !hasVideo {
 			utils.RespondWithError(w, http.StatusBadRequest,
-				"Short posts require at least one video media attachment")
-			return
+				utils.Localize(lang, "post.short_requires_video"))
+			return false
 		}
 	}
 
 	if post.PostType == models.PostTypeStory {
-		// Story posts only support Facebook and Instagram
-		allowedStoryPlatforms := map[models.Platform]bool{
-			models.Facebook:  true,
-			models.Instagram: true,
+		// Story posts require exactly one media attachment (image or video)
+		if len(post.MediaIDs) == 0 {
+			utils.RespondWithError(w, http.StatusBadRequest,
+				utils.Localize(lang, "post.story_requires_media"))
+			return false
+		}
+		if len(post.MediaIDs) > 1 {
+			utils.RespondWithError(w, http.StatusBadRequest,
+				utils.Localize(lang, "post.story_single_media"))
+			return false
 		}
+	}
+
+	// Reject content over a platform's limit unless the user opted in to
+	// having it cut to fit.
+	if !post.AllowTruncation {
+		if over := publishers.OverLimitPlatforms(post); len(over) > 0 {
+			names := make([]string, len(over))
+			limits := make(map[models.Platform]int, len(over))
+			lengths := make(map[models.Platform]int, len(over))
+			for i, p := range over {
+				names[i] = string(p)
+				limits[p] = publishers.ContentLimit(p, post.PostType)
+				lengths[p] = utils.RuneLen(post.ContentFor(p))
+			}
+			utils.RespondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error":                utils.Localize(lang, "post.content_over_limit", strings.Join(names, ", ")),
+				"over_limit_platforms": over,
+				"limits":               limits,
+				"content_length":       utils.RuneLen(post.Content),
+				"content_lengths":      lengths, // Differs from content_length where platform_content overrides it
+				"message":              utils.Localize(lang, "post.content_over_limit_hint"),
+			})
+			return false
+		}
+	}
+
+	// YouTube requires an explicit audience declaration (COPPA); there is
+	// deliberately no default, since declaring wrongly carries penalties.
+	if post.MadeForKids == nil {
 		for _, p := range post.Platforms {
-			if !allowedStoryPlatforms[p] {
-				utils.RespondWithError(w, http.StatusBadRequest,
-					"Story posts only support facebook and instagram platforms")
-				return
+			if p == models.YouTube {
+				utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.made_for_kids_required"))
+				return false
 			}
 		}
+	}
 
-		// Story posts require at least one media attachment (image or video)
-		if len(post.MediaIDs) == 0 {
-			utils.RespondWithError(w, http.StatusBadRequest,
-				"Story posts require at least one image or video media attachment")
-			return
+	if publishers.YouTubeTagsLength(post.Tags) > publishers.YouTubeMaxTagsLength {
+		utils.RespondWithError(w, http.StatusBadRequest,
+			utils.Localize(lang, "post.tags_too_long", publishers.YouTubeMaxTagsLength))
+		return false
+	}
+	for _, tag := range post.Tags {
+		if strings.TrimSpace(tag) == "" || strings.ContainsAny(tag, "<>") {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.invalid_tag"))
+			return false
 		}
 	}
 
+	// Validate the YouTube category (if one was chosen) is assignable.
+	if post.CategoryID != "" {
+		for _, p := range post.Platforms {
+			if p == models.YouTube {
+				if err := h.youtubeCategories.ValidateCategory(userID, config.Load().YouTubeRegionCode, post.CategoryID); err != nil {
+					if errors.Is(err, services.ErrInvalidYouTubeCategory) {
+						utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+					} else {
+						utils.RespondWithError(w, http.StatusBadGateway, "Error validating YouTube category: "+err.Error())
+					}
+					return false
+				}
+				break
+			}
+		}
+	}
+
+	post.Media = nil
 	if len(post.MediaIDs) > 0 {
 		mediaList, err := h.db.GetMediaByIDs(post.MediaIDs)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid media IDs")
-			return
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.invalid_media_ids"))
+			return false
 		}
 
 		requestedMedia := make(map[string]struct{}, len(post.MediaIDs))
@@ -151,65 +419,97 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if len(requestedMedia) > 0 {
-			utils.RespondWithError(w, http.StatusBadRequest, "One or more media IDs were not found")
-			return
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.media_not_found"))
+			return false
 		}
 
 		for _, media := range mediaList {
 			if media.UserID != userID {
-				utils.RespondWithError(w, http.StatusForbidden, "Access denied to media")
-				return
+				utils.RespondWithError(w, http.StatusForbidden, utils.Localize(lang, "post.media_access_denied"))
+				return false
 			}
 		}
 
 		post.Media = mediaList
-	}
-
-	post.ID = uuid.New().String()
-	post.UserID = userID
-	post.CreatedAt = time.Now()
-	post.UpdatedAt = time.Now()
 
-	if post.ScheduledFor != nil && post.ScheduledFor.After(time.Now()) {
-		post.Status = models.StatusScheduled
-		if err := h.db.CreatePost(&post); err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Error creating post scheduled for future")
-			return
-		}
-		utils.RespondWithJSON(w, http.StatusCreated, post)
-	} else {
-		post.Status = models.StatusDraft
-		if err := h.db.CreatePost(&post); err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Error creating post now")
-			return
+		if err := publishers.CheckAnimatedMedia(post); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.animated_webp_unsupported"))
+			return false
 		}
 
-		results := h.publisher.PublishPost(&post)
-		failedPlatforms := make([]string, 0)
-		for _, result := range results {
-			if !result.Success {
-				failedPlatforms = append(failedPlatforms, string(result.Platform))
+		for _, p := range post.Platforms {
+			if err := publishers.CheckVideoLimits(post, p); err != nil {
+				utils.RespondWithJSON(w, http.StatusBadRequest, map[string]string{
+					"error":  utils.Localize(lang, "post.video_over_limit", string(p)),
+					"detail": err.Error(),
+				})
+				return false
 			}
 		}
+	}
 
-		response := models.PublishResponse{
-			PostID:  post.ID,
-			Results: results,
+	// Reject the post up front when a platform needs media it doesn't have,
+	// rather than failing that platform after the others have published.
+	if missing := publishers.MissingMediaPlatforms(post); len(missing) > 0 {
+		names := make([]string, len(missing))
+		required := make(map[models.Platform]publishers.MediaRequirement, len(missing))
+		for i, p := range missing {
+			required[p] = publishers.RequiredMedia(p, post.PostType)
+			names[i] = string(p) + " (" + string(required[p]) + ")"
 		}
+		utils.RespondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":          utils.Localize(lang, "post.media_required", strings.Join(names, ", ")),
+			"required_media": required,
+		})
+		return false
+	}
 
-		if len(failedPlatforms) > 0 {
-			utils.RespondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
-				"error":             "Failed to publish to one or more platforms",
-				"failed_platforms":  failedPlatforms,
-				"publish_response": response,
-				"message":           "Check publish_response.results for platform-specific details",
-				"failed_summary":    "Failed platforms: " + strings.Join(failedPlatforms, ", "),
-			})
-			return
+	// The thumbnail is a custom cover image for Facebook Reels and YouTube videos.
+	post.Thumbnail = nil
+	if post.ThumbnailMediaID != "" {
+		thumbnail, err := h.db.GetMedia(post.ThumbnailMediaID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.thumbnail_not_found"))
+			return false
+		}
+		if thumbnail.UserID != userID {
+			utils.RespondWithError(w, http.StatusForbidden, utils.Localize(lang, "post.media_access_denied"))
+			return false
 		}
+		if thumbnail.Type != models.MediaImage {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.thumbnail_not_image"))
+			return false
+		}
+		post.Thumbnail = thumbnail
+	}
 
-		utils.RespondWithJSON(w, http.StatusCreated, response)
+	// Subtitles are SRT captions for the attached video (currently used by Twitter).
+	if post.Subtitles != "" {
+		if !strings.Contains(post.Subtitles, "-->") {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.subtitles_format"))
+			return false
+		}
+		if len(post.Subtitles) > maxSubtitlesSize {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.subtitles_too_large"))
+			return false
+		}
+		hasVideo := false
+		for _, m := range post.Media {
+			if m.Type == models.MediaVideo {
+				hasVideo = true
+				break
+			}
+		}
+		if !hasVideo {
+			utils.RespondWithError(w, http.StatusBadRequest, utils.Localize(lang, "post.subtitles_require_video"))
+			return false
+		}
+		if post.SubtitleLanguage == "" {
+			post.SubtitleLanguage = "en"
+		}
 	}
+
+	return true
 }
 
 func (h *Handler) GetPosts(w http.ResponseWriter, r *http.Request) {
@@ -228,6 +528,40 @@ func (h *Handler) GetPosts(w http.ResponseWriter, r *http.Request) {
 	utils.RespondWithJSON(w, http.StatusOK, posts)
 }
 
+// GetPostSummary returns the number of the user's posts in each status,
+// with every status present so dashboards need no special cases.
+func (h *Handler) GetPostSummary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	counts, err := h.db.GetPostCountsByStatus(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching post summary")
+		return
+	}
+
+	byStatus := map[models.PostStatus]int{
+		models.StatusDraft:      0,
+		models.StatusScheduled:  0,
+		models.StatusPublishing: 0,
+		models.StatusPublished:  0,
+		models.StatusFailed:     0,
+	}
+	total := 0
+	for status, count := range counts {
+		byStatus[status] = count
+		total += count
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"counts": byStatus,
+		"total":  total,
+	})
+}
+
 func (h *Handler) GetPost(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value("userID").(string)
 	if !ok || userID == "" {
@@ -250,3 +584,200 @@ func (h *Handler) GetPost(w http.ResponseWriter, r *http.Request) {
 
 	utils.RespondWithJSON(w, http.StatusOK, post)
 }
+
+// RetryPost re-publishes a failed post to the platforms that have not
+// succeeded yet. A pending automatic retry is cancelled.
+func (h *Handler) RetryPost(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	postID := mux.Vars(r)["id"]
+
+	post, err := h.db.GetPost(postID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if post.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	post, err = h.db.ClaimFailedPost(postID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error retrying post")
+		return
+	}
+	if post == nil {
+		utils.RespondWithError(w, http.StatusConflict, "Only failed posts can be retried")
+		return
+	}
+
+	results, err := h.publisher.RetryPost(post, services.TriggerInteractive)
+	if errors.Is(err, services.ErrPublishBusy) {
+		h.respondPublishBusy(w, post, models.StatusFailed)
+		return
+	}
+	response := models.PublishResponse{
+		PostID:  post.ID,
+		Results: results,
+	}
+
+	for _, result := range results {
+		if !result.Success {
+			utils.RespondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+				"error":            "Failed to publish to one or more platforms",
+				"publish_response": response,
+			})
+			return
+		}
+	}
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// publishBusyRetryAfter is the Retry-After, in seconds, sent with the 503 of
+// a publish refused because every global publish slot is taken.
+const publishBusyRetryAfter = "30"
+
+// respondPublishBusy puts a claimed post that could not be published for
+// lack of publish slots back in status, and responds 503.
+func (h *Handler) respondPublishBusy(w http.ResponseWriter, post *models.Post, status models.PostStatus) {
+	post.Status = status
+	post.UpdatedAt = time.Now()
+	if err := h.db.UpdatePost(post); err != nil {
+		utils.Errorf("failed to restore post status post_id=%s status=%s err=%v", post.ID, status, err)
+	}
+	w.Header().Set("Retry-After", publishBusyRetryAfter)
+	utils.RespondWithError(w, http.StatusServiceUnavailable, "Too many posts are being published right now; try again shortly")
+}
+
+// PublishPostNow publishes a scheduled post immediately instead of waiting
+// for its scheduled time.
+func (h *Handler) PublishPostNow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	postID := mux.Vars(r)["id"]
+
+	post, err := h.db.GetPost(postID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if post.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	// The claim fails if the scheduler picked the post up in the meantime
+	post, err = h.db.ClaimScheduledPost(postID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error publishing post")
+		return
+	}
+	if post == nil {
+		utils.RespondWithError(w, http.StatusConflict, "Only scheduled posts can be published now")
+		return
+	}
+
+	results, err := h.publisher.PublishPost(post, services.TriggerInteractive)
+	if errors.Is(err, services.ErrPublishBusy) {
+		h.respondPublishBusy(w, post, models.StatusScheduled)
+		return
+	}
+	response := models.PublishResponse{
+		PostID:  post.ID,
+		Results: results,
+	}
+
+	for _, result := range results {
+		if !result.Success {
+			utils.RespondWithJSON(w, http.StatusBadGateway, map[string]interface{}{
+				"error":            "Failed to publish to one or more platforms",
+				"publish_response": response,
+			})
+			return
+		}
+	}
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// GetPostResults returns the stored publish results of a post, one per
+// platform per attempt, so the outcome of a scheduled publish can be read
+// back after the scheduler ran it.
+func (h *Handler) GetPostResults(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	post, err := h.db.GetPost(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if post.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	results, err := h.db.GetPublishResults(post.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching publish results")
+		return
+	}
+	jobs, err := h.db.GetPublishJobs(post.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching publish jobs")
+		return
+	}
+	platforms, err := h.db.GetPlatformStatuses(post.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching platform statuses")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"post_id":   post.ID,
+		"status":    post.Status,
+		"results":   results,
+		"jobs":      jobs,
+		"platforms": platforms,
+	})
+}
+
+// GetPostPlatformStatus asks each platform of a post for the post's current
+// state there, e.g. whether a YouTube video has finished processing.
+func (h *Handler) GetPostPlatformStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	post, err := h.db.GetPost(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+	if post.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	statuses, err := h.publisher.PlatformStatuses(post)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching platform status")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"post_id":   post.ID,
+		"platforms": statuses,
+	})
+}
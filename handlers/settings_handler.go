@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GetSettings returns the authenticated user's settings.
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	settings, err := h.db.GetUserSettings(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching settings")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings updates the authenticated user's settings. Omitted fields are
+// left unchanged; send empty publish window bounds to remove the window.
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	var req struct {
+		PublishWindowStart *string `json:"publish_window_start"`
+		PublishWindowEnd   *string `json:"publish_window_end"`
+		Timezone           *string `json:"timezone"`
+
+		NotifyPublishFailures *bool `json:"notify_publish_failures"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	settings, err := h.db.GetUserSettings(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching settings")
+		return
+	}
+
+	if req.PublishWindowStart != nil {
+		settings.PublishWindowStart = strings.TrimSpace(*req.PublishWindowStart)
+	}
+	if req.PublishWindowEnd != nil {
+		settings.PublishWindowEnd = strings.TrimSpace(*req.PublishWindowEnd)
+	}
+	if req.Timezone != nil {
+		settings.Timezone = strings.TrimSpace(*req.Timezone)
+		if settings.Timezone == "" {
+			settings.Timezone = "UTC"
+		}
+	}
+
+	if req.NotifyPublishFailures != nil {
+		settings.NotifyPublishFailures = *req.NotifyPublishFailures
+	}
+
+	if (settings.PublishWindowStart == "") != (settings.PublishWindowEnd == "") {
+		utils.RespondWithError(w, http.StatusBadRequest, "publish_window_start and publish_window_end must be set together")
+		return
+	}
+	for _, v := range []string{settings.PublishWindowStart, settings.PublishWindowEnd} {
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(models.PublishWindowLayout, v); err != nil || len(v) != len(models.PublishWindowLayout) {
+			utils.RespondWithError(w, http.StatusBadRequest, "Publish window bounds must use the HH:MM 24-hour format")
+			return
+		}
+	}
+	if settings.HasPublishWindow() && settings.PublishWindowStart == settings.PublishWindowEnd {
+		utils.RespondWithError(w, http.StatusBadRequest, "publish_window_start and publish_window_end must differ")
+		return
+	}
+	if _, err := time.LoadLocation(settings.Timezone); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Unknown timezone: use an IANA name such as Europe/Paris")
+		return
+	}
+
+	if err := h.db.SaveUserSettings(settings); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error saving settings")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, settings)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type templateRequest struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// validate trims the request and checks that both fields are set.
+func (req *templateRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return "name is required"
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return "content is required"
+	}
+	return ""
+}
+
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	var req templateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		utils.RespondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	now := time.Now()
+	t := &models.Template{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      req.Name,
+		Content:   req.Content,
+		Variables: utils.TemplateVariables(req.Content),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.db.CreateTemplate(t); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error creating template")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, t)
+}
+
+func (h *Handler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	templates, err := h.db.GetUserTemplates(userID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching templates")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, templates)
+}
+
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.ownedTemplate(w, r)
+	if !ok {
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, t)
+}
+
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.ownedTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	var req templateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		utils.RespondWithError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	t.Name = req.Name
+	t.Content = req.Content
+	t.Variables = utils.TemplateVariables(req.Content)
+	t.UpdatedAt = time.Now()
+	if err := h.db.UpdateTemplate(t); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error updating template")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, t)
+}
+
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.ownedTemplate(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteTemplate(t.ID); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error deleting template")
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Template deleted successfully"})
+}
+
+// ownedTemplate loads the {id} template of the authenticated user, writing
+// the error response and returning false when that fails.
+func (h *Handler) ownedTemplate(w http.ResponseWriter, r *http.Request) (*models.Template, bool) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return nil, false
+	}
+
+	t, err := h.db.GetTemplate(mux.Vars(r)["id"])
+	if err != nil {
+		utils.RespondWithError(w, http.StatusNotFound, "Template not found")
+		return nil, false
+	}
+	if t.UserID != userID {
+		utils.RespondWithError(w, http.StatusForbidden, "Access denied")
+		return nil, false
+	}
+	return t, true
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/services"
+	"SocialMediaAPI/utils"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// tusVersion is the only tus protocol version supported.
+const tusVersion = "1.0.0"
+
+// Resumable uploads follow the tus 1.0.0 core protocol plus the creation and
+// termination extensions (https://tus.io/protocols/resumable-upload):
+//   - POST   /api/media/uploads       creates an upload (Upload-Length, Upload-Metadata)
+//   - HEAD   /api/media/uploads/{id}  returns the current Upload-Offset
+//   - PATCH  /api/media/uploads/{id}  appends bytes at Upload-Offset
+//   - DELETE /api/media/uploads/{id}  discards the upload
+//
+// When the last byte is received the file becomes a media item; its ID is
+// returned in the X-Media-ID header.
+
+// CreateUpload starts a resumable upload.
+func (h *Handler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	if !checkTusVersion(w, r) {
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Upload-Length header must be a positive integer")
+		return
+	}
+
+	metadata, err := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filename := metadata["filename"]
+	if !allowedUploadExtensions[strings.ToLower(filepath.Ext(filename))] {
+		utils.RespondWithError(w, http.StatusBadRequest,
+			"Upload-Metadata filename must have an allowed extension: .jpg, .jpeg, .png, .gif, .webp, .mp4")
+		return
+	}
+
+	altText := strings.TrimSpace(metadata["alt_text"])
+	if utils.RuneLen(altText) > maxAltTextLength {
+		utils.RespondWithError(w, http.StatusBadRequest,
+			fmt.Sprintf("alt_text must be at most %d characters", maxAltTextLength))
+		return
+	}
+
+	twitterMediaCategory := strings.TrimSpace(metadata["twitter_media_category"])
+	if twitterMediaCategory != "" && !models.TwitterMediaCategories[twitterMediaCategory] {
+		utils.RespondWithError(w, http.StatusBadRequest,
+			"Invalid twitter_media_category. Must be 'tweet_image', 'tweet_gif', 'tweet_video', or 'amplify_video'")
+		return
+	}
+
+	upload, err := h.uploads.Create(userID, filename, length, altText, twitterMediaCategory)
+	if errors.Is(err, services.ErrUploadTooLarge) {
+		utils.RespondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error creating upload")
+		return
+	}
+
+	w.Header().Set("Location", "/api/media/uploads/"+upload.ID)
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// GetUploadOffset answers HEAD with how many bytes of the upload were received.
+func (h *Handler) GetUploadOffset(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	if !checkTusVersion(w, r) {
+		return
+	}
+
+	upload, err := h.uploads.Get(userID, mux.Vars(r)["id"])
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	setUploadHeaders(w, upload)
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// AppendUpload appends the request body to the upload at Upload-Offset.
+func (h *Handler) AppendUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	if !checkTusVersion(w, r) {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		utils.RespondWithError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream")
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		utils.RespondWithError(w, http.StatusBadRequest, "Upload-Offset header must be a non-negative integer")
+		return
+	}
+
+	upload, media, err := h.uploads.Append(userID, mux.Vars(r)["id"], offset, r.Body, utils.MediaBaseURL(r))
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	setUploadHeaders(w, upload)
+	if media != nil {
+		w.Header().Set("X-Media-ID", media.ID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// TerminateUpload discards an unfinished upload.
+func (h *Handler) TerminateUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+	if !checkTusVersion(w, r) {
+		return
+	}
+
+	if err := h.uploads.Terminate(userID, mux.Vars(r)["id"]); err != nil {
+		respondUploadError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkTusVersion sets Tus-Resumable on the response and rejects requests
+// for another protocol version with 412 Precondition Failed.
+func checkTusVersion(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		utils.RespondWithError(w, http.StatusPreconditionFailed, "Unsupported or missing Tus-Resumable header; expected "+tusVersion)
+		return false
+	}
+	return true
+}
+
+func setUploadHeaders(w http.ResponseWriter, upload *models.MediaUpload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	if upload.MediaID != "" {
+		w.Header().Set("X-Media-ID", upload.MediaID)
+	}
+}
+
+func respondUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrUploadNotFound):
+		utils.RespondWithError(w, http.StatusNotFound, "Upload not found")
+	case errors.Is(err, services.ErrUploadOffsetMismatch):
+		utils.RespondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrUploadLocked):
+		utils.RespondWithError(w, http.StatusLocked, err.Error())
+	case errors.Is(err, services.ErrUploadRejected):
+		utils.RespondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondWithError(w, http.StatusInternalServerError, "Error processing upload")
+	}
+}
+
+// parseUploadMetadata decodes an Upload-Metadata header: comma-separated
+// "key base64value" pairs, where the value may be omitted.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if key == "" || err != nil {
+			return nil, fmt.Errorf("Upload-Metadata is malformed near %q", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/services"
+	"SocialMediaAPI/utils"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// regionCodePattern matches an ISO 3166-1 alpha-2 country code.
+var regionCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// GetYouTubeCategories lists the YouTube video categories for a region so the
+// client can offer a valid category_id. Only assignable categories are returned.
+func (h *Handler) GetYouTubeCategories(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("userID").(string)
+	if !ok || userID == "" {
+		utils.RespondWithError(w, http.StatusUnauthorized, "User ID not found in request context")
+		return
+	}
+
+	region := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("region")))
+	if region == "" {
+		region = config.Load().YouTubeRegionCode
+	}
+	if !regionCodePattern.MatchString(region) {
+		utils.RespondWithError(w, http.StatusBadRequest, "Invalid region. Must be a two-letter ISO 3166-1 country code (e.g. US)")
+		return
+	}
+
+	categories, err := h.youtubeCategories.ListCategories(userID, region)
+	if errors.Is(err, services.ErrYouTubeNotConnected) {
+		utils.RespondWithError(w, http.StatusConflict, "YouTube account is not connected. Connect it with GET /api/auth/youtube, then try again")
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadGateway, "Error fetching YouTube categories: "+err.Error())
+		return
+	}
+
+	assignable := make([]interface{}, 0, len(categories))
+	for _, c := range categories {
+		if c.Assignable {
+			assignable = append(assignable, c)
+		}
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"region":     region,
+		"categories": assignable,
+	})
+}
@@ -9,13 +9,16 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Publish window timezones must resolve on hosts without a zoneinfo database
 
 	"SocialMediaAPI/config"
 	"SocialMediaAPI/database"
 	"SocialMediaAPI/handlers"
 	"SocialMediaAPI/handlers/oauth"
 	"SocialMediaAPI/middleware"
+	"SocialMediaAPI/models"
 	"SocialMediaAPI/services"
+	"SocialMediaAPI/utils"
 
 	"github.com/gorilla/mux"
 )
@@ -28,20 +31,36 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	storage, err := services.NewStorageService(cfg.UploadDir, cfg.BaseURL, cfg.MaxImageUploadSize, cfg.MaxVideoUploadSize)
+	storage, err := services.NewStorageService(cfg.UploadDir, cfg.MediaBaseURL(), cfg.MaxImageUploadSize, cfg.MaxVideoUploadSize)
 	if err != nil {
 		log.Fatal("Failed to initialize storage:", err)
 	}
 
-	authService := services.NewAuthService(db)
-	publisher := services.NewPublisherService(db)
-	oauthStateService := services.NewOAuthStateService()
+	uploads, err := services.NewUploadService(db, storage, cfg.ResumableUploadDir, cfg.MaxVideoUploadSize, cfg.ResumableUploadExpiry)
+	if err != nil {
+		log.Fatal("Failed to initialize resumable uploads:", err)
+	}
 
-	scheduler := services.NewScheduler(db, publisher)
+	mailer := services.NewMailer(cfg)
+	authService := services.NewAuthService(db, mailer)
+	if err := authService.SeedAdmin(); err != nil {
+		log.Printf("Failed to seed admin user: %v", err)
+	}
+	publisher := services.NewPublisherService(db, mailer)
+	if err := publisher.ValidatePostTypePlatforms(); err != nil {
+		log.Fatal("Invalid post type platforms: ", err)
+	}
+	oauthStateService := services.NewOAuthStateService(db)
+	youtubeCategories := services.NewYouTubeCategoryService(db, publisher)
+
+	publishQueue := services.NewPublishQueue(db, publisher)
+	publishQueue.Start()
+
+	scheduler := services.NewScheduler(db, publisher, publishQueue, services.NewTokenExpiryNotifier(db, mailer))
 	scheduler.Start()
 
-	handler := handlers.NewHandler(db, publisher, authService, storage)
-	oauthHandler := oauth.NewOAuthHandler(db, oauthStateService)
+	oauthHandler := oauth.NewOAuthHandler(db, oauthStateService, utils.NewHTTPClient(cfg.OAuthHTTPTimeout))
+	handler := handlers.NewHandler(db, publisher, publishQueue, authService, storage, uploads, youtubeCategories, oauthHandler)
 
 	r := setupRoutes(handler, oauthHandler, authService, cfg)
 
@@ -64,17 +83,33 @@ func main() {
 	}
 
 	if cfg.TLSEnabled {
+		cipherSuites, err := tlsCipherSuites(cfg.TLSCipherSuites)
+		if err != nil {
+			log.Fatal("Invalid TLS_CIPHER_SUITES: ", err)
+		}
+		certs, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSOCSPStapleFile)
+		if err != nil {
+			log.Fatal("Failed to load TLS certificate: ", err)
+		}
+		// Renewed certificates are picked up when the files change; SIGHUP forces a reload
+		certs.reloadOnSIGHUP()
+
 		srv.TLSConfig = &tls.Config{
-			MinVersion: tls.VersionTLS12,
+			MinVersion:     tls.VersionTLS12,
+			CipherSuites:   cipherSuites,
+			GetCertificate: certs.GetCertificate,
 		}
 	}
 
+	// Startup is complete; the readiness probe can report ready.
+	handler.SetReady(true)
+
 	// Start server in a goroutine so we can listen for shutdown signals.
 	go func() {
 		var err error
 		if cfg.TLSEnabled {
 			log.Printf("TLS enabled — listening on %s", cfg.BaseURL)
-			err = srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+			err = srv.ListenAndServeTLS("", "")
 		} else {
 			log.Printf("TLS disabled — listening on %s", cfg.BaseURL)
 			err = srv.ListenAndServe()
@@ -91,11 +126,15 @@ func main() {
 	sig := <-quit
 	log.Printf("Received signal %s — shutting down gracefully...", sig)
 
+	// Fail readiness first so load balancers stop sending new requests.
+	handler.SetReady(false)
+
 	// Give in-flight requests up to 30 seconds to finish.
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	scheduler.Stop()
+	publishQueue.Stop(shutdownCtx)
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("Forced shutdown: %v", err)
@@ -115,6 +154,9 @@ func setupRoutes(h *handlers.Handler, oh *oauth.OAuthHandler, authService *servi
 		// Set CORS_ALLOWED_ORIGINS env var for production frontends.
 		log.Println("WARNING: CORS_ALLOWED_ORIGINS is not set — cross-origin requests will be blocked by browsers")
 	}
+	if len(cfg.CORSExposedHeaders) > 0 {
+		corsCfg.ExposedHeaders = cfg.CORSExposedHeaders
+	}
 	r.Use(middleware.CORS(corsCfg))
 
 	// ── Global rate limiter (per-IP) ────────────────────────────────
@@ -126,16 +168,23 @@ func setupRoutes(h *handlers.Handler, oh *oauth.OAuthHandler, authService *servi
 
 	// Public routes
 	r.HandleFunc("/health", h.HealthCheck).Methods("GET")
+	r.HandleFunc("/health/live", h.Liveness).Methods("GET")
+	r.HandleFunc("/health/ready", h.Readiness).Methods("GET")
+	r.HandleFunc("/metrics", h.Metrics).Methods("GET")
 	// Body limits: 1 MB for JSON routes, MaxUploadSize for file uploads.
 	// Applied per-handler (not globally) so upload routes aren't capped at 1 MB.
 	jsonLimit := int64(1 << 20) // 1 MB
 
 	r.HandleFunc("/api/auth/register", middleware.BodyLimitHandler(jsonLimit, authLimiter.LimitHandler(h.Register))).Methods("POST")
 	r.HandleFunc("/api/auth/login", middleware.BodyLimitHandler(jsonLimit, authLimiter.LimitHandler(h.Login))).Methods("POST")
+	r.HandleFunc("/api/auth/forgot-password", middleware.BodyLimitHandler(jsonLimit, authLimiter.LimitHandler(h.ForgotPassword))).Methods("POST")
+	r.HandleFunc("/api/auth/reset-password", middleware.BodyLimitHandler(jsonLimit, authLimiter.LimitHandler(h.ResetPassword))).Methods("POST")
 
 	// OAuth routes (public - no JWT required for callback)
 	r.HandleFunc("/auth/facebook/callback", oh.HandleFacebookCallback).Methods("GET")
 	r.HandleFunc("/auth/instagram/callback", oh.HandleInstagramCallback).Methods("GET")
+	r.HandleFunc("/auth/linkedin/callback", oh.HandleLinkedInCallback).Methods("GET")
+	r.HandleFunc("/auth/threads/callback", oh.HandleThreadsCallback).Methods("GET")
 	r.HandleFunc("/auth/tiktok/callback", oh.HandleTikTokCallback).Methods("GET")
 	r.HandleFunc("/auth/twitter/callback", oh.HandleTwitterCallback).Methods("GET")
 	r.HandleFunc("/auth/youtube/callback", oh.HandleYouTubeCallback).Methods("GET")
@@ -145,16 +194,33 @@ func setupRoutes(h *handlers.Handler, oh *oauth.OAuthHandler, authService *servi
 
 	// Static file serving
 	uploadDir := config.Load().UploadDir
-	r.PathPrefix("/uploads/").Handler(http.StripPrefix("/uploads/",
-		http.FileServer(http.Dir(uploadDir))))
+	r.PathPrefix("/uploads/").Handler(middleware.SignedURL(http.StripPrefix("/uploads/",
+		http.FileServer(http.Dir(uploadDir)))))
 
 	// Protected routes
 	protected := r.PathPrefix("/api").Subrouter()
 	protected.Use(middleware.AuthMiddleware(authService))
+	// Bound read/CRUD handlers; uploads and publishing legitimately run long
+	// and are only limited by the server's write timeout.
+	protected.Use(middleware.Timeout(cfg.RequestTimeout,
+		"POST /api/media",
+		"POST /api/media/uploads",
+		"PATCH /api/media/uploads/{id}",
+		"POST /api/posts",
+		"POST /api/posts/{id}/retry",
+		"POST /api/posts/{id}/publish-now",
+	))
+	// Compress JSON responses; /uploads/ above is left alone (media is
+	// already compressed and the file server handles Range requests).
+	protected.Use(middleware.Compress(cfg.CompressionMinSize))
+	// Conditional GETs: polling clients get 304 when nothing changed.
+	protected.Use(middleware.ETag())
 
 	// OAuth initiation (requires JWT)
 	protected.HandleFunc("/auth/facebook", oh.InitiateFacebookOAuth).Methods("GET")
 	protected.HandleFunc("/auth/instagram", oh.InitiateInstagramOAuth).Methods("GET")
+	protected.HandleFunc("/auth/linkedin", oh.InitiateLinkedInOAuth).Methods("GET")
+	protected.HandleFunc("/auth/threads", oh.InitiateThreadsOAuth).Methods("GET")
 	protected.HandleFunc("/auth/tiktok", oh.InitiateTikTokOAuth).Methods("GET")
 	protected.HandleFunc("/auth/twitter", oh.InitiateTwitterOAuth).Methods("GET")
 	protected.HandleFunc("/auth/youtube", oh.InitiateYouTubeOAuth).Methods("GET")
@@ -162,17 +228,66 @@ func setupRoutes(h *handlers.Handler, oh *oauth.OAuthHandler, authService *servi
 	// Credentials
 	protected.HandleFunc("/credentials", middleware.BodyLimitHandler(jsonLimit, h.SaveCredentials)).Methods("POST")
 	protected.HandleFunc("/credentials/status", h.GetConnectedPlatforms).Methods("GET")
+	protected.HandleFunc("/credentials", h.DisconnectAllPlatforms).Methods("DELETE")
 	protected.HandleFunc("/credentials/disconnect", h.DisconnectPlatform).Methods("DELETE")
+	protected.HandleFunc("/credentials/share", middleware.BodyLimitHandler(jsonLimit, h.ShareCredentials)).Methods("PUT")
+
+	// Organizations
+	protected.HandleFunc("/organizations", middleware.BodyLimitHandler(jsonLimit, h.CreateOrganization)).Methods("POST")
+	protected.HandleFunc("/organizations", h.GetOrganizations).Methods("GET")
+	protected.HandleFunc("/organizations/{id}/members", h.GetOrganizationMembers).Methods("GET")
+	protected.HandleFunc("/organizations/{id}/members", middleware.BodyLimitHandler(jsonLimit, h.AddOrganizationMember)).Methods("POST")
+	protected.HandleFunc("/organizations/{id}/members/{userId}", h.RemoveOrganizationMember).Methods("DELETE")
 
 	// Media (upload gets a higher body limit to allow large files)
 	protected.HandleFunc("/media", middleware.BodyLimitHandler(cfg.MaxUploadSize, h.UploadMedia)).Methods("POST")
 	protected.HandleFunc("/media", h.GetMedia).Methods("GET")
+	// Resumable (tus) uploads; PATCH bodies are capped by the declared Upload-Length
+	protected.HandleFunc("/media/uploads", h.CreateUpload).Methods("POST")
+	protected.HandleFunc("/media/uploads/{id}", h.GetUploadOffset).Methods("HEAD")
+	protected.HandleFunc("/media/uploads/{id}", h.AppendUpload).Methods("PATCH")
+	protected.HandleFunc("/media/uploads/{id}", h.TerminateUpload).Methods("DELETE")
+	protected.HandleFunc("/media/{id}", middleware.BodyLimitHandler(jsonLimit, h.UpdateMedia)).Methods("PATCH")
+	protected.HandleFunc("/media/{id}/sign", middleware.BodyLimitHandler(jsonLimit, h.SignMedia)).Methods("POST")
 	protected.HandleFunc("/media/{id}", h.DeleteMedia).Methods("DELETE")
 
 	// Posts
 	protected.HandleFunc("/posts", middleware.BodyLimitHandler(jsonLimit, h.CreatePost)).Methods("POST")
 	protected.HandleFunc("/posts", h.GetPosts).Methods("GET")
+	protected.HandleFunc("/posts/summary", h.GetPostSummary).Methods("GET")
 	protected.HandleFunc("/posts/{id}", h.GetPost).Methods("GET")
+	protected.HandleFunc("/posts/{id}", middleware.BodyLimitHandler(jsonLimit, h.UpdatePost)).Methods("PATCH")
+	protected.HandleFunc("/posts/{id}/retry", h.RetryPost).Methods("POST")
+	protected.HandleFunc("/posts/{id}/publish-now", h.PublishPostNow).Methods("POST")
+	protected.HandleFunc("/posts/{id}/results", h.GetPostResults).Methods("GET")
+	protected.HandleFunc("/posts/{id}/platform-status", h.GetPostPlatformStatus).Methods("GET")
+
+	// Content
+	protected.HandleFunc("/content/analyze", middleware.BodyLimitHandler(jsonLimit, h.AnalyzeContent)).Methods("POST")
+
+	// Templates
+	protected.HandleFunc("/templates", middleware.BodyLimitHandler(jsonLimit, h.CreateTemplate)).Methods("POST")
+	protected.HandleFunc("/templates", h.GetTemplates).Methods("GET")
+	protected.HandleFunc("/templates/{id}", h.GetTemplate).Methods("GET")
+	protected.HandleFunc("/templates/{id}", middleware.BodyLimitHandler(jsonLimit, h.UpdateTemplate)).Methods("PUT")
+	protected.HandleFunc("/templates/{id}", h.DeleteTemplate).Methods("DELETE")
+
+	// YouTube
+	protected.HandleFunc("/youtube/categories", h.GetYouTubeCategories).Methods("GET")
+
+	// Audit log
+	protected.HandleFunc("/audit", h.GetAuditLog).Methods("GET")
+
+	// Settings
+	protected.HandleFunc("/settings", h.GetSettings).Methods("GET")
+	protected.HandleFunc("/settings", middleware.BodyLimitHandler(jsonLimit, h.UpdateSettings)).Methods("PUT")
+
+	// Admin (requires the admin role)
+	admin := protected.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/users/{id}/credentials", h.AdminListUserCredentials).Methods("GET")
+	admin.HandleFunc("/users/{id}/credentials", h.AdminRevokeUserCredentials).Methods("DELETE")
+	admin.HandleFunc("/users/{id}/sessions", h.AdminRevokeUserSessions).Methods("DELETE")
 
 	return r
 }
@@ -181,13 +296,19 @@ func printEndpoints() {
 	log.Println("Endpoints available:")
 	log.Println("  POST   /api/auth/register          - Register new user")
 	log.Println("  POST   /api/auth/login             - Login")
+	log.Println("  POST   /api/auth/forgot-password   - Email a password reset token")
+	log.Println("  POST   /api/auth/reset-password    - Set a new password with a reset token")
 	log.Println("  GET    /api/auth/facebook          - Initiate Facebook OAuth (auth)")
 	log.Println("  GET    /api/auth/instagram         - Initiate Instagram OAuth (auth)")
+	log.Println("  GET    /api/auth/linkedin          - Initiate LinkedIn OAuth (auth)")
+	log.Println("  GET    /api/auth/threads           - Initiate Threads OAuth (auth)")
 	log.Println("  GET    /api/auth/tiktok            - Initiate TikTok OAuth (auth)")
 	log.Println("  GET    /api/auth/twitter           - Initiate Twitter OAuth (auth)")
 	log.Println("  GET    /api/auth/youtube           - Initiate YouTube OAuth (auth)")
 	log.Println("  GET    /auth/facebook/callback     - Facebook OAuth callback")
 	log.Println("  GET    /auth/instagram/callback    - Instagram OAuth callback")
+	log.Println("  GET    /auth/linkedin/callback     - LinkedIn OAuth callback")
+	log.Println("  GET    /auth/threads/callback      - Threads OAuth callback")
 	log.Println("  GET    /auth/tiktok/callback       - TikTok OAuth callback")
 	log.Println("  GET    /auth/twitter/callback      - Twitter OAuth callback")
 	log.Println("  GET    /auth/youtube/callback      - YouTube OAuth callback")
@@ -195,13 +316,48 @@ func printEndpoints() {
 	log.Println("  GET    /oauth/error                - OAuth error page")
 	log.Println("  GET    /api/credentials/status     - Get connected platforms (auth)")
 	log.Println("  POST   /api/credentials            - Save platform credentials (auth)")
+	log.Println("  DELETE /api/credentials            - Disconnect all platforms (auth)")
 	log.Println("  DELETE /api/credentials/disconnect - Disconnect platform (auth)")
+	log.Println("  PUT    /api/credentials/share      - Share platform with organization (auth)")
+	log.Println("  POST   /api/organizations          - Create organization (auth)")
+	log.Println("  GET    /api/organizations          - List organizations (auth)")
+	log.Println("  GET    /api/organizations/{id}/members - List members (auth)")
+	log.Println("  POST   /api/organizations/{id}/members - Add member (auth, owner)")
+	log.Println("  DELETE /api/organizations/{id}/members/{userId} - Remove member (auth)")
 	log.Println("  POST   /api/media                  - Upload media (auth)")
 	log.Println("  GET    /api/media                  - Get user media (auth)")
+	log.Println("  POST   /api/media/uploads          - Start a resumable (tus) upload (auth)")
+	log.Println("  HEAD   /api/media/uploads/{id}     - Get resumable upload offset (auth)")
+	log.Println("  PATCH  /api/media/uploads/{id}     - Append to resumable upload (auth)")
+	log.Println("  DELETE /api/media/uploads/{id}     - Cancel resumable upload (auth)")
+	log.Println("  PATCH  /api/media/{id}             - Update media metadata (auth)")
 	log.Println("  DELETE /api/media/{id}             - Delete media (auth)")
+	log.Println("  POST   /api/media/{id}/sign        - Get a freshly signed media URL (auth)")
 	log.Println("  POST   /api/posts                  - Create/schedule post (auth)")
 	log.Println("  GET    /api/posts                  - Get user posts (auth)")
+	log.Println("  GET    /api/posts/summary          - Count posts by status (auth)")
 	log.Println("  GET    /api/posts/{id}             - Get specific post (auth)")
+	log.Println("  PATCH  /api/posts/{id}             - Edit a draft or scheduled post (auth)")
+	log.Println("  POST   /api/posts/{id}/retry       - Retry a failed post (auth)")
+	log.Println("  POST   /api/posts/{id}/publish-now - Publish a scheduled post immediately (auth)")
+	log.Println("  GET    /api/posts/{id}/results     - Stored publish results per attempt (auth)")
+	log.Println("  GET    /api/posts/{id}/platform-status - Live status of the post on each platform (auth)")
+	log.Println("  POST   /api/content/analyze        - Preview content length per platform (auth)")
+	log.Println("  POST   /api/templates              - Create content template (auth)")
+	log.Println("  GET    /api/templates              - List content templates (auth)")
+	log.Println("  GET    /api/templates/{id}         - Get content template (auth)")
+	log.Println("  PUT    /api/templates/{id}         - Update content template (auth)")
+	log.Println("  DELETE /api/templates/{id}         - Delete content template (auth)")
+	log.Println("  GET    /api/youtube/categories     - List assignable YouTube categories (auth)")
+	log.Println("  GET    /api/audit                  - Get audit log (auth)")
+	log.Println("  GET    /api/settings               - Get user settings (auth)")
+	log.Println("  PUT    /api/settings               - Update user settings, e.g. publish window (auth)")
+	log.Println("  GET    /api/admin/users/{id}/credentials - List a user's credentials (admin)")
+	log.Println("  DELETE /api/admin/users/{id}/credentials - Revoke a user's credentials (admin)")
+	log.Println("  DELETE /api/admin/users/{id}/sessions    - Revoke a user's sessions (admin)")
 	log.Println("  GET    /health                     - Health check")
+	log.Println("  GET    /health/live                - Liveness probe")
+	log.Println("  GET    /health/ready               - Readiness probe (DB, uploads, config)")
+	log.Println("  GET    /metrics                    - Prometheus metrics (publish failures)")
 	log.Println("  GET    /uploads/*                  - Serve uploaded files")
 }
@@ -15,23 +15,24 @@ func AuthMiddleware(authService *services.AuthService) mux.MiddlewareFunc {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				utils.RespondWithError(w, http.StatusUnauthorized, "Missing authorization header")
+				utils.RespondWithError(w, http.StatusUnauthorized, utils.LocalizeRequest(r, "auth.missing_header"))
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid authorization header")
+				utils.RespondWithError(w, http.StatusUnauthorized, utils.LocalizeRequest(r, "auth.invalid_header"))
 				return
 			}
 
 			claims, err := authService.ValidateToken(parts[1])
 			if err != nil {
-				utils.RespondWithError(w, http.StatusUnauthorized, "Invalid token")
+				utils.RespondWithError(w, http.StatusUnauthorized, utils.LocalizeRequest(r, "auth.invalid_token"))
 				return
 			}
 
 			ctx := context.WithValue(r.Context(), "userID", claims.UserID)
+			ctx = context.WithValue(ctx, "role", claims.Role) // the user's current role, see ValidateToken
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -1,6 +1,10 @@
 package middleware
 
 import (
+	"SocialMediaAPI/utils"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -14,13 +18,17 @@ import (
 // limiting request bodies. It works transparently with json.NewDecoder,
 // io.ReadAll, r.ParseForm, etc.
 //
+// Handlers only see a read error when the limit trips, and usually answer
+// it with a generic 400. If the body went over the limit, that 400 is
+// replaced with a 413 naming the limit, so clients know what to fix.
+//
 // Apply this globally on the router for a sensible default (e.g. 1 MB), and
 // use BodyLimitHandler on specific routes that need a higher limit (e.g.
 // file uploads).
 func BodyLimit(maxBytes int64) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			w, r = limitBody(w, r, maxBytes)
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -31,7 +39,81 @@ func BodyLimit(maxBytes int64) mux.MiddlewareFunc {
 // global default — for example, file upload endpoints.
 func BodyLimitHandler(maxBytes int64, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		w, r = limitBody(w, r, maxBytes)
 		next(w, r)
 	}
 }
+
+func limitBody(w http.ResponseWriter, r *http.Request, maxBytes int64) (http.ResponseWriter, *http.Request) {
+	body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, maxBytes)}
+	r.Body = body
+	return &bodyLimitWriter{ResponseWriter: w, r: r, body: body, limit: maxBytes}, r
+}
+
+// limitedBody remembers whether a read hit the size limit.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// bodyLimitWriter turns a handler's 400 into a 413 once the body has gone
+// over the limit, discarding the handler's own error body.
+type bodyLimitWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	body        *limitedBody
+	limit       int64
+	wroteHeader bool
+	replaced    bool
+}
+
+func (w *bodyLimitWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if status == http.StatusBadRequest && w.body.exceeded {
+		w.replaced = true
+		utils.RespondWithJSON(w.ResponseWriter, http.StatusRequestEntityTooLarge, map[string]interface{}{
+			"error":     utils.LocalizeRequest(w.r, "request.body_too_large", formatBytes(w.limit)),
+			"max_bytes": w.limit,
+		})
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *bodyLimitWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLimitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// formatBytes renders a size limit the way it is usually configured.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
@@ -20,6 +20,10 @@ type CORSConfig struct {
 	// AllowedHeaders lists the request headers the client may send.
 	AllowedHeaders []string
 
+	// ExposedHeaders lists the response headers, beyond the CORS-safelisted
+	// ones, that browser scripts are allowed to read.
+	ExposedHeaders []string
+
 	// AllowCredentials indicates whether the browser should include
 	// credentials (cookies, Authorization header, TLS client certs) in
 	// cross-origin requests.
@@ -34,8 +38,11 @@ type CORSConfig struct {
 // AllowedOrigins is intentionally empty – callers MUST set it.
 func DefaultCORSConfig() CORSConfig {
 	return CORSConfig{
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Requested-With"},
+		AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Requested-With",
+			"Tus-Resumable", "Upload-Length", "Upload-Offset", "Upload-Metadata"},
+		ExposedHeaders: []string{"Retry-After", "ETag", "Location",
+			"Tus-Resumable", "Tus-Version", "Upload-Offset", "Upload-Length", "Upload-Expires", "X-Media-ID", "X-Total-Count"},
 		AllowCredentials: true,
 		MaxAge:           "86400", // 24 hours
 	}
@@ -63,6 +70,7 @@ func CORS(cfg CORSConfig) mux.MiddlewareFunc {
 
 	methods := strings.Join(cfg.AllowedMethods, ", ")
 	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -99,6 +107,9 @@ func CORS(cfg CORSConfig) mux.MiddlewareFunc {
 
 				w.Header().Set("Access-Control-Allow-Methods", methods)
 				w.Header().Set("Access-Control-Allow-Headers", headers)
+				if exposed != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposed)
+				}
 				if cfg.MaxAge != "" {
 					w.Header().Set("Access-Control-Max-Age", cfg.MaxAge)
 				}
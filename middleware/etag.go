@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// ETag returns gorilla/mux middleware that adds an ETag to successful GET
+// responses and answers 304 Not Modified when the client's If-None-Match
+// already names the current body.
+//
+// The tag is a hash of the response body, so it works for any handler
+// without knowing how the data was loaded. It is a weak validator because
+// Compress may change the encoding of the same representation. Register it
+// after Compress so it hashes the uncompressed body.
+func ETag() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ew := &etagWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(ew, r)
+
+			if ew.status != http.StatusOK || w.Header().Get("ETag") != "" {
+				w.WriteHeader(ew.status)
+				w.Write(ew.buf.Bytes())
+				return
+			}
+
+			sum := sha256.Sum256(ew.buf.Bytes())
+			tag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			w.Header().Set("ETag", tag)
+
+			if etagMatches(r.Header.Get("If-None-Match"), tag) {
+				w.Header().Del("Content-Type")
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.WriteHeader(ew.status)
+			w.Write(ew.buf.Bytes())
+		})
+	}
+}
+
+// etagMatches applies the weak comparison If-None-Match requires: a list
+// entry matches if it is "*" or equals tag ignoring the W/ prefix.
+func etagMatches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter buffers the status and body so the tag can be computed before
+// anything is sent.
+type etagWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+}
+
+func (ew *etagWriter) WriteHeader(status int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	ew.status = status
+}
+
+func (ew *etagWriter) Write(p []byte) (int, error) {
+	ew.wroteHeader = true
+	return ew.buf.Write(p)
+}
@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"SocialMediaAPI/utils"
-	"net"
 	"net/http"
 	"sync"
 	"time"
@@ -90,30 +89,9 @@ func (rl *RateLimiter) allow(ip string) bool {
 	return true
 }
 
-// extractIP returns the client IP from the request.
-// It prefers X-Real-IP, then X-Forwarded-For, then the connection
-// remote address (with port stripped).
+// extractIP returns the client IP used as the rate-limit key.
 func extractIP(r *http.Request) string {
-	// X-Real-IP is typically set by a reverse proxy (nginx, etc.)
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return ip
-	}
-	// X-Forwarded-For can contain a comma-separated chain; take the first.
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// First entry is the original client IP
-		for i := 0; i < len(xff); i++ {
-			if xff[i] == ',' {
-				return xff[:i]
-			}
-		}
-		return xff
-	}
-	// Fall back to the TCP remote address (strip port)
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return ip
+	return utils.ClientIP(r)
 }
 
 // Limit returns gorilla/mux middleware that enforces the rate limit globally.
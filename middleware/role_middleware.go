@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"SocialMediaAPI/utils"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RequireRole returns middleware that only lets through requests from users
+// who currently have the given role. It must run after AuthMiddleware, which
+// places the role stored for the user in the request context.
+func RequireRole(role string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userRole, _ := r.Context().Value("role").(string)
+			if userRole != role {
+				utils.RespondWithError(w, http.StatusForbidden, utils.LocalizeRequest(r, "auth.insufficient_permissions"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
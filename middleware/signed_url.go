@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"SocialMediaAPI/utils"
+	"net/http"
+)
+
+// SignedURL wraps the static uploads handler. Requests carrying a signature
+// (see utils.SignURL) are refused with 403 when it is invalid or expired;
+// requests without one are served as before, since platforms fetch media by
+// its plain URL.
+func SignedURL(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Has("signature") || query.Has("expires") {
+			if err := utils.VerifySignedURL(r.URL.Path, query); err != nil {
+				utils.RespondWithError(w, http.StatusForbidden, err.Error())
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
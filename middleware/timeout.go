@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"SocialMediaAPI/utils"
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Timeout returns gorilla/mux middleware that bounds how long a client waits
+// for a handler. When the limit is hit the client receives a 503 JSON error
+// and anything the handler writes afterwards is discarded. The handler's
+// context is cancelled too, which only stops work done with r.Context() (the
+// OAuth handlers' platform calls). Database queries don't take the context,
+// so the handler keeps running and may still commit writes after the 503.
+//
+// This works like http.TimeoutHandler, which it is modelled on, but answers
+// in the API's JSON error format.
+//
+// exempt lists routes that legitimately run long, as "METHOD /path/template"
+// (e.g. "POST /api/media"), matched against the route mux resolved.
+func Timeout(timeout time.Duration, exempt ...string) mux.MiddlewareFunc {
+	skip := make(map[string]bool, len(exempt))
+	for _, e := range exempt {
+		skip[e] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil {
+				if tpl, err := route.GetPathTemplate(); err == nil && skip[r.Method+" "+tpl] {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{w: w, h: make(http.Header)}
+			done := make(chan struct{})
+			panicChan := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicChan <- p
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicChan:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				dst := w.Header()
+				for k, vv := range tw.h {
+					dst[k] = vv
+				}
+				if tw.code == 0 {
+					tw.code = http.StatusOK
+				}
+				w.WriteHeader(tw.code)
+				w.Write(tw.buf.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				if ctx.Err() == context.DeadlineExceeded {
+					utils.Warnf("request timed out method=%s path=%s timeout=%s", r.Method, r.URL.Path, timeout)
+					utils.RespondWithError(w, http.StatusServiceUnavailable, "Request timed out")
+				}
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers the handler's response until it finishes, so a
+// timeout can still replace it with a clean 503.
+type timeoutWriter struct {
+	w  http.ResponseWriter
+	h  http.Header
+	mu sync.Mutex
+
+	buf      bytes.Buffer
+	code     int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.h }
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
@@ -11,6 +11,7 @@ const (
 	Instagram Platform = "instagram"
 	TikTok    Platform = "tiktok"
 	YouTube   Platform = "youtube"
+	Threads   Platform = "threads"
 )
 
 type PostStatus string
@@ -38,6 +39,15 @@ const (
 	MediaVideo MediaType = "video"
 )
 
+// TwitterMediaCategories are the media_category values accepted by the Twitter
+// media upload endpoint.
+var TwitterMediaCategories = map[string]bool{
+	"tweet_image":   true,
+	"tweet_gif":     true,
+	"tweet_video":   true,
+	"amplify_video": true,
+}
+
 // PrivacyLevel controls post visibility across platforms.
 // Each publisher maps these to platform-specific values.
 type PrivacyLevel string
@@ -49,64 +59,348 @@ const (
 	PrivacyPrivate   PrivacyLevel = "private"   // Visible only to the creator
 )
 
+// Role values for User.Role.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"`
+	Name            string     `json:"name"`
+	Role            string     `json:"role"`
+	TokensRevokedAt *time.Time `json:"-"` // JWTs issued at or before this time are rejected
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type Media struct {
+	ID                   string    `json:"id"`
+	UserID               string    `json:"user_id"`
+	Filename             string    `json:"filename"`
+	Path                 string    `json:"path"`
+	URL                  string    `json:"url"`
+	Type                 MediaType `json:"type"`
+	Size                 int64     `json:"size"`
+	MimeType             string    `json:"mime_type"`
+	AltText              string    `json:"alt_text,omitempty"`               // accessibility description sent to platforms that support it
+	TwitterMediaCategory string    `json:"twitter_media_category,omitempty"` // overrides the media_category picked from the MIME type
+	IsAnimated           bool      `json:"is_animated"`                      // animated image (currently detected for WebP)
+	Width                int       `json:"width,omitempty"`                  // video display width in pixels, read from the MP4 track
+	Height               int       `json:"height,omitempty"`                 // video display height in pixels
+	BitrateKbps          int       `json:"bitrate_kbps,omitempty"`           // average video bitrate
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// MediaUpload is an in-progress resumable (tus) upload. The bytes received so
+// far are kept in a partial file; once Offset reaches Length the file is
+// validated and stored as a regular Media item, whose ID is recorded in MediaID.
+type MediaUpload struct {
+	ID                   string    `json:"id"`
+	UserID               string    `json:"user_id"`
+	Filename             string    `json:"filename"`
+	Length               int64     `json:"length"`
+	Offset               int64     `json:"offset"`
+	AltText              string    `json:"alt_text,omitempty"`
+	TwitterMediaCategory string    `json:"twitter_media_category,omitempty"`
+	MediaID              string    `json:"media_id,omitempty"`
+	ExpiresAt            time.Time `json:"expires_at"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// Complete reports whether every byte of the upload has been received.
+func (u *MediaUpload) Complete() bool {
+	return u.Offset >= u.Length
+}
+
+type Post struct {
+	ID                   string       `json:"id"`
+	UserID               string       `json:"user_id"`
+	Content              string       `json:"content"`
+	PlatformContent      PlatformText `json:"platform_content,omitempty"` // Content to publish instead of Content on a platform, e.g. a shorter tweet
+	PostType             PostType     `json:"post_type"`
+	PrivacyLevel         PrivacyLevel `json:"privacy_level"`
+	IsSponsored          bool         `json:"is_sponsored"`
+	AllowTruncation      bool         `json:"allow_truncation"`                 // Cut content to each platform's limit instead of rejecting the post
+	ThreadingEnabled     bool         `json:"threading_enabled"`                // Publish Twitter content over the limit as a thread instead of rejecting it
+	TestMode             bool         `json:"test_mode"`                        // Publish to TestPlatform only, to check formatting on a test account
+	TestPlatform         Platform     `json:"test_platform,omitempty"`          // Platform a test-mode post goes to; empty = the first of Platforms
+	MadeForKids          *bool        `json:"made_for_kids,omitempty"`          // YouTube audience declaration (COPPA); required for YouTube, nil = not declared
+	CategoryID           string       `json:"category_id,omitempty"`            // YouTube video category; defaults to "22" (People & Blogs)
+	Tags                 []string     `json:"tags,omitempty"`                   // YouTube video tags; at most 500 characters together
+	DefaultLanguage      string       `json:"default_language,omitempty"`       // BCP 47 language of the YouTube title and description
+	DefaultAudioLanguage string       `json:"default_audio_language,omitempty"` // BCP 47 language spoken in the YouTube video
+	Subtitles            string       `json:"subtitles,omitempty"`              // SRT captions attached to the video on Twitter
+	SubtitleLanguage     string       `json:"subtitle_language,omitempty"`      // BCP 47 language of Subtitles; defaults to "en"
+	ThumbnailMediaID     string       `json:"thumbnail_media_id,omitempty"`     // Image used as the Facebook Reel cover and YouTube thumbnail; empty = the platform picks a frame
+	Thumbnail            *Media       `json:"thumbnail,omitempty"`              // Loaded from ThumbnailMediaID
+	MediaIDs             []string     `json:"media_ids,omitempty"`
+	Media                []*Media     `json:"media,omitempty"`
+	Platforms            []Platform   `json:"platforms"`
+	Accounts             AccountIDs   `json:"accounts,omitempty"` // Connected account per platform; platforms left out use their primary account
+	Status               PostStatus   `json:"status"`
+	ScheduledFor         *time.Time   `json:"scheduled_for,omitempty"`
+	PublishedAt          *time.Time   `json:"published_at,omitempty"`
+	RetryCount           int          `json:"retry_count"`             // Automatic retries attempted after a failed scheduled publish
+	NextRetryAt          *time.Time   `json:"next_retry_at,omitempty"` // When the scheduler retries the failed platforms; nil when no retry is pending
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
+}
+
+// AccountIDs maps a platform to the ID of the credential (connected account)
+// a post publishes to there.
+type AccountIDs map[Platform]string
+
+// PlatformText maps a platform to the content a post publishes there in
+// place of its Content.
+type PlatformText map[Platform]string
+
+// ContentFor returns the content post publishes on platform: its
+// PlatformContent override when there is one, Content otherwise.
+func (p *Post) ContentFor(platform Platform) string {
+	if content := p.PlatformContent[platform]; content != "" {
+		return content
+	}
+	return p.Content
+}
+
+// Template is reusable post content with {{name}} placeholders, filled in
+// from the variables sent with POST /api/posts.
+type Template struct {
 	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
+	UserID    string    `json:"user_id"`
 	Name      string    `json:"name"`
+	Content   string    `json:"content"`
+	Variables []string  `json:"variables"` // Placeholders used in Content, derived on load
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type Media struct {
+type PlatformCredentials struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Platform     Platform   `json:"platform"`
+	AccessToken  string     `json:"-"`
+	RefreshToken string     `json:"-"`
+	Secret       string     `json:"-"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	TokenType    string     `json:"token_type"`
+	// Platform-independent identity fields
+	PlatformUserID string `json:"platform_user_id,omitempty"`
+	PlatformPageID string `json:"platform_page_id,omitempty"`
+	// Connected account as shown to the user, e.g. "@handle" / "Page Name"
+	PlatformUsername    string `json:"platform_username,omitempty"`
+	PlatformDisplayName string `json:"platform_display_name,omitempty"`
+	// Scopes the user granted on connect, as reported by the platform; empty
+	// when it reports none (Meta platforms, manually entered credentials)
+	GrantedScopes []string `json:"granted_scopes,omitempty"`
+	// OrganizationID shares the credential with every member of the organization
+	OrganizationID string    `json:"organization_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Organization groups users (e.g. an agency team) that share connected accounts.
+type Organization struct {
 	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Filename  string    `json:"filename"`
-	Path      string    `json:"path"`
-	URL       string    `json:"url"`
-	Type      MediaType `json:"type"`
-	Size      int64     `json:"size"`
-	MimeType  string    `json:"mime_type"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"owner_id"`
+	Role      string    `json:"role,omitempty"` // the requesting user's membership role
 	CreatedAt time.Time `json:"created_at"`
 }
 
-type Post struct {
-	ID           string       `json:"id"`
-	UserID       string       `json:"user_id"`
-	Content      string       `json:"content"`
-	PostType     PostType     `json:"post_type"`
-	PrivacyLevel PrivacyLevel `json:"privacy_level"`
-	IsSponsored  bool         `json:"is_sponsored"`
-	MediaIDs     []string     `json:"media_ids,omitempty"`
-	Media        []*Media     `json:"media,omitempty"`
-	Platforms    []Platform   `json:"platforms"`
-	Status       PostStatus   `json:"status"`
-	ScheduledFor *time.Time   `json:"scheduled_for,omitempty"`
-	PublishedAt  *time.Time   `json:"published_at,omitempty"`
-	CreatedAt    time.Time    `json:"created_at"`
-	UpdatedAt    time.Time    `json:"updated_at"`
+// Membership roles for OrganizationMember.Role.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleMember = "member"
+)
+
+type OrganizationMember struct {
+	OrganizationID string    `json:"organization_id"`
+	UserID         string    `json:"user_id"`
+	Email          string    `json:"email"`
+	Name           string    `json:"name"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
-type PlatformCredentials struct {
-	ID               string    `json:"id"`
-	UserID           string    `json:"user_id"`
-	Platform         Platform  `json:"platform"`
-	AccessToken      string    `json:"-"`
-	RefreshToken     string    `json:"-"`
-	Secret           string    `json:"-"`
-	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
-	TokenType        string    `json:"token_type"`
-	// Platform-independent identity fields
-	PlatformUserID   string    `json:"platform_user_id,omitempty"`
-	PlatformPageID   string    `json:"platform_page_id,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
+// Audit log actions.
+const (
+	AuditPlatformConnected    = "platform.connected"
+	AuditPlatformDisconnected = "platform.disconnected"
+	AuditCredentialsRevoked   = "credentials.revoked"
+	AuditSessionsRevoked      = "sessions.revoked"
+	AuditPostPublished        = "post.published"
+)
+
+// PublishWindowLayout is the "HH:MM" format of the publish window bounds.
+const PublishWindowLayout = "15:04"
+
+// UserSettings holds per-user preferences. When both publish window bounds are
+// set, the scheduler only publishes the user's posts between them (in
+// Timezone); due posts wait for the next window open. A window whose end is
+// before its start spans midnight (e.g. 22:00-06:00).
+type UserSettings struct {
+	UserID                string    `json:"user_id"`
+	PublishWindowStart    string    `json:"publish_window_start"`
+	PublishWindowEnd      string    `json:"publish_window_end"`
+	Timezone              string    `json:"timezone"`                // IANA name, e.g. "Europe/Paris"
+	NotifyPublishFailures bool      `json:"notify_publish_failures"` // Email the user when a scheduled post fails for good
+	UpdatedAt             time.Time `json:"updated_at"`
 }
 
+// HasPublishWindow reports whether a publish window is configured.
+func (s *UserSettings) HasPublishWindow() bool {
+	return s.PublishWindowStart != "" && s.PublishWindowEnd != ""
+}
+
+// InPublishWindow reports whether t falls inside the publish window. It is
+// always true when no window is configured or the settings are invalid, so a
+// bad setting never blocks publishing.
+func (s *UserSettings) InPublishWindow(t time.Time) bool {
+	if !s.HasPublishWindow() {
+		return true
+	}
+	start, err1 := time.Parse(PublishWindowLayout, s.PublishWindowStart)
+	end, err2 := time.Parse(PublishWindowLayout, s.PublishWindowEnd)
+	loc, err3 := time.LoadLocation(s.Timezone)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return true
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+
+	if startMin <= endMin {
+		return minute >= startMin && minute < endMin
+	}
+	// Window spans midnight
+	return minute >= startMin || minute < endMin
+}
+
+// AuditEntry is one row of the audit trail. UserID is the user the action
+// affected; ActorID is who performed it when that differs (e.g. an admin).
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	UserID    string    `json:"user_id"`
+	ActorID   string    `json:"actor_id,omitempty"`
+	Action    string    `json:"action"`
+	Platform  string    `json:"platform,omitempty"`
+	PostID    string    `json:"post_id,omitempty"`
+	Success   bool      `json:"success"`
+	Details   string    `json:"details,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Machine-readable PublishResult.ErrorCode values for failures that require
+// the user to reconnect the platform.
+const (
+	ErrorCodeMissingCredentials = "missing_credentials"
+	ErrorCodeTokenExpired       = "token_expired"
+	ErrorCodeTokenInvalid       = "token_invalid"
+	ErrorCodeInsufficientScope  = "insufficient_permissions"
+)
+
+// PublishResult.ErrorCategory values. Only transient and rate_limited
+// failures are worth retrying.
+const (
+	ErrorCategoryAuth           = "auth"
+	ErrorCategoryRateLimited    = "rate_limited"
+	ErrorCategoryInvalidContent = "invalid_content"
+	ErrorCategoryMediaError     = "media_error"
+	ErrorCategoryTransient      = "transient"
+	ErrorCategoryUnsupported    = "unsupported"
+)
+
 type PublishResult struct {
 	Platform Platform `json:"platform"`
 	Success  bool     `json:"success"`
 	Message  string   `json:"message"`
 	PostID   string   `json:"post_id,omitempty"`
+	// NeedsReauth is set when the token is missing, expired or revoked, so
+	// clients can prompt the user to reconnect the account
+	NeedsReauth   bool   `json:"needs_reauth"`
+	ErrorCode     string `json:"error_code,omitempty"`
+	ErrorCategory string `json:"error_category,omitempty"`
+	// TestMode marks the result of a test-mode publish to a single platform
+	TestMode bool `json:"test_mode,omitempty"`
+}
+
+// PublishAttempt is a PublishResult as stored in publish_results: one row
+// per platform per publish or retry of a post.
+type PublishAttempt struct {
+	ID int `json:"id"`
+	PublishResult
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Retryable reports whether a failed publish may succeed if attempted again.
+func (r PublishResult) Retryable() bool {
+	return r.ErrorCategory == ErrorCategoryTransient || r.ErrorCategory == ErrorCategoryRateLimited
+}
+
+type PublishJobStatus string
+
+const (
+	JobPending PublishJobStatus = "pending" // Waiting for next_attempt_at
+	JobRunning PublishJobStatus = "running" // Being published
+	JobDone    PublishJobStatus = "done"    // Published; never attempted again
+	JobFailed  PublishJobStatus = "failed"  // Last attempt failed and no retry is pending
+)
+
+// PublishJob is the publish of one post to one platform, stored in
+// publish_jobs. Each attempt bumps Attempts; publish_results keeps the
+// outcome of every attempt.
+type PublishJob struct {
+	ID            int              `json:"id"`
+	PostID        string           `json:"post_id"`
+	Platform      Platform         `json:"platform"`
+	Status        PublishJobStatus `json:"status"`
+	Attempts      int              `json:"attempts"`
+	NextAttemptAt time.Time        `json:"next_attempt_at"`
+	LastError     string           `json:"last_error,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// PostPlatformStatus is the outcome of publishing a post to one platform,
+// stored in post_platform_status: Status is "published" once any attempt
+// succeeded, with the post's ExternalID there, and "failed" otherwise.
+type PostPlatformStatus struct {
+	PostID     string     `json:"post_id"`
+	Platform   Platform   `json:"platform"`
+	Status     PostStatus `json:"status"`
+	ExternalID string     `json:"external_id,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// PlatformState is the normalized state of a published post on the platform.
+type PlatformState string
+
+const (
+	PlatformStateProcessing   PlatformState = "processing"    // Uploaded, still being processed or reviewed
+	PlatformStateLive         PlatformState = "live"          // Visible to its intended audience
+	PlatformStatePrivate      PlatformState = "private"       // Processed but only visible to the owner
+	PlatformStateFailed       PlatformState = "failed"        // Processing failed or the platform rejected it
+	PlatformStateRemoved      PlatformState = "removed"       // Deleted, or no longer found on the platform
+	PlatformStateNotPublished PlatformState = "not_published" // No successful publish recorded
+	PlatformStateUnsupported  PlatformState = "unsupported"   // The platform's status cannot be queried
+	PlatformStateUnknown      PlatformState = "unknown"       // The status query failed
+)
+
+// PlatformPostStatus is the live state of a post on one platform, as
+// reported by GET /api/posts/{id}/platform-status.
+type PlatformPostStatus struct {
+	Platform       Platform      `json:"platform"`
+	ExternalPostID string        `json:"external_post_id,omitempty"`
+	State          PlatformState `json:"state"`
+	Detail         string        `json:"detail,omitempty"` // The platform's own status, or why the query failed
+	URL            string        `json:"url,omitempty"`
 }
 
 type LoginRequest struct {
@@ -120,6 +414,37 @@ type RegisterRequest struct {
 	Name     string `json:"name"`
 }
 
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// PasswordReset is a single-use password reset token. Only the SHA-256 hash
+// of the token is stored; the token itself is only ever sent to the user.
+type PasswordReset struct {
+	ID        string
+	UserID    string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// OAuthState is a pending OAuth authorization, keyed by the random state
+// token passed through the provider. CodeVerifier is only set for PKCE flows.
+type OAuthState struct {
+	State        string
+	UserID       string
+	Platform     string
+	CodeVerifier string
+	CreatedAt    time.Time
+	UsedAt       *time.Time
+}
+
 type AuthResponse struct {
 	Token string `json:"token"`
 	User  User   `json:"user"`
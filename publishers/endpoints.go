@@ -0,0 +1,26 @@
+package publishers
+
+import "strings"
+
+// Production API base URLs. Each publisher accepts its own base so tests can
+// point it at an httptest.Server instead of the real platform.
+const (
+	DefaultFacebookGraphBase  = "https://graph.facebook.com"
+	DefaultInstagramGraphBase = "https://graph.instagram.com"
+	DefaultLinkedInAPIBase    = "https://api.linkedin.com"
+	DefaultThreadsGraphBase   = "https://graph.threads.net"
+	DefaultTikTokAPIBase      = "https://open.tiktokapis.com"
+	DefaultTwitterAPIBase     = "https://api.x.com"
+	DefaultTwitterUploadBase  = "https://upload.x.com"
+	DefaultYouTubeAPIBase     = "https://www.googleapis.com"
+	DefaultGoogleOAuthBase    = "https://oauth2.googleapis.com"
+)
+
+// baseURLOrDefault returns base without a trailing slash, or def when base is empty.
+func baseURLOrDefault(base, def string) string {
+	base = strings.TrimRight(base, "/")
+	if base == "" {
+		return def
+	}
+	return base
+}
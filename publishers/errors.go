@@ -0,0 +1,130 @@
+package publishers
+
+import (
+	"SocialMediaAPI/models"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+)
+
+// PublishError classifies a platform failure so clients and the retry logic
+// can react to it: auth failures need the user to reconnect, transient and
+// rate-limited failures are worth retrying, the rest are not.
+type PublishError struct {
+	Category string
+	// Code is a machine-readable reason, set for auth failures
+	Code string
+	Err  error
+}
+
+func (e *PublishError) Error() string { return e.Err.Error() }
+
+func (e *PublishError) Unwrap() error { return e.Err }
+
+func newPublishError(category string, err error) error {
+	return &PublishError{Category: category, Err: err}
+}
+
+func newAuthError(code string, err error) error {
+	return &PublishError{Category: models.ErrorCategoryAuth, Code: code, Err: err}
+}
+
+// httpError classifies err by the HTTP status the platform answered with.
+func httpError(statusCode int, err error) error {
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return newAuthError(models.ErrorCodeTokenInvalid, err)
+	case statusCode == http.StatusTooManyRequests:
+		return newPublishError(models.ErrorCategoryRateLimited, err)
+	case statusCode == http.StatusRequestEntityTooLarge || statusCode == http.StatusUnsupportedMediaType:
+		return newPublishError(models.ErrorCategoryMediaError, err)
+	case statusCode >= 500:
+		return newPublishError(models.ErrorCategoryTransient, err)
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusForbidden || statusCode == http.StatusUnprocessableEntity:
+		return newPublishError(models.ErrorCategoryInvalidContent, err)
+	}
+	return err
+}
+
+// metaError classifies err by its Graph API (Facebook/Instagram/Threads) error code.
+func metaError(code int, err error) error {
+	switch code {
+	case 102, 190, 192:
+		// 102: session expired, 190: invalid/expired OAuth token, 192: invalid token signature
+		return newAuthError(models.ErrorCodeTokenInvalid, err)
+	case 10, 200:
+		// Permission denied / permission not granted for this token
+		return newAuthError(models.ErrorCodeInsufficientScope, err)
+	case 4, 17, 32, 613:
+		// Application, user, page and custom rate limits
+		return newPublishError(models.ErrorCategoryRateLimited, err)
+	case 1, 2:
+		// Unknown error / temporary service error
+		return newPublishError(models.ErrorCategoryTransient, err)
+	case 324, 352, 9004, 36003:
+		// Missing or unreadable upload, unsupported video format, media fetch
+		// failed, invalid aspect ratio
+		return newPublishError(models.ErrorCategoryMediaError, err)
+	case 100, 368, 506:
+		// Invalid parameter, blocked as abusive, duplicate post
+		return newPublishError(models.ErrorCategoryInvalidContent, err)
+	}
+	return err
+}
+
+// errorCategory returns the category of err. Errors that were not classified
+// by a publisher fall back to network failures (transient) and local file
+// errors (media_error).
+func errorCategory(err error) (category, code string) {
+	var pubErr *PublishError
+	if errors.As(err, &pubErr) {
+		return pubErr.Category, pubErr.Code
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return models.ErrorCategoryTransient, ""
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return models.ErrorCategoryMediaError, ""
+	}
+	return "", ""
+}
+
+// failureResult builds a failed PublishResult categorized from err, flagging
+// auth failures so the client can prompt the user to reconnect.
+func failureResult(platform models.Platform, message string, err error) models.PublishResult {
+	category, code := errorCategory(err)
+	return models.PublishResult{
+		Platform:      platform,
+		Success:       false,
+		Message:       message,
+		NeedsReauth:   category == models.ErrorCategoryAuth,
+		ErrorCode:     code,
+		ErrorCategory: category,
+	}
+}
+
+// errorResult builds a failed PublishResult for a failure detected before
+// calling the platform (unsupported post type, missing attachment, ...).
+func errorResult(platform models.Platform, category, message string) models.PublishResult {
+	return models.PublishResult{
+		Platform:      platform,
+		Success:       false,
+		Message:       message,
+		ErrorCategory: category,
+	}
+}
+
+// reauthResult builds a failed PublishResult that asks the user to reconnect.
+func reauthResult(platform models.Platform, code, message string) models.PublishResult {
+	return models.PublishResult{
+		Platform:      platform,
+		Success:       false,
+		Message:       message,
+		NeedsReauth:   true,
+		ErrorCode:     code,
+		ErrorCategory: models.ErrorCategoryAuth,
+	}
+}
@@ -12,12 +12,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
 type FacebookPublisher struct{
-	client *http.Client
+	client  *http.Client
+	baseURL string
 }
 
 type FacebookPageResponse struct {
@@ -50,11 +52,7 @@ func (f *FacebookPublisher) Publish(post *models.Post, cred *models.PlatformCred
 
 	if cred == nil || cred.AccessToken == "" {
 		utils.Warnf("facebook publish missing credentials post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.Facebook,
-			Success:  false,
-			Message:  "Missing Facebook credentials",
-		}
+		return reauthResult(models.Facebook, models.ErrorCodeMissingCredentials, "Missing Facebook credentials")
 	}
 
 	// Check if token is expired
@@ -64,24 +62,20 @@ func (f *FacebookPublisher) Publish(post *models.Post, cred *models.PlatformCred
 		// Attempt to refresh the token
 		if err := tokenValidator.RefreshFacebookToken(cred); err != nil {
 			utils.Errorf("facebook token refresh failed post_id=%s user_id=%s err=%v", post.ID, post.UserID, err)
-			return models.PublishResult{
-				Platform: models.Facebook,
-				Success:  false,
-				Message:  fmt.Sprintf("Facebook token has expired and cannot be refreshed: %v", err),
-			}
+			return reauthResult(models.Facebook, models.ErrorCodeTokenExpired,
+				fmt.Sprintf("Facebook token has expired and cannot be refreshed: %v", err))
 		}
 		utils.Infof("facebook token refresh succeeded post_id=%s user_id=%s", post.ID, post.UserID)
 	}
 
+	// Retries of every call below share one wait budget
+	budget := newRetryBudget()
+
 	// Get Page Access Token first
-	pageAccessToken, pageID, err := f.getPageAccessToken(cred.AccessToken)
+	pageAccessToken, pageID, err := f.getPageAccessToken(cred.AccessToken, cred.PlatformPageID)
 	if err != nil {
 		utils.Errorf("facebook page token lookup failed post_id=%s user_id=%s err=%v", post.ID, post.UserID, err)
-		return models.PublishResult{
-			Platform: models.Facebook,
-			Success:  false,
-			Message:  fmt.Sprintf("Error getting page access token: %v", err),
-		}
+		return failureResult(models.Facebook, fmt.Sprintf("Error getting page access token: %v", err), err)
 	}
 	utils.Debugf("facebook page token lookup succeeded post_id=%s page_id=%s", post.ID, pageID)
 
@@ -91,11 +85,7 @@ func (f *FacebookPublisher) Publish(post *models.Post, cred *models.PlatformCred
 		postID, err := f.publishReel(post, pageAccessToken, pageID)
 		if err != nil {
 			utils.Errorf("facebook reel publish failed post_id=%s page_id=%s err=%v", post.ID, pageID, err)
-			return models.PublishResult{
-				Platform: models.Facebook,
-				Success:  false,
-				Message:  fmt.Sprintf("Error publishing Facebook Reel: %v", err),
-			}
+			return failureResult(models.Facebook, fmt.Sprintf("Error publishing Facebook Reel: %v", err), err)
 		}
 		utils.Infof("facebook reel publish succeeded post_id=%s page_id=%s external_post_id=%s", post.ID, pageID, postID)
 		return models.PublishResult{
@@ -109,14 +99,10 @@ func (f *FacebookPublisher) Publish(post *models.Post, cred *models.PlatformCred
 	// Story posts → publish as Facebook Story
 	if post.PostType == models.PostTypeStory {
 		utils.Infof("facebook publish mode=story post_id=%s page_id=%s", post.ID, pageID)
-		postID, err := f.publishStory(post, pageAccessToken, pageID)
+		postID, err := f.publishStory(post, pageAccessToken, pageID, budget)
 		if err != nil {
 			utils.Errorf("facebook story publish failed post_id=%s page_id=%s err=%v", post.ID, pageID, err)
-			return models.PublishResult{
-				Platform: models.Facebook,
-				Success:  false,
-				Message:  fmt.Sprintf("Error publishing Facebook Story: %v", err),
-			}
+			return failureResult(models.Facebook, fmt.Sprintf("Error publishing Facebook Story: %v", err), err)
 		}
 		utils.Infof("facebook story publish succeeded post_id=%s page_id=%s external_post_id=%s", post.ID, pageID, postID)
 		return models.PublishResult{
@@ -131,19 +117,15 @@ func (f *FacebookPublisher) Publish(post *models.Post, cred *models.PlatformCred
 	var postID string
 	if len(post.Media) > 0 {
 		utils.Infof("facebook publish mode=media post_id=%s page_id=%s media_count=%d", post.ID, pageID, len(post.Media))
-		postID, err = f.publishWithMedia(post, pageAccessToken, pageID)
+		postID, err = f.publishWithMedia(post, pageAccessToken, pageID, budget)
 	} else {
 		utils.Infof("facebook publish mode=text post_id=%s page_id=%s", post.ID, pageID)
-		postID, err = f.publishTextOnly(post, pageAccessToken, pageID)
+		postID, err = f.publishTextOnly(post, pageAccessToken, pageID, budget)
 	}
 
 	if err != nil {
 		utils.Errorf("facebook publish failed post_id=%s page_id=%s err=%v", post.ID, pageID, err)
-		return models.PublishResult{
-			Platform: models.Facebook,
-			Success:  false,
-			Message:  fmt.Sprintf("Error publishing to Facebook: %v", err),
-		}
+		return failureResult(models.Facebook, fmt.Sprintf("Error publishing to Facebook: %v", err), err)
 	}
 
 	utils.Infof("facebook publish succeeded post_id=%s page_id=%s external_post_id=%s", post.ID, pageID, postID)
@@ -156,25 +138,33 @@ func (f *FacebookPublisher) Publish(post *models.Post, cred *models.PlatformCred
 	}
 }
 
-// NewFacebookPublisher creates a FacebookPublisher with an injectable http.Client.
-// If nil is passed, a default client with a sensible timeout is used.
-func NewFacebookPublisher(client *http.Client) *FacebookPublisher {
+// NewFacebookPublisher creates a FacebookPublisher with an injectable http.Client
+// and Graph API base URL. If nil is passed, a default client with a sensible
+// timeout is used; an empty baseURL uses the production Graph API.
+func NewFacebookPublisher(client *http.Client, baseURL string) *FacebookPublisher {
 	if client == nil {
-		client = &http.Client{Timeout: 30 * time.Second}
+		client = utils.NewHTTPClient(30 * time.Second)
 	}
-	return &FacebookPublisher{client: client}
+	return &FacebookPublisher{client: client, baseURL: baseURLOrDefault(baseURL, DefaultFacebookGraphBase)}
 }
 
 func (f *FacebookPublisher) httpClient() *http.Client {
 	if f.client == nil {
-		f.client = &http.Client{Timeout: 30 * time.Second}
+		f.client = utils.NewHTTPClient(30 * time.Second)
 	}
 	return f.client
 }
 
-func (f *FacebookPublisher) getPageAccessToken(userAccessToken string) (string, string, error) {
+// graphBase returns the Graph API base URL, defaulting to production.
+func (f *FacebookPublisher) graphBase() string {
+	return baseURLOrDefault(f.baseURL, DefaultFacebookGraphBase)
+}
+
+// getPageAccessToken returns the access token and ID of the connected Page:
+// the one with pageID, or the first Page of the account when pageID is empty.
+func (f *FacebookPublisher) getPageAccessToken(userAccessToken, pageID string) (string, string, error) {
 	cfg := config.Load()
-	url := fmt.Sprintf("https://graph.facebook.com/%s/me/accounts", cfg.FacebookVersion)
+	url := fmt.Sprintf("%s/%s/me/accounts", f.graphBase(), cfg.FacebookVersion)
 	utils.Debugf("facebook requesting page access token")
 
 	req, err := http.NewRequest("GET", url, nil)
@@ -198,10 +188,12 @@ func (f *FacebookPublisher) getPageAccessToken(userAccessToken string) (string,
 		// Check for token expiration error
 		tokenValidator := utils.NewTokenValidator()
 		if tokenValidator.IsFacebookTokenExpiredError(body) {
-			return "", "", fmt.Errorf("access token has expired (error code: %d)", fbError.Error.Code)
+			return "", "", newAuthError(models.ErrorCodeTokenExpired,
+				fmt.Errorf("access token has expired (error code: %d)", fbError.Error.Code))
 		}
 		
-		return "", "", fmt.Errorf("Facebook API error: %s (code: %d)", fbError.Error.Message, fbError.Error.Code)
+		return "", "", metaError(fbError.Error.Code,
+			fmt.Errorf("Facebook API error: %s (code: %d)", fbError.Error.Message, fbError.Error.Code))
 	}
 
 	var pageResp FacebookPageResponse
@@ -214,31 +206,45 @@ func (f *FacebookPublisher) getPageAccessToken(userAccessToken string) (string,
 		return "", "", fmt.Errorf("no Facebook pages found for this account")
 	}
 
-	// Use the first page
 	page := pageResp.Data[0]
+	if pageID != "" {
+		found := false
+		for _, p := range pageResp.Data {
+			if p.ID == pageID {
+				page, found = p, true
+				break
+			}
+		}
+		if !found {
+			return "", "", newAuthError(models.ErrorCodeMissingCredentials,
+				fmt.Errorf("Facebook page %s is no longer available to this account. Reconnect it", pageID))
+		}
+	}
 	utils.Debugf("facebook selected page page_id=%s page_name=%s", page.ID, page.Name)
 	return page.AccessToken, page.ID, nil
 }
 
-func (f *FacebookPublisher) publishTextOnly(post *models.Post, pageAccessToken, pageID string) (string, error) {
+func (f *FacebookPublisher) publishTextOnly(post *models.Post, pageAccessToken, pageID string, budget *retryBudget) (string, error) {
 	cfg := config.Load()
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/feed", cfg.FacebookVersion, pageID)
+	url := fmt.Sprintf("%s/%s/%s/feed", f.graphBase(), cfg.FacebookVersion, pageID)
 	utils.Debugf("facebook posting text content post_id=%s page_id=%s", post.ID, pageID)
 
 	payload := map[string]interface{}{
-		"message":            post.Content,
+		"message":            caption(post, models.Facebook),
 		"is_branded_content": post.IsSponsored,
 	}
 
 	jsonData, _ := json.Marshal(payload)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+pageAccessToken)
-	resp, err := f.httpClient().Do(req)
+	resp, err := retryDo(f.httpClient(), func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+pageAccessToken)
+		return req, nil
+	}, cfg.PlatformRetryAttempts, budget)
 	if err != nil {
 		return "", err
 	}
@@ -250,7 +256,7 @@ func (f *FacebookPublisher) publishTextOnly(post *models.Post, pageAccessToken,
 		var fbError FacebookErrorResponse
 		json.Unmarshal(body, &fbError)
 		utils.Errorf("facebook text post API error post_id=%s page_id=%s status=%d message=%s", post.ID, pageID, resp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook API error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook API error: %s", fbError.Error.Message))
 	}
 
 	var postResp FacebookPostResponse
@@ -261,28 +267,28 @@ func (f *FacebookPublisher) publishTextOnly(post *models.Post, pageAccessToken,
 	return postResp.ID, nil
 }
 
-func (f *FacebookPublisher) publishWithMedia(post *models.Post, pageAccessToken, pageID string) (string, error) {
+func (f *FacebookPublisher) publishWithMedia(post *models.Post, pageAccessToken, pageID string, budget *retryBudget) (string, error) {
 	utils.Debugf("facebook publishWithMedia post_id=%s page_id=%s media_count=%d", post.ID, pageID, len(post.Media))
 	// For multiple images, we need to upload them first and then create a post
 	if len(post.Media) == 1 && post.Media[0].Type == models.MediaImage {
 		// Single image - can post directly
 		utils.Debugf("facebook media flow single image post_id=%s page_id=%s", post.ID, pageID)
-		return f.publishSinglePhoto(post, pageAccessToken, pageID)
+		return f.publishSinglePhoto(post, pageAccessToken, pageID, budget)
 	} else if len(post.Media) > 1 {
 		// Multiple images - need to upload first then create album post
 		utils.Debugf("facebook media flow multiple images post_id=%s page_id=%s count=%d", post.ID, pageID, len(post.Media))
-		return f.publishMultiplePhotos(post, pageAccessToken, pageID)
+		return f.publishMultiplePhotos(post, pageAccessToken, pageID, budget)
 	}
 
 	return "", fmt.Errorf("unsupported media configuration")
 }
 
-func (f *FacebookPublisher) publishSinglePhoto(post *models.Post, pageAccessToken, pageID string) (string, error) {
+func (f *FacebookPublisher) publishSinglePhoto(post *models.Post, pageAccessToken, pageID string, budget *retryBudget) (string, error) {
 	media := post.Media[0]
-	return f.uploadPhoto(media, pageAccessToken, pageID, true, post.Content)
+	return f.uploadPhoto(media, pageAccessToken, pageID, true, caption(post, models.Facebook), budget)
 }
 
-func (f *FacebookPublisher) publishMultiplePhotos(post *models.Post, pageAccessToken, pageID string) (string, error) {
+func (f *FacebookPublisher) publishMultiplePhotos(post *models.Post, pageAccessToken, pageID string, budget *retryBudget) (string, error) {
 	utils.Infof("facebook uploading multiple photos post_id=%s page_id=%s", post.ID, pageID)
 	// Step 1: Upload all photos without publishing (bounded concurrency)
 	photoIDs := []string{}
@@ -302,7 +308,7 @@ func (f *FacebookPublisher) publishMultiplePhotos(post *models.Post, pageAccessT
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			photoID, err := f.uploadPhoto(m, pageAccessToken, pageID, false, "")
+			photoID, err := f.uploadPhoto(m, pageAccessToken, pageID, false, "", budget)
 			if err != nil {
 				utils.Errorf("facebook photo upload failed post_id=%s page_id=%s media_id=%s err=%v", post.ID, pageID, m.ID, err)
 				select {
@@ -327,7 +333,7 @@ func (f *FacebookPublisher) publishMultiplePhotos(post *models.Post, pageAccessT
 
 	// Step 2: Create a post with all photos
 	cfg := config.Load()
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/feed", cfg.FacebookVersion, pageID)
+	url := fmt.Sprintf("%s/%s/%s/feed", f.graphBase(), cfg.FacebookVersion, pageID)
 
 	// Build attached_media parameter
 	attachedMedia := []map[string]string{}
@@ -338,20 +344,22 @@ func (f *FacebookPublisher) publishMultiplePhotos(post *models.Post, pageAccessT
 	}
 
 	payload := map[string]interface{}{
-		"message":            post.Content,
+		"message":            caption(post, models.Facebook),
 		"attached_media":     attachedMedia,
 		"is_branded_content": post.IsSponsored,
 	}
 
 	jsonData, _ := json.Marshal(payload)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+pageAccessToken)
-	resp, err := f.httpClient().Do(req)
+	resp, err := retryDo(f.httpClient(), func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+pageAccessToken)
+		return req, nil
+	}, cfg.PlatformRetryAttempts, budget)
 	if err != nil {
 		return "", err
 	}
@@ -363,7 +371,7 @@ func (f *FacebookPublisher) publishMultiplePhotos(post *models.Post, pageAccessT
 		var fbError FacebookErrorResponse
 		json.Unmarshal(body, &fbError)
 		utils.Errorf("facebook multi-photo feed post API error post_id=%s page_id=%s status=%d message=%s", post.ID, pageID, resp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook API error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook API error: %s", fbError.Error.Message))
 	}
 
 	var postResp FacebookPostResponse
@@ -375,13 +383,14 @@ func (f *FacebookPublisher) publishMultiplePhotos(post *models.Post, pageAccessT
 }
 
 func (f *FacebookPublisher) uploadPhotoUnpublished(media *models.Media, pageAccessToken, pageID string) (string, error) {
-	return f.uploadPhoto(media, pageAccessToken, pageID, false, "")
+	return f.uploadPhoto(media, pageAccessToken, pageID, false, "", nil)
 }
 
 // uploadPhoto uploads a photo to the page. If published is false the photo will be uploaded unpublished.
-func (f *FacebookPublisher) uploadPhoto(media *models.Media, pageAccessToken, pageID string, published bool, message string) (string, error) {
+// Retries wait within budget.
+func (f *FacebookPublisher) uploadPhoto(media *models.Media, pageAccessToken, pageID string, published bool, message string, budget *retryBudget) (string, error) {
 	cfg := config.Load()
-	url := fmt.Sprintf("https://graph.facebook.com/%s/%s/photos", cfg.FacebookVersion, pageID)
+	url := fmt.Sprintf("%s/%s/%s/photos", f.graphBase(), cfg.FacebookVersion, pageID)
 	utils.Debugf("facebook upload photo start page_id=%s media_id=%s published=%t", pageID, media.ID, published)
 
 	body := &bytes.Buffer{}
@@ -412,14 +421,15 @@ func (f *FacebookPublisher) uploadPhoto(media *models.Media, pageAccessToken, pa
 
 	writer.Close()
 
-	req, err := http.NewRequest("POST", url, body)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Authorization", "Bearer "+pageAccessToken)
-
-	resp, err := f.httpClient().Do(req)
+	resp, err := retryDo(f.httpClient(), func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+pageAccessToken)
+		return req, nil
+	}, cfg.PlatformRetryAttempts, budget)
 	if err != nil {
 		return "", err
 	}
@@ -431,7 +441,7 @@ func (f *FacebookPublisher) uploadPhoto(media *models.Media, pageAccessToken, pa
 		var fbError FacebookErrorResponse
 		json.Unmarshal(respBody, &fbError)
 		utils.Errorf("facebook upload photo API error page_id=%s media_id=%s status=%d message=%s", pageID, media.ID, resp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook API error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook API error: %s", fbError.Error.Message))
 	}
 
 	var photoResp FacebookPhotoResponse
@@ -463,7 +473,7 @@ func (f *FacebookPublisher) publishReel(post *models.Post, pageAccessToken, page
 	utils.Infof("facebook reel upload start post_id=%s page_id=%s media_id=%s", post.ID, pageID, videoMedia.ID)
 
 	// Step 1: Initialize the video upload
-	initURL := fmt.Sprintf("https://graph.facebook.com/%s/%s/video_reels", cfg.FacebookVersion, pageID)
+	initURL := fmt.Sprintf("%s/%s/%s/video_reels", f.graphBase(), cfg.FacebookVersion, pageID)
 
 	initPayload := map[string]string{
 		"upload_phase": "start",
@@ -488,7 +498,7 @@ func (f *FacebookPublisher) publishReel(post *models.Post, pageAccessToken, page
 		var fbError FacebookErrorResponse
 		json.Unmarshal(body, &fbError)
 		utils.Errorf("facebook reel init API error post_id=%s page_id=%s status=%d message=%s", post.ID, pageID, resp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook Reel init error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook Reel init error: %s", fbError.Error.Message))
 	}
 
 	var initResp struct {
@@ -531,19 +541,28 @@ func (f *FacebookPublisher) publishReel(post *models.Post, pageAccessToken, page
 	uploadBody, _ := io.ReadAll(uploadResp.Body)
 	if uploadResp.StatusCode != http.StatusOK {
 		utils.Errorf("facebook reel upload API error post_id=%s status=%d body=%s", post.ID, uploadResp.StatusCode, string(uploadBody))
-		return "", fmt.Errorf("Facebook Reel upload error: %s", string(uploadBody))
+		return "", httpError(uploadResp.StatusCode, fmt.Errorf("Facebook Reel upload error: %s", string(uploadBody)))
 	}
 	utils.Debugf("facebook reel upload success post_id=%s video_id=%s", post.ID, initResp.VideoID)
 
+	// Set the custom cover before publishing, so the Reel never goes out
+	// with an auto-selected frame
+	if post.Thumbnail != nil {
+		if err := f.uploadVideoThumbnail(post.Thumbnail, initResp.VideoID, pageAccessToken); err != nil {
+			utils.Errorf("facebook reel thumbnail upload failed post_id=%s video_id=%s err=%v", post.ID, initResp.VideoID, err)
+			return "", err
+		}
+	}
+
 	// Step 3: Publish (finish) the reel
-	finishURL := fmt.Sprintf("https://graph.facebook.com/%s/%s/video_reels", cfg.FacebookVersion, pageID)
+	finishURL := fmt.Sprintf("%s/%s/%s/video_reels", f.graphBase(), cfg.FacebookVersion, pageID)
 
 	finishPayload := map[string]interface{}{
-		"upload_phase":     "finish",
-		"video_id":         initResp.VideoID,
-		"title":            post.Content,
-		"description":      post.Content,
-		"video_state":      "PUBLISHED",
+		"upload_phase":       "finish",
+		"video_id":           initResp.VideoID,
+		"title":              reelTitle(post.ContentFor(models.Facebook), cfg.FacebookReelTitleMaxLength),
+		"description":        caption(post, models.Facebook),
+		"video_state":        "PUBLISHED",
 		"is_branded_content": post.IsSponsored,
 	}
 	jsonData, _ = json.Marshal(finishPayload)
@@ -566,7 +585,7 @@ func (f *FacebookPublisher) publishReel(post *models.Post, pageAccessToken, page
 		var fbError FacebookErrorResponse
 		json.Unmarshal(finishBody, &fbError)
 		utils.Errorf("facebook reel finish API error post_id=%s status=%d message=%s", post.ID, finishResp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook Reel publish error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook Reel publish error: %s", fbError.Error.Message))
 	}
 
 	var finishResult struct {
@@ -578,9 +597,67 @@ func (f *FacebookPublisher) publishReel(post *models.Post, pageAccessToken, page
 	return initResp.VideoID, nil
 }
 
+// uploadVideoThumbnail sets media as the preferred thumbnail (cover) of an
+// uploaded video via POST /{video-id}/thumbnails.
+func (f *FacebookPublisher) uploadVideoThumbnail(media *models.Media, videoID, pageAccessToken string) error {
+	cfg := config.Load()
+	url := fmt.Sprintf("%s/%s/%s/thumbnails", f.graphBase(), cfg.FacebookVersion, videoID)
+	utils.Debugf("facebook upload thumbnail start video_id=%s media_id=%s", videoID, media.ID)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	file, err := os.Open(media.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open thumbnail file: %w", err)
+	}
+	defer file.Close()
+
+	part, err := writer.CreateFormFile("source", filepath.Base(media.Path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	writer.WriteField("is_preferred", "true")
+	writer.Close()
+
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+pageAccessToken)
+
+	resp, err := f.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var fbError FacebookErrorResponse
+		json.Unmarshal(respBody, &fbError)
+		return metaError(fbError.Error.Code, fmt.Errorf("Facebook Reel thumbnail error: %s", fbError.Error.Message))
+	}
+
+	utils.Debugf("facebook upload thumbnail success video_id=%s media_id=%s", videoID, media.ID)
+	return nil
+}
+
+// reelTitle returns the first line of content, cut to maxLength characters,
+// as the Reel title. The full caption goes in the description, so a long
+// caption no longer ends up as the title too.
+func reelTitle(content string, maxLength int) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	return utils.TruncateRunes(strings.TrimSpace(title), maxLength)
+}
+
 // publishStory publishes a photo or video as a Facebook Page Story.
 // Uses the Page Stories API: POST /{page-id}/stories with either a photo_id or video_id.
-func (f *FacebookPublisher) publishStory(post *models.Post, pageAccessToken, pageID string) (string, error) {
+func (f *FacebookPublisher) publishStory(post *models.Post, pageAccessToken, pageID string, budget *retryBudget) (string, error) {
 	cfg := config.Load()
 	utils.Infof("facebook story publish start post_id=%s page_id=%s media_count=%d", post.ID, pageID, len(post.Media))
 
@@ -591,7 +668,7 @@ func (f *FacebookPublisher) publishStory(post *models.Post, pageAccessToken, pag
 	media := post.Media[0]
 
 	if media.Type == models.MediaImage {
-		return f.publishStoryPhoto(post, media, pageAccessToken, pageID, cfg)
+		return f.publishStoryPhoto(post, media, pageAccessToken, pageID, cfg, budget)
 	} else if media.Type == models.MediaVideo {
 		return f.publishStoryVideo(post, media, pageAccessToken, pageID, cfg)
 	}
@@ -600,18 +677,18 @@ func (f *FacebookPublisher) publishStory(post *models.Post, pageAccessToken, pag
 }
 
 // publishStoryPhoto uploads a photo as unpublished, then creates a photo story.
-func (f *FacebookPublisher) publishStoryPhoto(post *models.Post, media *models.Media, pageAccessToken, pageID string, cfg *config.Config) (string, error) {
+func (f *FacebookPublisher) publishStoryPhoto(post *models.Post, media *models.Media, pageAccessToken, pageID string, cfg *config.Config, budget *retryBudget) (string, error) {
 	utils.Debugf("facebook story photo upload start post_id=%s page_id=%s media_id=%s", post.ID, pageID, media.ID)
 
 	// Upload the photo as unpublished first
-	photoID, err := f.uploadPhoto(media, pageAccessToken, pageID, false, "")
+	photoID, err := f.uploadPhoto(media, pageAccessToken, pageID, false, "", budget)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload photo for story: %w", err)
 	}
 	utils.Debugf("facebook story photo uploaded post_id=%s photo_id=%s", post.ID, photoID)
 
 	// Create the story with the uploaded photo
-	storyURL := fmt.Sprintf("https://graph.facebook.com/%s/%s/photo_stories", cfg.FacebookVersion, pageID)
+	storyURL := fmt.Sprintf("%s/%s/%s/photo_stories", f.graphBase(), cfg.FacebookVersion, pageID)
 
 	payload := map[string]string{
 		"photo_id": photoID,
@@ -636,7 +713,7 @@ func (f *FacebookPublisher) publishStoryPhoto(post *models.Post, media *models.M
 		var fbError FacebookErrorResponse
 		json.Unmarshal(body, &fbError)
 		utils.Errorf("facebook story photo API error post_id=%s status=%d message=%s", post.ID, resp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook Story photo error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook Story photo error: %s", fbError.Error.Message))
 	}
 
 	var storyResp struct {
@@ -656,7 +733,7 @@ func (f *FacebookPublisher) publishStoryVideo(post *models.Post, media *models.M
 	utils.Debugf("facebook story video upload start post_id=%s page_id=%s media_id=%s", post.ID, pageID, media.ID)
 
 	// Step 1: Initialize the video upload via the video_stories endpoint
-	initURL := fmt.Sprintf("https://graph.facebook.com/%s/%s/video_stories", cfg.FacebookVersion, pageID)
+	initURL := fmt.Sprintf("%s/%s/%s/video_stories", f.graphBase(), cfg.FacebookVersion, pageID)
 
 	initPayload := map[string]string{
 		"upload_phase": "start",
@@ -681,7 +758,7 @@ func (f *FacebookPublisher) publishStoryVideo(post *models.Post, media *models.M
 		var fbError FacebookErrorResponse
 		json.Unmarshal(body, &fbError)
 		utils.Errorf("facebook story video init API error post_id=%s status=%d message=%s", post.ID, resp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook Story video init error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook Story video init error: %s", fbError.Error.Message))
 	}
 
 	var initResp struct {
@@ -723,12 +800,12 @@ func (f *FacebookPublisher) publishStoryVideo(post *models.Post, media *models.M
 	uploadBody, _ := io.ReadAll(uploadResp.Body)
 	if uploadResp.StatusCode != http.StatusOK {
 		utils.Errorf("facebook story video upload API error post_id=%s status=%d body=%s", post.ID, uploadResp.StatusCode, string(uploadBody))
-		return "", fmt.Errorf("Facebook Story video upload error: %s", string(uploadBody))
+		return "", httpError(uploadResp.StatusCode, fmt.Errorf("Facebook Story video upload error: %s", string(uploadBody)))
 	}
 	utils.Debugf("facebook story video upload success post_id=%s video_id=%s", post.ID, initResp.VideoID)
 
 	// Step 3: Finish publishing the story
-	finishURL := fmt.Sprintf("https://graph.facebook.com/%s/%s/video_stories", cfg.FacebookVersion, pageID)
+	finishURL := fmt.Sprintf("%s/%s/%s/video_stories", f.graphBase(), cfg.FacebookVersion, pageID)
 
 	finishPayload := map[string]string{
 		"upload_phase": "finish",
@@ -754,7 +831,7 @@ func (f *FacebookPublisher) publishStoryVideo(post *models.Post, media *models.M
 		var fbError FacebookErrorResponse
 		json.Unmarshal(finishBody, &fbError)
 		utils.Errorf("facebook story video finish API error post_id=%s status=%d message=%s", post.ID, finishResp.StatusCode, fbError.Error.Message)
-		return "", fmt.Errorf("Facebook Story video publish error: %s", fbError.Error.Message)
+		return "", metaError(fbError.Error.Code, fmt.Errorf("Facebook Story video publish error: %s", fbError.Error.Message))
 	}
 
 	var finishResult struct {
@@ -766,3 +843,34 @@ func (f *FacebookPublisher) publishStoryVideo(post *models.Post, media *models.M
 	utils.Infof("facebook story video published post_id=%s video_id=%s", post.ID, initResp.VideoID)
 	return initResp.VideoID, nil
 }
+
+// RevokeToken implements TokenRevoker by deleting every permission the user
+// granted the app, which invalidates the stored user token and the page
+// tokens derived from it.
+func (f *FacebookPublisher) RevokeToken(cred *models.PlatformCredentials) error {
+	userID := cred.PlatformUserID
+	if userID == "" {
+		userID = "me"
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s/permissions", f.graphBase(), config.Load().FacebookVersion, userID)
+
+	req, err := http.NewRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cred.AccessToken)
+
+	resp, err := f.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("facebook token revoke request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var fbError FacebookErrorResponse
+		json.Unmarshal(body, &fbError)
+		return fmt.Errorf("facebook token revoke failed: %s (code: %d)", fbError.Error.Message, fbError.Error.Code)
+	}
+	return nil
+}
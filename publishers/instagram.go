@@ -11,11 +11,14 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type InstagramPublisher struct {
-	client *http.Client
+	client  *http.Client
+	baseURL string
 }
 
 type instagramErrorResponse struct {
@@ -26,46 +29,41 @@ type instagramErrorResponse struct {
 	} `json:"error"`
 }
 
-func NewInstagramPublisher(client *http.Client) *InstagramPublisher {
+func NewInstagramPublisher(client *http.Client, baseURL string) *InstagramPublisher {
 	if client == nil {
-		client = &http.Client{Timeout: 30 * time.Second}
+		client = utils.NewHTTPClient(30 * time.Second)
 	}
-	return &InstagramPublisher{client: client}
+	return &InstagramPublisher{client: client, baseURL: baseURLOrDefault(baseURL, DefaultInstagramGraphBase)}
 }
 
 func (i *InstagramPublisher) httpClient() *http.Client {
 	if i.client == nil {
-		i.client = &http.Client{Timeout: 30 * time.Second}
+		i.client = utils.NewHTTPClient(30 * time.Second)
 	}
 	return i.client
 }
 
+// graphBase returns the Instagram Graph API base URL, defaulting to production.
+func (i *InstagramPublisher) graphBase() string {
+	return baseURLOrDefault(i.baseURL, DefaultInstagramGraphBase)
+}
+
 func (i *InstagramPublisher) Publish(post *models.Post, cred *models.PlatformCredentials) models.PublishResult {
 	if cred == nil || cred.AccessToken == "" {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Missing Instagram credentials",
-		}
+		return reauthResult(models.Instagram, models.ErrorCodeMissingCredentials, "Missing Instagram credentials")
 	}
 
 	if cred.PlatformUserID == "" {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram account not connected correctly. Reconnect via OAuth to fetch Instagram Business Account ID",
-		}
+		return reauthResult(models.Instagram, models.ErrorCodeMissingCredentials,
+			"Instagram account not connected correctly. Reconnect via OAuth to fetch Instagram Business Account ID")
 	}
 
 	// Check if token is expired
 	tokenValidator := utils.NewTokenValidator()
 	if tokenValidator.IsTokenExpired(cred) {
 		utils.Warnf("instagram token expired post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram token has expired. Please reconnect your account via OAuth",
-		}
+		return reauthResult(models.Instagram, models.ErrorCodeTokenExpired,
+			"Instagram token has expired. Please reconnect your account via OAuth")
 	}
 
 	// Short posts (Reels) — publish as a Reel with video
@@ -78,44 +76,42 @@ func (i *InstagramPublisher) Publish(post *models.Post, cred *models.PlatformCre
 		return i.publishStory(post, cred)
 	}
 
-	// Normal posts — image-based publishing
+	// Normal posts — image-based publishing, or a video feed post when only
+	// video is attached
 	imageMedia := []*models.Media{}
+	var videoMedia *models.Media
 	for _, media := range post.Media {
 		if media.Type == models.MediaImage {
 			imageMedia = append(imageMedia, media)
+		} else if media.Type == models.MediaVideo && videoMedia == nil {
+			videoMedia = media
 		}
 	}
 
+	if len(imageMedia) == 0 && videoMedia != nil {
+		return i.publishVideo(post, videoMedia, cred)
+	}
+
 	if len(imageMedia) == 0 {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram requires at least one image for normal posts",
-		}
+		return errorResult(models.Instagram, models.ErrorCategoryInvalidContent,
+			"Instagram requires at least one image or video for normal posts")
 	}
 
 	if strings.Contains(strings.ToLower(imageMedia[0].URL), "localhost") || strings.Contains(strings.ToLower(imageMedia[0].URL), "127.0.0.1") {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files",
-		}
+		return errorResult(models.Instagram, models.ErrorCategoryMediaError,
+			"Instagram cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files")
 	}
 
 	var postID string
 	var err error
 	if len(imageMedia) == 1 {
-		postID, err = i.publishSingleImage(post.Content, imageMedia[0].URL, cred.PlatformUserID, cred.AccessToken, post.IsSponsored)
+		postID, err = i.publishSingleImage(caption(post, models.Instagram), imageMedia[0], cred.PlatformUserID, cred.AccessToken, post.IsSponsored)
 	} else {
-		postID, err = i.publishCarousel(post.Content, imageMedia, cred.PlatformUserID, cred.AccessToken, post.IsSponsored)
+		postID, err = i.publishCarousel(caption(post, models.Instagram), imageMedia, cred.PlatformUserID, cred.AccessToken, post.IsSponsored)
 	}
 
 	if err != nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  fmt.Sprintf("Error publishing to Instagram: %v", err),
-		}
+		return failureResult(models.Instagram, fmt.Sprintf("Error publishing to Instagram: %v", err), err)
 	}
 
 	return models.PublishResult{
@@ -138,19 +134,13 @@ func (i *InstagramPublisher) publishReel(post *models.Post, cred *models.Platfor
 	}
 
 	if videoMedia == nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram Reels require a video attachment",
-		}
+		return errorResult(models.Instagram, models.ErrorCategoryInvalidContent,
+			"Instagram Reels require a video attachment")
 	}
 
 	if strings.Contains(strings.ToLower(videoMedia.URL), "localhost") || strings.Contains(strings.ToLower(videoMedia.URL), "127.0.0.1") {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files",
-		}
+		return errorResult(models.Instagram, models.ErrorCategoryMediaError,
+			"Instagram cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files")
 	}
 
 	// Create a REELS media container.
@@ -159,35 +149,23 @@ func (i *InstagramPublisher) publishReel(post *models.Post, cred *models.Platfor
 	reelParams := map[string]string{
 		"media_type": "REELS",
 		"video_url":  videoMedia.URL,
-		"caption":    post.Content,
+		"caption":    caption(post, models.Instagram),
 	}
 	if post.IsSponsored {
 		reelParams["branded_content_tag_enabled"] = "true"
 	}
 	containerID, err := i.createMediaContainer(cred.PlatformUserID, cred.AccessToken, reelParams)
 	if err != nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  fmt.Sprintf("Error creating Instagram Reel container: %v", err),
-		}
+		return failureResult(models.Instagram, fmt.Sprintf("Error creating Instagram Reel container: %v", err), err)
 	}
 
-	if err := i.waitContainerReady(containerID, cred.AccessToken); err != nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  fmt.Sprintf("Error processing Instagram Reel: %v", err),
-		}
+	if err := i.waitContainerReady(containerID, cred.AccessToken, true); err != nil {
+		return failureResult(models.Instagram, fmt.Sprintf("Error processing Instagram Reel: %v", err), err)
 	}
 
 	postID, err := i.publishContainer(cred.PlatformUserID, cred.AccessToken, containerID)
 	if err != nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  fmt.Sprintf("Error publishing Instagram Reel: %v", err),
-		}
+		return failureResult(models.Instagram, fmt.Sprintf("Error publishing Instagram Reel: %v", err), err)
 	}
 
 	return models.PublishResult{
@@ -198,25 +176,57 @@ func (i *InstagramPublisher) publishReel(post *models.Post, cred *models.Platfor
 	}
 }
 
+// publishVideo publishes a video as a regular Instagram feed post
+// (media_type VIDEO), as opposed to a Reel.
+func (i *InstagramPublisher) publishVideo(post *models.Post, videoMedia *models.Media, cred *models.PlatformCredentials) models.PublishResult {
+	if strings.Contains(strings.ToLower(videoMedia.URL), "localhost") || strings.Contains(strings.ToLower(videoMedia.URL), "127.0.0.1") {
+		return errorResult(models.Instagram, models.ErrorCategoryMediaError,
+			"Instagram cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files")
+	}
+
+	videoParams := map[string]string{
+		"media_type": "VIDEO",
+		"video_url":  videoMedia.URL,
+		"caption":    caption(post, models.Instagram),
+	}
+	if post.IsSponsored {
+		videoParams["branded_content_tag_enabled"] = "true"
+	}
+	containerID, err := i.createMediaContainer(cred.PlatformUserID, cred.AccessToken, videoParams)
+	if err != nil {
+		return failureResult(models.Instagram, fmt.Sprintf("Error creating Instagram video container: %v", err), err)
+	}
+
+	if err := i.waitContainerReady(containerID, cred.AccessToken, true); err != nil {
+		return failureResult(models.Instagram, fmt.Sprintf("Error processing Instagram video: %v", err), err)
+	}
+
+	postID, err := i.publishContainer(cred.PlatformUserID, cred.AccessToken, containerID)
+	if err != nil {
+		return failureResult(models.Instagram, fmt.Sprintf("Error publishing Instagram video: %v", err), err)
+	}
+
+	return models.PublishResult{
+		Platform: models.Instagram,
+		Success:  true,
+		Message:  "Published successfully as Instagram video",
+		PostID:   postID,
+	}
+}
+
 // publishStory publishes an image or video as an Instagram Story.
 // Uses the Content Publishing API with media_type STORIES.
 func (i *InstagramPublisher) publishStory(post *models.Post, cred *models.PlatformCredentials) models.PublishResult {
 	if len(post.Media) == 0 {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram Stories require at least one image or video attachment",
-		}
+		return errorResult(models.Instagram, models.ErrorCategoryInvalidContent,
+			"Instagram Stories require at least one image or video attachment")
 	}
 
 	media := post.Media[0]
 
 	if strings.Contains(strings.ToLower(media.URL), "localhost") || strings.Contains(strings.ToLower(media.URL), "127.0.0.1") {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  "Instagram cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files",
-		}
+		return errorResult(models.Instagram, models.ErrorCategoryMediaError,
+			"Instagram cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files")
 	}
 
 	// Build the container parameters based on media type
@@ -234,28 +244,16 @@ func (i *InstagramPublisher) publishStory(post *models.Post, cred *models.Platfo
 
 	containerID, err := i.createMediaContainer(cred.PlatformUserID, cred.AccessToken, containerParams)
 	if err != nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  fmt.Sprintf("Error creating Instagram Story container: %v", err),
-		}
+		return failureResult(models.Instagram, fmt.Sprintf("Error creating Instagram Story container: %v", err), err)
 	}
 
-	if err := i.waitContainerReady(containerID, cred.AccessToken); err != nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  fmt.Sprintf("Error processing Instagram Story: %v", err),
-		}
+	if err := i.waitContainerReady(containerID, cred.AccessToken, media.Type == models.MediaVideo); err != nil {
+		return failureResult(models.Instagram, fmt.Sprintf("Error processing Instagram Story: %v", err), err)
 	}
 
 	postID, err := i.publishContainer(cred.PlatformUserID, cred.AccessToken, containerID)
 	if err != nil {
-		return models.PublishResult{
-			Platform: models.Instagram,
-			Success:  false,
-			Message:  fmt.Sprintf("Error publishing Instagram Story: %v", err),
-		}
+		return failureResult(models.Instagram, fmt.Sprintf("Error publishing Instagram Story: %v", err), err)
 	}
 
 	return models.PublishResult{
@@ -266,11 +264,14 @@ func (i *InstagramPublisher) publishStory(post *models.Post, cred *models.Platfo
 	}
 }
 
-func (i *InstagramPublisher) publishSingleImage(caption, imageURL, instagramUserID, accessToken string, isSponsored bool) (string, error) {
+func (i *InstagramPublisher) publishSingleImage(caption string, image *models.Media, instagramUserID, accessToken string, isSponsored bool) (string, error) {
 	params := map[string]string{
-		"image_url": imageURL,
+		"image_url": image.URL,
 		"caption":   caption,
 	}
+	if image.AltText != "" {
+		params["alt_text"] = image.AltText
+	}
 	if isSponsored {
 		params["branded_content_tag_enabled"] = "true"
 	}
@@ -279,7 +280,7 @@ func (i *InstagramPublisher) publishSingleImage(caption, imageURL, instagramUser
 		return "", err
 	}
 
-	if err := i.waitContainerReady(containerID, accessToken); err != nil {
+	if err := i.waitContainerReady(containerID, accessToken, false); err != nil {
 		return "", err
 	}
 
@@ -287,19 +288,53 @@ func (i *InstagramPublisher) publishSingleImage(caption, imageURL, instagramUser
 }
 
 func (i *InstagramPublisher) publishCarousel(caption string, media []*models.Media, instagramUserID, accessToken string, isSponsored bool) (string, error) {
-	children := make([]string, 0, len(media))
-	for _, m := range media {
-		containerID, err := i.createMediaContainer(instagramUserID, accessToken, map[string]string{
-			"image_url":        m.URL,
-			"is_carousel_item": "true",
-		})
-		if err != nil {
-			return "", err
-		}
-		if err := i.waitContainerReady(containerID, accessToken); err != nil {
-			return "", err
+	// Create child containers with bounded concurrency. Each child is written
+	// to its own slot so the carousel keeps the original media order.
+	children := make([]string, len(media))
+	sem := make(chan struct{}, config.Load().InstagramCarouselConcurrency)
+	var wg sync.WaitGroup
+	errCh := make(chan error, 1)
+	// Once a child has failed for good the carousel can't be published, so
+	// children not started yet are skipped
+	var failed atomic.Bool
+
+	for idx, m := range media {
+		idx, m := idx, m
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if failed.Load() {
+				return
+			}
+
+			containerID, err := i.createCarouselChild(m, instagramUserID, accessToken)
+			if err != nil {
+				failed.Store(true)
+				utils.Errorf("instagram carousel child failed ig_user_id=%s media_id=%s err=%v", instagramUserID, m.ID, err)
+				select {
+				case errCh <- fmt.Errorf("carousel item %d: %w", idx+1, err):
+				default:
+				}
+				return
+			}
+			children[idx] = containerID
+		}()
+	}
+	wg.Wait()
+	select {
+	case e := <-errCh:
+		// Unpublished containers can't be deleted; the ones already
+		// created expire on their own after 24 hours
+		ready := 0
+		for _, id := range children {
+			if id != "" {
+				ready++
+			}
 		}
-		children = append(children, containerID)
+		return "", fmt.Errorf("%w (%d of %d carousel items were ready)", e, ready, len(media))
+	default:
 	}
 
 	carouselParams := map[string]string{
@@ -315,16 +350,57 @@ func (i *InstagramPublisher) publishCarousel(caption string, media []*models.Med
 		return "", err
 	}
 
-	if err := i.waitContainerReady(carouselContainerID, accessToken); err != nil {
+	if err := i.waitContainerReady(carouselContainerID, accessToken, false); err != nil {
 		return "", err
 	}
 
 	return i.publishContainer(instagramUserID, accessToken, carouselContainerID)
 }
 
+// instagramChildRetryDelay is the wait before the first retry of a carousel
+// child; it doubles with each further retry.
+const instagramChildRetryDelay = 2 * time.Second
+
+// createCarouselChild creates the carousel item container of an image and
+// waits until it is ready. Transient and rate-limited failures are retried
+// up to INSTAGRAM_CAROUSEL_CHILD_RETRIES times, so one Meta hiccup doesn't
+// fail a large carousel.
+func (i *InstagramPublisher) createCarouselChild(m *models.Media, instagramUserID, accessToken string) (string, error) {
+	params := map[string]string{
+		"image_url":        m.URL,
+		"is_carousel_item": "true",
+	}
+	if m.AltText != "" {
+		params["alt_text"] = m.AltText
+	}
+
+	retries := config.Load().InstagramCarouselChildRetries
+	delay := instagramChildRetryDelay
+	for attempt := 0; ; attempt++ {
+		containerID, err := i.createMediaContainer(instagramUserID, accessToken, params)
+		if err == nil {
+			err = i.waitContainerReady(containerID, accessToken, false)
+		}
+		if err == nil {
+			return containerID, nil
+		}
+
+		category, _ := errorCategory(err)
+		if attempt >= retries || (category != models.ErrorCategoryTransient && category != models.ErrorCategoryRateLimited) {
+			if attempt > 0 {
+				return "", fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+			}
+			return "", err
+		}
+		utils.Warnf("instagram carousel child retry ig_user_id=%s media_id=%s attempt=%d err=%v", instagramUserID, m.ID, attempt+1, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
 func (i *InstagramPublisher) createMediaContainer(instagramUserID, accessToken string, values map[string]string) (string, error) {
 	cfg := config.Load()
-	endpoint := fmt.Sprintf("https://graph.instagram.com/%s/%s/media", cfg.InstagramVersion, instagramUserID)
+	endpoint := fmt.Sprintf("%s/%s/%s/media", i.graphBase(), cfg.InstagramVersion, instagramUserID)
 
 	form := url.Values{}
 	for k, v := range values {
@@ -346,7 +422,7 @@ func (i *InstagramPublisher) createMediaContainer(instagramUserID, accessToken s
 
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Instagram media container API error: %s", i.parseInstagramError(body))
+		return "", i.apiError("Instagram media container API error: %s", body)
 	}
 
 	var data struct {
@@ -364,7 +440,7 @@ func (i *InstagramPublisher) createMediaContainer(instagramUserID, accessToken s
 
 func (i *InstagramPublisher) publishContainer(instagramUserID, accessToken, containerID string) (string, error) {
 	cfg := config.Load()
-	endpoint := fmt.Sprintf("https://graph.instagram.com/%s/%s/media_publish", cfg.InstagramVersion, instagramUserID)
+	endpoint := fmt.Sprintf("%s/%s/%s/media_publish", i.graphBase(), cfg.InstagramVersion, instagramUserID)
 
 	form := url.Values{}
 	form.Set("creation_id", containerID)
@@ -384,7 +460,7 @@ func (i *InstagramPublisher) publishContainer(instagramUserID, accessToken, cont
 
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Instagram publish API error: %s", i.parseInstagramError(body))
+		return "", i.apiError("Instagram publish API error: %s", body)
 	}
 
 	var data struct {
@@ -397,11 +473,28 @@ func (i *InstagramPublisher) publishContainer(instagramUserID, accessToken, cont
 	return data.ID, nil
 }
 
-func (i *InstagramPublisher) waitContainerReady(containerID, accessToken string) error {
+// instagramMaxPollInterval caps the doubling wait between container checks.
+const instagramMaxPollInterval = 30 * time.Second
+
+// waitContainerReady polls a media container until Instagram has processed
+// it, waiting INSTAGRAM_CONTAINER_POLL_INTERVAL_SECONDS and then twice as
+// long after each check. It gives up after INSTAGRAM_CONTAINER_POLL_ATTEMPTS
+// checks or INSTAGRAM_CONTAINER_TIMEOUT_SECONDS, reporting the last status.
+// Video containers, which take much longer to process, use the
+// INSTAGRAM_VIDEO_CONTAINER_* limits instead.
+func (i *InstagramPublisher) waitContainerReady(containerID, accessToken string, isVideo bool) error {
 	cfg := config.Load()
-	endpoint := fmt.Sprintf("https://graph.instagram.com/%s/%s?fields=status_code&access_token=%s", cfg.InstagramVersion, containerID, url.QueryEscape(accessToken))
+	endpoint := fmt.Sprintf("%s/%s/%s?fields=status_code&access_token=%s", i.graphBase(), cfg.InstagramVersion, containerID, url.QueryEscape(accessToken))
+
+	attempts, timeout := cfg.InstagramContainerPollAttempts, cfg.InstagramContainerTimeout
+	if isVideo {
+		attempts, timeout = cfg.InstagramVideoContainerPollAttempts, cfg.InstagramVideoContainerTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	interval := cfg.InstagramContainerPollInterval
+	lastStatus := "unknown"
 
-	for attempt := 0; attempt < 30; attempt++ {
+	for attempt := 0; attempt < attempts; attempt++ {
 		resp, err := i.httpClient().Get(endpoint)
 		if err != nil {
 			return err
@@ -410,7 +503,7 @@ func (i *InstagramPublisher) waitContainerReady(containerID, accessToken string)
 		resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("Instagram container status API error: %s", i.parseInstagramError(body))
+			return i.apiError("Instagram container status API error: %s", body)
 		}
 
 		var status struct {
@@ -425,13 +518,68 @@ func (i *InstagramPublisher) waitContainerReady(containerID, accessToken string)
 		}
 
 		if status.StatusCode == "ERROR" {
-			return fmt.Errorf("Instagram media processing failed")
+			return newPublishError(models.ErrorCategoryMediaError, fmt.Errorf("Instagram media processing failed"))
 		}
+		lastStatus = status.StatusCode
 
-		time.Sleep(3 * time.Second)
+		if attempt == attempts-1 || time.Now().Add(interval).After(deadline) {
+			break
+		}
+		time.Sleep(interval)
+		interval *= 2
+		if interval > instagramMaxPollInterval {
+			interval = instagramMaxPollInterval
+		}
+	}
+
+	return newPublishError(models.ErrorCategoryTransient,
+		fmt.Errorf("Instagram media processing timeout (last status %s)", lastStatus))
+}
+
+// PostStatus implements StatusChecker. Published media has no processing
+// state of its own, so media the Graph API returns is live; media it no
+// longer knows (error code 100) has been removed.
+func (i *InstagramPublisher) PostStatus(mediaID string, cred *models.PlatformCredentials) (models.PlatformPostStatus, error) {
+	cfg := config.Load()
+	status := models.PlatformPostStatus{Platform: models.Instagram, ExternalPostID: mediaID}
+	endpoint := fmt.Sprintf("%s/%s/%s?fields=id,permalink&access_token=%s", i.graphBase(), cfg.InstagramVersion, mediaID, url.QueryEscape(cred.AccessToken))
+
+	resp, err := i.httpClient().Get(endpoint)
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		var igErr instagramErrorResponse
+		json.Unmarshal(body, &igErr)
+		if igErr.Error.Code == 100 {
+			status.State = models.PlatformStateRemoved
+			status.Detail = i.parseInstagramError(body)
+			return status, nil
+		}
+		return status, i.apiError("Instagram media status API error: %s", body)
 	}
 
-	return fmt.Errorf("Instagram media processing timeout")
+	var media struct {
+		Permalink string `json:"permalink"`
+	}
+	if err := json.Unmarshal(body, &media); err != nil {
+		return status, err
+	}
+
+	status.State = models.PlatformStateLive
+	status.URL = media.Permalink
+	return status, nil
+}
+
+// apiError formats a Graph API error response, marking token and permission
+// failures so the publish result asks the user to reconnect.
+func (i *InstagramPublisher) apiError(format string, body []byte) error {
+	var igErr instagramErrorResponse
+	json.Unmarshal(body, &igErr)
+	return metaError(igErr.Error.Code, fmt.Errorf(format, i.parseInstagramError(body)))
 }
 
 func (i *InstagramPublisher) parseInstagramError(body []byte) string {
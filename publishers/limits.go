@@ -0,0 +1,256 @@
+package publishers
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"fmt"
+)
+
+// Hard limits on post content, in characters (runes). For TikTok and YouTube
+// the content becomes the video title, so the title limit applies.
+const (
+	twitterMaxTextLength      = 280
+	facebookMaxTextLength     = 63206
+	instagramMaxCaptionLength = 2200
+	linkedInMaxTextLength     = 3000
+	tiktokMaxTitleLength      = 150
+	threadsMaxTextLength      = 500
+)
+
+var platformContentLimits = map[models.Platform]int{
+	models.Twitter:   twitterMaxTextLength,
+	models.Facebook:  facebookMaxTextLength,
+	models.Instagram: instagramMaxCaptionLength,
+	models.LinkedIn:  linkedInMaxTextLength,
+	models.TikTok:    tiktokMaxTitleLength,
+	models.YouTube:   youtubeMaxTitleLength,
+	models.Threads:   threadsMaxTextLength,
+}
+
+// ContentLimit returns how many characters of content a post of postType may
+// carry on platform: the platform's hard limit, lowered to MAX_CAPTION_LENGTH
+// when that is set. 0 means the platform has no known limit.
+func ContentLimit(platform models.Platform, postType models.PostType) int {
+	cfg := config.Load()
+	limit := platformContentLimits[platform]
+	if platform == models.YouTube && postType == models.PostTypeShort {
+		// Leave room for the " #Shorts" suffix appended to the title
+		limit -= utils.RuneLen(youtubeShortsSuffix)
+	}
+	if platform == models.Facebook && postType == models.PostTypeShort {
+		// Short posts are Reels, whose content becomes the description
+		limit = cfg.FacebookReelDescriptionMaxLength
+	}
+	if max := cfg.CaptionMaxLength; max > 0 && (limit == 0 || max < limit) {
+		limit = max
+	}
+	return limit
+}
+
+// Threaded reports whether post goes to platform as a thread when its content
+// is over the limit, so the limit does not apply. Only normal Twitter posts
+// with threading_enabled are threaded.
+func Threaded(post *models.Post, platform models.Platform) bool {
+	return platform == models.Twitter && post.ThreadingEnabled && post.PostType == models.PostTypeNormal
+}
+
+// OverLimitPlatforms returns the platforms of post whose content limit the
+// post's content exceeds.
+func OverLimitPlatforms(post *models.Post) []models.Platform {
+	over := []models.Platform{}
+	for _, p := range post.Platforms {
+		if Threaded(post, p) {
+			continue
+		}
+		if limit := ContentLimit(p, post.PostType); limit > 0 && utils.RuneLen(post.ContentFor(p)) > limit {
+			over = append(over, p)
+		}
+	}
+	return over
+}
+
+// CheckContentLength returns an error when post's content is too long for
+// platform and the post neither allows truncation nor is threaded there.
+func CheckContentLength(post *models.Post, platform models.Platform) error {
+	if post.AllowTruncation || Threaded(post, platform) {
+		return nil
+	}
+	limit := ContentLimit(platform, post.PostType)
+	if length := utils.RuneLen(post.ContentFor(platform)); limit > 0 && length > limit {
+		return fmt.Errorf("Content is %d characters, over the %d character limit for %s. Shorten it or set allow_truncation",
+			length, limit, platform)
+	}
+	return nil
+}
+
+// Default video limits per platform: the longest edge in pixels and the
+// average bitrate in kbps. 0 means no known limit. VIDEO_MAX_RESOLUTION and
+// VIDEO_MAX_BITRATE_KBPS override them per platform.
+var platformVideoLimits = map[models.Platform]struct{ resolution, bitrateKbps int }{
+	models.Twitter:   {1920, 25000},
+	models.Facebook:  {4096, 0},
+	models.Instagram: {1920, 25000},
+	models.LinkedIn:  {4096, 0},
+	models.TikTok:    {4096, 0},
+	models.YouTube:   {7680, 0},
+	models.Threads:   {1920, 100000},
+}
+
+// VideoLimits returns the longest video edge (pixels) and the average
+// bitrate (kbps) platform accepts. 0 means no limit.
+func VideoLimits(platform models.Platform) (resolution, bitrateKbps int) {
+	defaults := platformVideoLimits[platform]
+	resolution, bitrateKbps = defaults.resolution, defaults.bitrateKbps
+	cfg := config.Load()
+	if v, ok := cfg.VideoMaxResolution[string(platform)]; ok {
+		resolution = v
+	}
+	if v, ok := cfg.VideoMaxBitrateKbps[string(platform)]; ok {
+		bitrateKbps = v
+	}
+	return resolution, bitrateKbps
+}
+
+// CheckVideoLimits returns an error when a video attached to post is larger
+// or has a higher bitrate than platform accepts. Videos whose metadata could
+// not be read are left for the platform to judge.
+func CheckVideoLimits(post *models.Post, platform models.Platform) error {
+	maxResolution, maxBitrate := VideoLimits(platform)
+	for _, m := range post.Media {
+		if m.Type != models.MediaVideo {
+			continue
+		}
+		if longest := max(m.Width, m.Height); maxResolution > 0 && longest > maxResolution {
+			return fmt.Errorf("Video %s is %dx%d, over the %d pixel limit for %s. Export it at a lower resolution",
+				m.ID, m.Width, m.Height, maxResolution, platform)
+		}
+		if maxBitrate > 0 && m.BitrateKbps > maxBitrate {
+			return fmt.Errorf("Video %s has a bitrate of %d kbps, over the %d kbps limit for %s. Export it at a lower bitrate",
+				m.ID, m.BitrateKbps, maxBitrate, platform)
+		}
+	}
+	return nil
+}
+
+// MediaRequirement is the media a platform needs on a post before it can
+// publish it.
+type MediaRequirement string
+
+const (
+	MediaRequirementNone         MediaRequirement = ""
+	MediaRequirementVideo        MediaRequirement = "video"
+	MediaRequirementImageOrVideo MediaRequirement = "image_or_video"
+)
+
+// platformMediaRequirements lists the media each platform needs per post
+// type. Anything not listed may be published as text only.
+var platformMediaRequirements = map[models.Platform]map[models.PostType]MediaRequirement{
+	models.Instagram: {
+		models.PostTypeNormal: MediaRequirementImageOrVideo,
+		models.PostTypeShort:  MediaRequirementVideo,
+		models.PostTypeStory:  MediaRequirementImageOrVideo,
+	},
+	models.Facebook: {
+		models.PostTypeShort: MediaRequirementVideo,
+		models.PostTypeStory: MediaRequirementImageOrVideo,
+	},
+	models.TikTok: {
+		models.PostTypeNormal: MediaRequirementVideo,
+		models.PostTypeShort:  MediaRequirementVideo,
+		models.PostTypeStory:  MediaRequirementVideo,
+	},
+	models.YouTube: {
+		models.PostTypeNormal: MediaRequirementVideo,
+		models.PostTypeShort:  MediaRequirementVideo,
+		models.PostTypeStory:  MediaRequirementVideo,
+	},
+}
+
+// RequiredMedia returns the media platform needs on a post of postType.
+func RequiredMedia(platform models.Platform, postType models.PostType) MediaRequirement {
+	return platformMediaRequirements[platform][postType]
+}
+
+// MissingMediaPlatforms returns the platforms of post whose media
+// requirement the post's media does not meet.
+func MissingMediaPlatforms(post *models.Post) []models.Platform {
+	hasImage, hasVideo := false, false
+	for _, m := range post.Media {
+		switch m.Type {
+		case models.MediaImage:
+			hasImage = true
+		case models.MediaVideo:
+			hasVideo = true
+		}
+	}
+
+	missing := []models.Platform{}
+	for _, p := range post.Platforms {
+		switch RequiredMedia(p, post.PostType) {
+		case MediaRequirementVideo:
+			if !hasVideo {
+				missing = append(missing, p)
+			}
+		case MediaRequirementImageOrVideo:
+			if !hasImage && !hasVideo {
+				missing = append(missing, p)
+			}
+		}
+	}
+	return missing
+}
+
+// caption returns the post content for platform (see Post.ContentFor) cut
+// to platform's content limit. Posts that do not allow truncation are
+// rejected before publishing, so this only shortens content when the user
+// asked for it.
+func caption(post *models.Post, platform models.Platform) string {
+	content := post.ContentFor(platform)
+	limit := ContentLimit(platform, post.PostType)
+	if limit <= 0 {
+		return content
+	}
+	return utils.TruncateRunes(content, limit)
+}
+
+// countingModeCharacters is the only counting mode in use: every platform
+// limit is measured in Unicode code points.
+const countingModeCharacters = "characters"
+
+// ContentAnalysis describes how content measures up against one platform's
+// limit.
+type ContentAnalysis struct {
+	Platform     models.Platform `json:"platform"`
+	CountingMode string          `json:"counting_mode"`
+	Length       int             `json:"length"`
+	Limit        int             `json:"limit"`
+	Fits         bool            `json:"fits"`
+	Remaining    int             `json:"remaining"`
+	Preview      string          `json:"truncated_preview"`
+}
+
+// AnalyzeContent measures content against the limit of each platform for a
+// post of postType, with the text each would publish if truncation were
+// allowed. It fails on a platform it does not know.
+func AnalyzeContent(content string, platforms []models.Platform, postType models.PostType) ([]ContentAnalysis, error) {
+	results := make([]ContentAnalysis, 0, len(platforms))
+	for _, p := range platforms {
+		if _, ok := platformContentLimits[p]; !ok {
+			return nil, fmt.Errorf("unsupported platform: %s", p)
+		}
+		post := &models.Post{Content: content, PostType: postType}
+		limit := ContentLimit(p, postType)
+		length := utils.RuneLen(content)
+		results = append(results, ContentAnalysis{
+			Platform:     p,
+			CountingMode: countingModeCharacters,
+			Length:       length,
+			Limit:        limit,
+			Fits:         length <= limit,
+			Remaining:    limit - length,
+			Preview:      caption(post, p),
+		})
+	}
+	return results, nil
+}
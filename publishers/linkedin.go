@@ -3,48 +3,285 @@ package publishers
 import (
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
 	"time"
-
-	"github.com/google/uuid"
 )
 
-type LinkedInPublisher struct{}
+// LinkedInPublisher shares posts through the LinkedIn UGC Posts API
+// (POST /v2/ugcPosts). Images are registered as assets, uploaded to the URL
+// LinkedIn returns, then referenced from the share by their asset URN.
+type LinkedInPublisher struct {
+	client  *http.Client
+	baseURL string
+}
 
-func (l *LinkedInPublisher) Publish(post *models.Post, cred *models.PlatformCredentials) models.PublishResult {
-	time.Sleep(700 * time.Millisecond)
+type linkedInErrorResponse struct {
+	ServiceErrorCode int    `json:"serviceErrorCode"`
+	Message          string `json:"message"`
+	Status           int    `json:"status"`
+}
+
+func NewLinkedInPublisher(client *http.Client, baseURL string) *LinkedInPublisher {
+	if client == nil {
+		client = utils.NewHTTPClient(30 * time.Second)
+	}
+	return &LinkedInPublisher{client: client, baseURL: baseURLOrDefault(baseURL, DefaultLinkedInAPIBase)}
+}
+
+func (l *LinkedInPublisher) httpClient() *http.Client {
+	if l.client == nil {
+		l.client = utils.NewHTTPClient(30 * time.Second)
+	}
+	return l.client
+}
 
+// apiBase returns the LinkedIn API base URL, defaulting to production.
+func (l *LinkedInPublisher) apiBase() string {
+	return baseURLOrDefault(l.baseURL, DefaultLinkedInAPIBase)
+}
+
+func (l *LinkedInPublisher) Publish(post *models.Post, cred *models.PlatformCredentials) models.PublishResult {
 	if cred == nil || cred.AccessToken == "" {
-		return models.PublishResult{
-			Platform: models.LinkedIn,
-			Success:  false,
-			Message:  "Missing LinkedIn credentials",
-		}
+		return reauthResult(models.LinkedIn, models.ErrorCodeMissingCredentials, "Missing LinkedIn credentials")
+	}
+
+	if cred.PlatformUserID == "" {
+		return reauthResult(models.LinkedIn, models.ErrorCodeMissingCredentials,
+			"LinkedIn account not connected correctly. Reconnect to set the LinkedIn member or organization ID")
 	}
 
 	// Check if token is expired
 	tokenValidator := utils.NewTokenValidator()
 	if tokenValidator.IsTokenExpired(cred) {
-		return models.PublishResult{
-			Platform: models.LinkedIn,
-			Success:  false,
-			Message:  "LinkedIn token has expired. Please reconnect your account via OAuth",
-		}
+		utils.Warnf("linkedin token expired post_id=%s user_id=%s", post.ID, post.UserID)
+		return reauthResult(models.LinkedIn, models.ErrorCodeTokenExpired,
+			"LinkedIn token has expired. Please reconnect your account via OAuth")
 	}
 
 	// LinkedIn does NOT support stories or short-form video posts.
-	if post.PostType == models.PostTypeStory {
-		return models.PublishResult{
-			Platform: models.LinkedIn,
-			Success:  false,
-			Message:  "LinkedIn does not support stories. Use post_type 'normal' instead",
+	if post.PostType == models.PostTypeStory || post.PostType == models.PostTypeShort {
+		return errorResult(models.LinkedIn, models.ErrorCategoryUnsupported,
+			fmt.Sprintf("LinkedIn does not support %s posts. Use post_type 'normal' instead", post.PostType))
+	}
+
+	var image *models.Media
+	for _, m := range post.Media {
+		if m.Type != models.MediaImage {
+			return errorResult(models.LinkedIn, models.ErrorCategoryUnsupported,
+				"LinkedIn shares currently support text and a single image only")
+		}
+		if image != nil {
+			return errorResult(models.LinkedIn, models.ErrorCategoryUnsupported,
+				"LinkedIn shares support at most one image")
 		}
+		image = m
+	}
+
+	author := linkedInAuthorURN(cred.PlatformUserID)
+
+	var asset string
+	if image != nil {
+		var err error
+		asset, err = l.uploadImage(image, author, cred.AccessToken)
+		if err != nil {
+			utils.Errorf("linkedin image upload failed post_id=%s media_id=%s err=%v", post.ID, image.ID, err)
+			return failureResult(models.LinkedIn, fmt.Sprintf("Error uploading image to LinkedIn: %v", err), err)
+		}
+	}
+
+	postID, err := l.createShare(author, caption(post, models.LinkedIn), post.PrivacyLevel, image, asset, cred.AccessToken)
+	if err != nil {
+		utils.Errorf("linkedin share failed post_id=%s err=%v", post.ID, err)
+		return failureResult(models.LinkedIn, fmt.Sprintf("Error publishing to LinkedIn: %v", err), err)
 	}
 
 	return models.PublishResult{
 		Platform: models.LinkedIn,
 		Success:  true,
 		Message:  "Published successfully on LinkedIn",
-		PostID:   fmt.Sprintf("li_%s", uuid.New().String()[:8]),
+		PostID:   postID,
+	}
+}
+
+// linkedInAuthorURN turns the stored platform user ID into the author URN of
+// a share. A full URN (urn:li:person:... or urn:li:organization:...) is used
+// as is; a bare ID is taken to be a member.
+func linkedInAuthorURN(platformUserID string) string {
+	if strings.HasPrefix(platformUserID, "urn:li:") {
+		return platformUserID
+	}
+	return "urn:li:person:" + platformUserID
+}
+
+// linkedInVisibility maps a privacy level to a share's visibility. LinkedIn
+// only knows public and connections-only shares, so every restricted level
+// becomes CONNECTIONS.
+func linkedInVisibility(privacy models.PrivacyLevel) string {
+	if privacy == "" || privacy == models.PrivacyPublic {
+		return "PUBLIC"
+	}
+	return "CONNECTIONS"
+}
+
+// createShare posts a text share, with the uploaded image asset when there
+// is one, and returns the URN of the new post.
+func (l *LinkedInPublisher) createShare(author, text string, privacy models.PrivacyLevel, image *models.Media, asset, accessToken string) (string, error) {
+	shareContent := map[string]interface{}{
+		"shareCommentary":    map[string]string{"text": text},
+		"shareMediaCategory": "NONE",
+	}
+	if asset != "" {
+		media := map[string]interface{}{
+			"status": "READY",
+			"media":  asset,
+		}
+		if image.AltText != "" {
+			media["description"] = map[string]string{"text": image.AltText}
+		}
+		shareContent["shareMediaCategory"] = "IMAGE"
+		shareContent["media"] = []interface{}{media}
+	}
+
+	payload := map[string]interface{}{
+		"author":         author,
+		"lifecycleState": "PUBLISHED",
+		"specificContent": map[string]interface{}{
+			"com.linkedin.ugc.ShareContent": shareContent,
+		},
+		"visibility": map[string]string{
+			"com.linkedin.ugc.MemberNetworkVisibility": linkedInVisibility(privacy),
+		},
+	}
+
+	body, header, err := l.postJSON(l.apiBase()+"/v2/ugcPosts", payload, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	// The post URN comes back in X-RestLi-Id, and in the body as id
+	if id := header.Get("X-RestLi-Id"); id != "" {
+		return id, nil
+	}
+	var data struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", err
+	}
+	if data.ID == "" {
+		return "", fmt.Errorf("LinkedIn share API returned empty post id")
+	}
+	return data.ID, nil
+}
+
+// uploadImage registers an image upload for owner, PUTs the file to the
+// upload URL LinkedIn hands out and returns the asset URN.
+func (l *LinkedInPublisher) uploadImage(media *models.Media, owner, accessToken string) (string, error) {
+	payload := map[string]interface{}{
+		"registerUploadRequest": map[string]interface{}{
+			"recipes": []string{"urn:li:digitalmediaRecipe:feedshare-image"},
+			"owner":   owner,
+			"serviceRelationships": []map[string]string{{
+				"relationshipType": "OWNER",
+				"identifier":       "urn:li:userGeneratedContent",
+			}},
+		},
+	}
+
+	body, _, err := l.postJSON(l.apiBase()+"/v2/assets?action=registerUpload", payload, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	var registered struct {
+		Value struct {
+			UploadMechanism struct {
+				HTTPRequest struct {
+					UploadURL string `json:"uploadUrl"`
+				} `json:"com.linkedin.digitalmedia.uploading.MediaUploadHttpRequest"`
+			} `json:"uploadMechanism"`
+			Asset string `json:"asset"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(body, &registered); err != nil {
+		return "", err
+	}
+	uploadURL := registered.Value.UploadMechanism.HTTPRequest.UploadURL
+	if uploadURL == "" || registered.Value.Asset == "" {
+		return "", fmt.Errorf("LinkedIn register upload API returned no upload URL or asset")
+	}
+
+	file, err := os.Open(media.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open media file: %w", err)
+	}
+	defer file.Close()
+
+	req, err := http.NewRequest("PUT", uploadURL, file)
+	if err != nil {
+		return "", err
+	}
+	if info, err := file.Stat(); err == nil {
+		req.ContentLength = info.Size()
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", media.MimeType)
+
+	resp, err := l.httpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("LinkedIn image upload request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", l.apiError(resp.StatusCode, "LinkedIn image upload failed: %s", respBody)
+	}
+
+	return registered.Value.Asset, nil
+}
+
+// postJSON POSTs payload to endpoint with the Rest.li 2.0 protocol header and
+// returns the body and headers of a 200/201 response.
+func (l *LinkedInPublisher) postJSON(endpoint string, payload interface{}, accessToken string) ([]byte, http.Header, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Restli-Protocol-Version", "2.0.0")
+
+	resp, err := l.httpClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, nil, l.apiError(resp.StatusCode, "LinkedIn API error: %s", body)
+	}
+	return body, resp.Header, nil
+}
+
+// apiError formats a LinkedIn error response ({"serviceErrorCode", "message",
+// "status"}) and classifies it by HTTP status.
+func (l *LinkedInPublisher) apiError(statusCode int, format string, body []byte) error {
+	var liErr linkedInErrorResponse
+	if err := json.Unmarshal(body, &liErr); err == nil && liErr.Message != "" {
+		return httpError(statusCode, fmt.Errorf(format, liErr.Message))
 	}
+	return httpError(statusCode, fmt.Errorf(format, fmt.Sprintf("status %d: %s", statusCode, string(body))))
 }
@@ -0,0 +1,51 @@
+package publishers
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/models"
+	"strings"
+)
+
+// defaultPostTypePlatforms lists the platforms each post type can be
+// published to when POST_TYPE_*_PLATFORMS does not override it.
+var defaultPostTypePlatforms = map[models.PostType][]models.Platform{
+	models.PostTypeNormal: {models.Twitter, models.Facebook, models.LinkedIn, models.Instagram, models.YouTube, models.Threads},
+	models.PostTypeShort:  {models.Instagram, models.Facebook, models.TikTok, models.YouTube},
+	models.PostTypeStory:  {models.Facebook, models.Instagram},
+}
+
+// PostTypePlatforms returns the platforms a post of postType may be
+// published to: the POST_TYPE_<TYPE>_PLATFORMS list when set, otherwise the
+// built-in defaults.
+func PostTypePlatforms(postType models.PostType) []models.Platform {
+	cfg := config.Load()
+	var override []string
+	switch postType {
+	case models.PostTypeNormal:
+		override = cfg.NormalPostPlatforms
+	case models.PostTypeShort:
+		override = cfg.ShortPostPlatforms
+	case models.PostTypeStory:
+		override = cfg.StoryPostPlatforms
+	}
+	if len(override) == 0 {
+		return defaultPostTypePlatforms[postType]
+	}
+
+	platforms := make([]models.Platform, len(override))
+	for i, name := range override {
+		platforms[i] = models.Platform(strings.ToLower(name))
+	}
+	return platforms
+}
+
+// SupportsPostType reports whether posts of postType may be published to
+// platform.
+func SupportsPostType(platform models.Platform, postType models.PostType) bool {
+	for _, p := range PostTypePlatforms(postType) {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
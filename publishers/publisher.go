@@ -2,8 +2,41 @@ package publishers
 
 import (
 	"SocialMediaAPI/models"
+	"fmt"
 )
 
 type PlatformPublisher interface {
 	Publish(post *models.Post, credentials *models.PlatformCredentials) models.PublishResult
-}
\ No newline at end of file
+}
+
+// StatusChecker is implemented by publishers that can look up the current
+// state of a post they published, identified by its external post ID.
+type StatusChecker interface {
+	PostStatus(externalPostID string, credentials *models.PlatformCredentials) (models.PlatformPostStatus, error)
+}
+
+// TokenRefresher is implemented by publishers that can renew an expired
+// access token with the stored refresh token. RefreshToken updates the
+// token fields of credentials in place; the caller persists them.
+type TokenRefresher interface {
+	RefreshToken(credentials *models.PlatformCredentials) error
+}
+
+// TokenRevoker is implemented by publishers whose platform lets us revoke
+// the access we were granted, so disconnecting an account also invalidates
+// its tokens on the platform side.
+type TokenRevoker interface {
+	RevokeToken(credentials *models.PlatformCredentials) error
+}
+
+// CheckAnimatedMedia returns an error when post carries an animated WebP.
+// No platform publishes those as animations, and there is no conversion to
+// GIF or MP4 yet, so they would otherwise go out as a still first frame.
+func CheckAnimatedMedia(post *models.Post) error {
+	for _, m := range post.Media {
+		if m.IsAnimated && m.MimeType == "image/webp" {
+			return fmt.Errorf("Media %s is an animated WebP, which cannot be published. Convert it to GIF or MP4 and upload it again", m.ID)
+		}
+	}
+	return nil
+}
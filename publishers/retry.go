@@ -0,0 +1,109 @@
+package publishers
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/utils"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// retryBaseDelay is the wait before the first retry of a platform call; it
+// doubles with each further retry unless the platform sends Retry-After.
+const retryBaseDelay = time.Second
+
+// retryBudget is the time the retries of one publish may spend waiting, in
+// total across all of its platform calls (PLATFORM_RETRY_MAX_WAIT_SECONDS),
+// so an interactive publish still answers before the server's write timeout
+// however many calls it makes. It is safe for concurrent use.
+type retryBudget struct {
+	mu        sync.Mutex
+	remaining time.Duration
+}
+
+// newRetryBudget returns a budget for one publish.
+func newRetryBudget() *retryBudget {
+	return &retryBudget{remaining: config.Load().PlatformRetryMaxWait}
+}
+
+// take reserves wait from the budget, reporting false (and reserving nothing)
+// when not enough is left.
+func (b *retryBudget) take(wait time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if wait > b.remaining {
+		return false
+	}
+	b.remaining -= wait
+	return true
+}
+
+// retryDo sends the request built by newRequest, retrying while the platform
+// answers 429 or 5xx, up to maxAttempts attempts in all. The wait doubles
+// from retryBaseDelay or follows the response's Retry-After, and is taken
+// from budget, which the publish's other calls share; a nil budget gives the
+// call one of its own. newRequest is called for every attempt, so the body is
+// sent again in full.
+//
+// Network errors are not retried, as the platform may have acted on the
+// request. The last response is returned for the caller to read and classify.
+func retryDo(client *http.Client, newRequest func() (*http.Request, error), maxAttempts int, budget *retryBudget) (*http.Response, error) {
+	if budget == nil {
+		budget = newRetryBudget()
+	}
+	delay := retryBaseDelay
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil || attempt >= maxAttempts || !retryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+
+		wait := delay
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			wait = after
+		}
+		if !budget.take(wait) {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		utils.Warnf("platform API retry host=%s path=%s status=%d attempt=%d wait=%s",
+			req.URL.Host, req.URL.Path, resp.StatusCode, attempt, wait)
+		time.Sleep(wait)
+		delay *= 2
+	}
+}
+
+// retryableStatus reports whether a platform response is worth retrying:
+// rate limited or a server error.
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			seconds = 0
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package publishers
+
+import "SocialMediaAPI/models"
+
+// requiredScopes lists the OAuth scopes each platform needs to publish. Each
+// entry is one requirement, met by any of its scopes (e.g. YouTube's broader
+// scopes also allow uploads); the first is the one the OAuth flow requests.
+// Facebook's granted permissions are read from /me/permissions on connect;
+// Instagram and Threads don't report them, so they are not checked.
+var requiredScopes = map[models.Platform][][]string{
+	models.Facebook: {{"pages_manage_posts"}},
+	models.Twitter:  {{"tweet.write"}},
+	models.LinkedIn: {{"w_member_social"}},
+	models.TikTok:   {{"video.publish"}},
+	models.YouTube: {{
+		"https://www.googleapis.com/auth/youtube.upload",
+		"https://www.googleapis.com/auth/youtube",
+		"https://www.googleapis.com/auth/youtube.force-ssl",
+	}},
+}
+
+// MissingScope returns a scope platform needs to publish that is not among
+// the granted ones, or "" when none is missing. Credentials whose granted
+// scopes are unknown (none recorded) are assumed to have them all.
+func MissingScope(platform models.Platform, granted []string) string {
+	if len(granted) == 0 {
+		return ""
+	}
+	has := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		has[scope] = true
+	}
+
+	for _, alternatives := range requiredScopes[platform] {
+		met := false
+		for _, scope := range alternatives {
+			if has[scope] {
+				met = true
+				break
+			}
+		}
+		if !met {
+			return alternatives[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,303 @@
+package publishers
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// threadsMaxCarouselItems is the most media a Threads carousel accepts.
+const threadsMaxCarouselItems = 20
+
+// ThreadsPublisher publishes through the Threads API, which works like
+// Instagram's Content Publishing API: create a media container with
+// POST /{user-id}/threads, wait for it to finish processing, then publish it
+// with POST /{user-id}/threads_publish.
+type ThreadsPublisher struct {
+	client  *http.Client
+	baseURL string
+}
+
+type threadsErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+func NewThreadsPublisher(client *http.Client, baseURL string) *ThreadsPublisher {
+	if client == nil {
+		client = utils.NewHTTPClient(30 * time.Second)
+	}
+	return &ThreadsPublisher{client: client, baseURL: baseURLOrDefault(baseURL, DefaultThreadsGraphBase)}
+}
+
+func (t *ThreadsPublisher) httpClient() *http.Client {
+	if t.client == nil {
+		t.client = utils.NewHTTPClient(30 * time.Second)
+	}
+	return t.client
+}
+
+// graphBase returns the Threads Graph API base URL, defaulting to production.
+func (t *ThreadsPublisher) graphBase() string {
+	return baseURLOrDefault(t.baseURL, DefaultThreadsGraphBase)
+}
+
+func (t *ThreadsPublisher) Publish(post *models.Post, cred *models.PlatformCredentials) models.PublishResult {
+	if cred == nil || cred.AccessToken == "" {
+		return reauthResult(models.Threads, models.ErrorCodeMissingCredentials, "Missing Threads credentials")
+	}
+
+	if cred.PlatformUserID == "" {
+		return reauthResult(models.Threads, models.ErrorCodeMissingCredentials,
+			"Threads account not connected correctly. Reconnect via OAuth to fetch the Threads user ID")
+	}
+
+	tokenValidator := utils.NewTokenValidator()
+	if tokenValidator.IsTokenExpired(cred) {
+		utils.Warnf("threads token expired post_id=%s user_id=%s", post.ID, post.UserID)
+		return reauthResult(models.Threads, models.ErrorCodeTokenExpired,
+			"Threads token has expired. Please reconnect your account via OAuth")
+	}
+
+	if post.PostType != "" && post.PostType != models.PostTypeNormal {
+		return errorResult(models.Threads, models.ErrorCategoryInvalidContent,
+			fmt.Sprintf("Threads does not support %s posts", post.PostType))
+	}
+
+	media := []*models.Media{}
+	for _, m := range post.Media {
+		if m.Type == models.MediaImage || m.Type == models.MediaVideo {
+			media = append(media, m)
+		}
+	}
+	if len(media) > threadsMaxCarouselItems {
+		return errorResult(models.Threads, models.ErrorCategoryInvalidContent,
+			fmt.Sprintf("Threads supports at most %d media attachments per post", threadsMaxCarouselItems))
+	}
+	for _, m := range media {
+		if strings.Contains(strings.ToLower(m.URL), "localhost") || strings.Contains(strings.ToLower(m.URL), "127.0.0.1") {
+			return errorResult(models.Threads, models.ErrorCategoryMediaError,
+				"Threads cannot fetch local media URLs. Use a public BASE_URL (e.g. HTTPS domain or tunnel) so Meta servers can access your files")
+		}
+	}
+
+	text := caption(post, models.Threads)
+
+	var containerID string
+	var err error
+	switch len(media) {
+	case 0:
+		containerID, err = t.createContainer(cred.PlatformUserID, cred.AccessToken, map[string]string{
+			"media_type": "TEXT",
+			"text":       text,
+		})
+	case 1:
+		params := t.mediaParams(media[0])
+		params["text"] = text
+		containerID, err = t.createContainer(cred.PlatformUserID, cred.AccessToken, params)
+	default:
+		containerID, err = t.createCarousel(text, media, cred.PlatformUserID, cred.AccessToken)
+	}
+	if err != nil {
+		return failureResult(models.Threads, fmt.Sprintf("Error creating Threads container: %v", err), err)
+	}
+
+	if err := t.waitContainerReady(containerID, cred.AccessToken); err != nil {
+		return failureResult(models.Threads, fmt.Sprintf("Error processing Threads media: %v", err), err)
+	}
+
+	postID, err := t.publishContainer(cred.PlatformUserID, cred.AccessToken, containerID)
+	if err != nil {
+		return failureResult(models.Threads, fmt.Sprintf("Error publishing to Threads: %v", err), err)
+	}
+
+	return models.PublishResult{
+		Platform: models.Threads,
+		Success:  true,
+		Message:  "Published successfully on Threads",
+		PostID:   postID,
+	}
+}
+
+// mediaParams returns the container parameters for one image or video.
+func (t *ThreadsPublisher) mediaParams(m *models.Media) map[string]string {
+	params := map[string]string{}
+	if m.Type == models.MediaVideo {
+		params["media_type"] = "VIDEO"
+		params["video_url"] = m.URL
+	} else {
+		params["media_type"] = "IMAGE"
+		params["image_url"] = m.URL
+	}
+	if m.AltText != "" {
+		params["alt_text"] = m.AltText
+	}
+	return params
+}
+
+// createCarousel creates one child container per media item, in order, and
+// returns the carousel container holding them.
+func (t *ThreadsPublisher) createCarousel(text string, media []*models.Media, threadsUserID, accessToken string) (string, error) {
+	children := make([]string, 0, len(media))
+	for _, m := range media {
+		params := t.mediaParams(m)
+		params["is_carousel_item"] = "true"
+		containerID, err := t.createContainer(threadsUserID, accessToken, params)
+		if err == nil {
+			err = t.waitContainerReady(containerID, accessToken)
+		}
+		if err != nil {
+			utils.Errorf("threads carousel child failed threads_user_id=%s media_id=%s err=%v", threadsUserID, m.ID, err)
+			return "", err
+		}
+		children = append(children, containerID)
+	}
+
+	return t.createContainer(threadsUserID, accessToken, map[string]string{
+		"media_type": "CAROUSEL",
+		"children":   strings.Join(children, ","),
+		"text":       text,
+	})
+}
+
+func (t *ThreadsPublisher) createContainer(threadsUserID, accessToken string, values map[string]string) (string, error) {
+	cfg := config.Load()
+	endpoint := fmt.Sprintf("%s/%s/%s/threads", t.graphBase(), cfg.ThreadsVersion, threadsUserID)
+
+	form := url.Values{}
+	for k, v := range values {
+		form.Set(k, v)
+	}
+	form.Set("access_token", accessToken)
+
+	body, err := t.postForm(endpoint, form)
+	if err != nil {
+		return "", err
+	}
+
+	var data struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", err
+	}
+	if data.ID == "" {
+		return "", fmt.Errorf("Threads container API returned empty container id")
+	}
+
+	return data.ID, nil
+}
+
+func (t *ThreadsPublisher) publishContainer(threadsUserID, accessToken, containerID string) (string, error) {
+	cfg := config.Load()
+	endpoint := fmt.Sprintf("%s/%s/%s/threads_publish", t.graphBase(), cfg.ThreadsVersion, threadsUserID)
+
+	form := url.Values{}
+	form.Set("creation_id", containerID)
+	form.Set("access_token", accessToken)
+
+	body, err := t.postForm(endpoint, form)
+	if err != nil {
+		return "", err
+	}
+
+	var data struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", err
+	}
+
+	return data.ID, nil
+}
+
+// postForm POSTs form to endpoint and returns the body of a 200 response.
+func (t *ThreadsPublisher) postForm(endpoint string, form url.Values) ([]byte, error) {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, t.apiError("Threads API error: %s", body)
+	}
+	return body, nil
+}
+
+// waitContainerReady polls the container until Threads has fetched and
+// processed its media. Text-only containers are usually ready immediately.
+func (t *ThreadsPublisher) waitContainerReady(containerID, accessToken string) error {
+	cfg := config.Load()
+	endpoint := fmt.Sprintf("%s/%s/%s?fields=status,error_message&access_token=%s", t.graphBase(), cfg.ThreadsVersion, containerID, url.QueryEscape(accessToken))
+
+	for attempt := 0; attempt < 30; attempt++ {
+		resp, err := t.httpClient().Get(endpoint)
+		if err != nil {
+			return err
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return t.apiError("Threads container status API error: %s", body)
+		}
+
+		var status struct {
+			Status       string `json:"status"`
+			ErrorMessage string `json:"error_message"`
+		}
+		if err := json.Unmarshal(body, &status); err != nil {
+			return err
+		}
+
+		switch status.Status {
+		case "FINISHED", "PUBLISHED", "":
+			return nil
+		case "ERROR", "EXPIRED":
+			return newPublishError(models.ErrorCategoryMediaError,
+				fmt.Errorf("Threads media processing failed: %s", sanitizeThreadsStatus(status.Status, status.ErrorMessage)))
+		}
+
+		time.Sleep(3 * time.Second)
+	}
+
+	return newPublishError(models.ErrorCategoryTransient, fmt.Errorf("Threads media processing timeout"))
+}
+
+// sanitizeThreadsStatus prefers Threads' error message over the bare status.
+func sanitizeThreadsStatus(status, message string) string {
+	if message != "" {
+		return message
+	}
+	return strings.ToLower(status)
+}
+
+// apiError formats a Threads API error response. Threads shares the Graph
+// API error codes, so token and permission failures are classified the same
+// way as for Facebook and Instagram.
+func (t *ThreadsPublisher) apiError(format string, body []byte) error {
+	var thErr threadsErrorResponse
+	if err := json.Unmarshal(body, &thErr); err == nil && thErr.Error.Message != "" {
+		return metaError(thErr.Error.Code, fmt.Errorf(format, thErr.Error.Message))
+	}
+	return fmt.Errorf(format, string(body))
+}
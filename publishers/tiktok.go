@@ -9,13 +9,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 )
 
 type TikTokPublisher struct {
-	client *http.Client
+	client  *http.Client
+	baseURL string
 }
 
 type tiktokErrorResponse struct {
@@ -26,52 +28,48 @@ type tiktokErrorResponse struct {
 	} `json:"error"`
 }
 
-// NewTikTokPublisher creates a TikTokPublisher with an injectable http.Client.
-func NewTikTokPublisher(client *http.Client) *TikTokPublisher {
+// NewTikTokPublisher creates a TikTokPublisher with an injectable http.Client
+// and API base URL. An empty baseURL uses the production API.
+func NewTikTokPublisher(client *http.Client, baseURL string) *TikTokPublisher {
 	if client == nil {
-		client = &http.Client{Timeout: 60 * time.Second}
+		client = utils.NewHTTPClient(60 * time.Second)
 	}
-	return &TikTokPublisher{client: client}
+	return &TikTokPublisher{client: client, baseURL: baseURLOrDefault(baseURL, DefaultTikTokAPIBase)}
 }
 
 func (t *TikTokPublisher) httpClient() *http.Client {
 	if t.client == nil {
-		t.client = &http.Client{Timeout: 60 * time.Second}
+		t.client = utils.NewHTTPClient(60 * time.Second)
 	}
 	return t.client
 }
 
+// apiBase returns the TikTok API base URL, defaulting to production.
+func (t *TikTokPublisher) apiBase() string {
+	return baseURLOrDefault(t.baseURL, DefaultTikTokAPIBase)
+}
+
 func (t *TikTokPublisher) Publish(post *models.Post, cred *models.PlatformCredentials) models.PublishResult {
 	utils.Infof("tiktok publish started post_id=%s user_id=%s media_count=%d post_type=%s", post.ID, post.UserID, len(post.Media), post.PostType)
 
 	if cred == nil || cred.AccessToken == "" {
 		utils.Warnf("tiktok publish missing credentials post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.TikTok,
-			Success:  false,
-			Message:  "Missing TikTok credentials",
-		}
+		return reauthResult(models.TikTok, models.ErrorCodeMissingCredentials, "Missing TikTok credentials")
 	}
 
 	// Check if token is expired
 	tokenValidator := utils.NewTokenValidator()
 	if tokenValidator.IsTokenExpired(cred) {
 		utils.Warnf("tiktok token expired post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.TikTok,
-			Success:  false,
-			Message:  "TikTok token has expired. Please reconnect your account via OAuth",
-		}
+		return reauthResult(models.TikTok, models.ErrorCodeTokenExpired,
+			"TikTok token has expired. Please reconnect your account via OAuth")
 	}
 
 	// TikTok only supports short-form video posts
 	if post.PostType != models.PostTypeShort {
 		utils.Warnf("tiktok publish rejected: unsupported post_type post_id=%s post_type=%s", post.ID, post.PostType)
-		return models.PublishResult{
-			Platform: models.TikTok,
-			Success:  false,
-			Message:  "TikTok only supports short-form video posts (post_type must be 'short')",
-		}
+		return errorResult(models.TikTok, models.ErrorCategoryUnsupported,
+			"TikTok only supports short-form video posts (post_type must be 'short')")
 	}
 
 	// Find the video media
@@ -85,11 +83,7 @@ func (t *TikTokPublisher) Publish(post *models.Post, cred *models.PlatformCreden
 
 	if videoMedia == nil {
 		utils.Warnf("tiktok publish no video found post_id=%s", post.ID)
-		return models.PublishResult{
-			Platform: models.TikTok,
-			Success:  false,
-			Message:  "TikTok requires a video attachment",
-		}
+		return errorResult(models.TikTok, models.ErrorCategoryInvalidContent, "TikTok requires a video attachment")
 	}
 
 	// Step 1: Query creator info to validate privacy level options
@@ -105,25 +99,17 @@ func (t *TikTokPublisher) Publish(post *models.Post, cred *models.PlatformCreden
 	utils.Infof("tiktok resolved privacy_level=%s post_id=%s", tiktokPrivacy, post.ID)
 
 	// Step 2: Initialize the video upload via TikTok Content Posting API
-	uploadURL, publishID, err := t.initVideoUpload(cred.AccessToken, videoMedia, post.Content, post.IsSponsored, tiktokPrivacy)
+	uploadURL, publishID, err := t.initVideoUpload(cred.AccessToken, videoMedia, caption(post, models.TikTok), post.IsSponsored, tiktokPrivacy)
 	if err != nil {
 		utils.Errorf("tiktok init upload failed post_id=%s err=%v", post.ID, err)
-		return models.PublishResult{
-			Platform: models.TikTok,
-			Success:  false,
-			Message:  fmt.Sprintf("Failed to initialize TikTok upload: %v", err),
-		}
+		return failureResult(models.TikTok, fmt.Sprintf("Failed to initialize TikTok upload: %v", err), err)
 	}
 	utils.Infof("tiktok init upload success post_id=%s publish_id=%s", post.ID, publishID)
 
 	// Step 3: Upload the video file to the provided URL
 	if err := t.uploadVideoFile(uploadURL, videoMedia); err != nil {
 		utils.Errorf("tiktok video upload failed post_id=%s publish_id=%s err=%v", post.ID, publishID, err)
-		return models.PublishResult{
-			Platform: models.TikTok,
-			Success:  false,
-			Message:  fmt.Sprintf("Failed to upload video to TikTok: %v", err),
-		}
+		return failureResult(models.TikTok, fmt.Sprintf("Failed to upload video to TikTok: %v", err), err)
 	}
 	utils.Infof("tiktok video upload success post_id=%s publish_id=%s", post.ID, publishID)
 
@@ -131,11 +117,7 @@ func (t *TikTokPublisher) Publish(post *models.Post, cred *models.PlatformCreden
 	finalStatus, err := t.waitForPublish(cred.AccessToken, publishID)
 	if err != nil {
 		utils.Errorf("tiktok publish status check failed post_id=%s publish_id=%s err=%v", post.ID, publishID, err)
-		return models.PublishResult{
-			Platform: models.TikTok,
-			Success:  false,
-			Message:  fmt.Sprintf("TikTok publish status check failed: %v", err),
-		}
+		return failureResult(models.TikTok, fmt.Sprintf("TikTok publish status check failed: %v", err), err)
 	}
 
 	utils.Infof("tiktok publish completed post_id=%s publish_id=%s status=%s", post.ID, publishID, finalStatus)
@@ -154,7 +136,7 @@ func (t *TikTokPublisher) initVideoUpload(accessToken string, media *models.Medi
 	cfg := config.Load()
 	_ = cfg // reserved for future version config
 
-	endpoint := "https://open.tiktokapis.com/v2/post/publish/video/init/"
+	endpoint := t.apiBase() + "/v2/post/publish/video/init/"
 
 	// Get file size
 	fileInfo, err := os.Stat(media.Path)
@@ -163,11 +145,6 @@ func (t *TikTokPublisher) initVideoUpload(accessToken string, media *models.Medi
 	}
 	fileSize := fileInfo.Size()
 
-	// TikTok enforces a 150-character title limit.
-	if len(title) > 150 {
-		title = title[:150]
-	}
-
 	// Prepare the request body.
 	// brand_content_toggle and brand_organic_toggle are REQUIRED by TikTok's
 	// content sharing guidelines (https://developers.tiktok.com/doc/content-sharing-guidelines/).
@@ -215,7 +192,8 @@ func (t *TikTokPublisher) initVideoUpload(accessToken string, media *models.Medi
 
 	if resp.StatusCode != http.StatusOK {
 		utils.Errorf("tiktok init upload API error status=%d body=%s", resp.StatusCode, string(body))
-		return "", "", fmt.Errorf("TikTok API error (status %d): %s", resp.StatusCode, t.parseTikTokError(body))
+		return "", "", t.classifyError(resp.StatusCode, body,
+			fmt.Errorf("TikTok API error (status %d): %s", resp.StatusCode, t.parseTikTokError(body)))
 	}
 
 	var initResp struct {
@@ -234,7 +212,8 @@ func (t *TikTokPublisher) initVideoUpload(accessToken string, media *models.Medi
 	}
 
 	if initResp.Error.Code != "" && initResp.Error.Code != "ok" {
-		return "", "", fmt.Errorf("TikTok init error: %s - %s", initResp.Error.Code, initResp.Error.Message)
+		return "", "", t.classifyError(resp.StatusCode, body,
+			fmt.Errorf("TikTok init error: %s - %s", initResp.Error.Code, initResp.Error.Message))
 	}
 
 	if initResp.Data.UploadURL == "" {
@@ -273,7 +252,7 @@ func (t *TikTokPublisher) uploadVideoFile(uploadURL string, media *models.Media)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("TikTok upload error (status %d): %s", resp.StatusCode, string(body))
+		return httpError(resp.StatusCode, fmt.Errorf("TikTok upload error (status %d): %s", resp.StatusCode, string(body)))
 	}
 
 	return nil
@@ -281,7 +260,9 @@ func (t *TikTokPublisher) uploadVideoFile(uploadURL string, media *models.Media)
 
 // waitForPublish polls TikTok's publish status endpoint until the video is published or fails.
 func (t *TikTokPublisher) waitForPublish(accessToken, publishID string) (string, error) {
-	endpoint := "https://open.tiktokapis.com/v2/post/publish/status/fetch/"
+	endpoint := t.apiBase() + "/v2/post/publish/status/fetch/"
+	// The retries of every status check share one wait budget
+	budget := newRetryBudget()
 
 	for attempt := 0; attempt < 15; attempt++ {
 		payload := map[string]string{
@@ -289,14 +270,15 @@ func (t *TikTokPublisher) waitForPublish(accessToken, publishID string) (string,
 		}
 		jsonData, _ := json.Marshal(payload)
 
-		req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
-		if err != nil {
-			return "", err
-		}
-		req.Header.Set("Authorization", "Bearer "+accessToken)
-		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
-
-		resp, err := t.httpClient().Do(req)
+		resp, err := retryDo(t.httpClient(), func() (*http.Request, error) {
+			req, err := http.NewRequest("POST", endpoint, bytes.NewReader(jsonData))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+			return req, nil
+		}, config.Load().PlatformRetryAttempts, budget)
 		if err != nil {
 			return "", fmt.Errorf("status check request failed: %w", err)
 		}
@@ -305,12 +287,13 @@ func (t *TikTokPublisher) waitForPublish(accessToken, publishID string) (string,
 		resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			return "", fmt.Errorf("TikTok status API error (status %d): %s", resp.StatusCode, string(body))
+			return "", t.classifyError(resp.StatusCode, body, fmt.Errorf("TikTok status API error (status %d): %s", resp.StatusCode, string(body)))
 		}
 
 		var statusResp struct {
 			Data struct {
-				Status string `json:"status"`
+				Status     string `json:"status"`
+				FailReason string `json:"fail_reason"`
 			} `json:"data"`
 			Error struct {
 				Code    string `json:"code"`
@@ -333,14 +316,41 @@ func (t *TikTokPublisher) waitForPublish(accessToken, publishID string) (string,
 			if errMsg == "" {
 				errMsg = "TikTok video processing failed"
 			}
-			return status, fmt.Errorf("tiktok publish failed: %s", errMsg)
+			return status, t.classifyCode(statusResp.Data.FailReason, 0, fmt.Errorf("tiktok publish failed: %s", errMsg))
 		}
 
 		// PROCESSING_UPLOAD, PROCESSING_DOWNLOAD, or SENDING_TO_USER_INBOX
 		time.Sleep(3 * time.Second)
 	}
 
-	return "TIMEOUT", fmt.Errorf("TikTok video processing timeout after 45 seconds")
+	return "TIMEOUT", newPublishError(models.ErrorCategoryTransient, fmt.Errorf("TikTok video processing timeout after 45 seconds"))
+}
+
+// classifyError categorizes a TikTok API error from its error code, falling
+// back to the HTTP status.
+func (t *TikTokPublisher) classifyError(statusCode int, body []byte, err error) error {
+	var ttErr tiktokErrorResponse
+	json.Unmarshal(body, &ttErr)
+	return t.classifyCode(ttErr.Error.Code, statusCode, err)
+}
+
+func (t *TikTokPublisher) classifyCode(code string, statusCode int, err error) error {
+	switch code {
+	case "access_token_invalid":
+		return newAuthError(models.ErrorCodeTokenInvalid, err)
+	case "scope_not_authorized":
+		return newAuthError(models.ErrorCodeInsufficientScope, err)
+	case "rate_limit_exceeded", "spam_risk_too_many_posts", "spam_risk_too_many_pending_share":
+		return newPublishError(models.ErrorCategoryRateLimited, err)
+	case "file_format_check_failed", "duration_check_failed", "frame_rate_check_failed",
+		"picture_size_check_failed", "video_pull_failed", "photo_pull_failed":
+		return newPublishError(models.ErrorCategoryMediaError, err)
+	case "privacy_level_option_mismatch", "spam_risk_text", "spam_risk_user_banned_from_posting":
+		return newPublishError(models.ErrorCategoryInvalidContent, err)
+	case "internal_error":
+		return newPublishError(models.ErrorCategoryTransient, err)
+	}
+	return httpError(statusCode, err)
 }
 
 func (t *TikTokPublisher) parseTikTokError(body []byte) string {
@@ -381,7 +391,7 @@ func containsPrivacyLevel(levels []string, target string) bool {
 // the privacy_level_options the authenticated user has enabled.
 // Returns the list of available privacy levels (e.g. ["PUBLIC_TO_EVERYONE","SELF_ONLY"]).
 func (t *TikTokPublisher) queryCreatorInfo(accessToken string) ([]string, error) {
-	endpoint := "https://open.tiktokapis.com/v2/post/publish/creator_info/query/"
+	endpoint := t.apiBase() + "/v2/post/publish/creator_info/query/"
 
 	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer([]byte("{}")))
 	if err != nil {
@@ -399,7 +409,8 @@ func (t *TikTokPublisher) queryCreatorInfo(accessToken string) ([]string, error)
 	body, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("creator info API error (status %d): %s", resp.StatusCode, t.parseTikTokError(body))
+		return nil, t.classifyError(resp.StatusCode, body,
+			fmt.Errorf("creator info API error (status %d): %s", resp.StatusCode, t.parseTikTokError(body)))
 	}
 
 	var infoResp struct {
@@ -422,4 +433,63 @@ func (t *TikTokPublisher) queryCreatorInfo(accessToken string) ([]string, error)
 
 	utils.Debugf("tiktok creator info privacy_level_options=%v", infoResp.Data.PrivacyLevelOptions)
 	return infoResp.Data.PrivacyLevelOptions, nil
-}
\ No newline at end of file
+}
+
+// RefreshToken implements TokenRefresher. TikTok access tokens last 24 hours
+// and refresh tokens a year; the refresh token is rotated on every refresh,
+// so both are replaced.
+func (t *TikTokPublisher) RefreshToken(cred *models.PlatformCredentials) error {
+	cfg := config.Load()
+
+	form := url.Values{}
+	form.Set("client_key", cfg.TikTokClientKey)
+	form.Set("client_secret", cfg.TikTokClientSecret)
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", cred.RefreshToken)
+
+	req, err := http.NewRequest("POST", t.apiBase()+"/v2/oauth/token/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("tiktok token refresh request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tiktok token refresh failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return fmt.Errorf("failed to parse token refresh response: %w", err)
+	}
+	// An invalid refresh token is reported with a 200 and an error field
+	if tokenResp.Error != "" {
+		return fmt.Errorf("tiktok token refresh failed: %s - %s", tokenResp.Error, tokenResp.ErrorDescription)
+	}
+	if tokenResp.AccessToken == "" {
+		return fmt.Errorf("tiktok token refresh returned empty access token")
+	}
+
+	cred.AccessToken = tokenResp.AccessToken
+	if tokenResp.RefreshToken != "" {
+		cred.RefreshToken = tokenResp.RefreshToken
+	}
+	cred.ExpiresAt = nil
+	if tokenResp.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+		cred.ExpiresAt = &expiresAt
+	}
+	return nil
+}
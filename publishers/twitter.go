@@ -1,15 +1,18 @@
 package publishers
 
 import (
+	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -17,9 +20,26 @@ import (
 	"time"
 )
 
+// twitterDuplicateMessage is shown when Twitter rejects a tweet because it is
+// identical to one the account posted recently.
+const twitterDuplicateMessage = "Twitter rejected this as a duplicate of a recent tweet."
+
+// twitterMaxAltTextLength is the longest alt text Twitter accepts, in
+// characters.
+const twitterMaxAltTextLength = 1000
+
+// twitterSimpleUploadMaxBytes is the largest image accepted by the simple
+// (non-chunked) media upload.
+const twitterSimpleUploadMaxBytes = 5 << 20
+
+// errTwitterDuplicate is returned by createTweet for duplicate-content rejections.
+var errTwitterDuplicate = errors.New(twitterDuplicateMessage)
+
 // TwitterPublisher implements PlatformPublisher for the Twitter/X API v2.
 type TwitterPublisher struct {
-	client *http.Client
+	client        *http.Client
+	baseURL       string
+	uploadBaseURL string
 }
 
 // twitterErrorResponse represents a Twitter API v2 error payload.
@@ -57,22 +77,37 @@ type twitterMediaUploadResponse struct {
 	} `json:"processing_info"`
 }
 
-// NewTwitterPublisher creates a TwitterPublisher with an injectable http.Client.
-// If nil is passed a default client with a sensible timeout is used.
-func NewTwitterPublisher(client *http.Client) *TwitterPublisher {
+// NewTwitterPublisher creates a TwitterPublisher with an injectable http.Client
+// and API base URLs. If nil is passed a default client with a sensible timeout
+// is used; empty base URLs use the production API and upload hosts.
+func NewTwitterPublisher(client *http.Client, baseURL, uploadBaseURL string) *TwitterPublisher {
 	if client == nil {
-		client = &http.Client{Timeout: 60 * time.Second}
+		client = utils.NewHTTPClient(60 * time.Second)
+	}
+	return &TwitterPublisher{
+		client:        client,
+		baseURL:       baseURLOrDefault(baseURL, DefaultTwitterAPIBase),
+		uploadBaseURL: baseURLOrDefault(uploadBaseURL, DefaultTwitterUploadBase),
 	}
-	return &TwitterPublisher{client: client}
 }
 
 func (t *TwitterPublisher) httpClient() *http.Client {
 	if t.client == nil {
-		t.client = &http.Client{Timeout: 60 * time.Second}
+		t.client = utils.NewHTTPClient(60 * time.Second)
 	}
 	return t.client
 }
 
+// apiBase returns the v2 API base URL, defaulting to production.
+func (t *TwitterPublisher) apiBase() string {
+	return baseURLOrDefault(t.baseURL, DefaultTwitterAPIBase)
+}
+
+// uploadBase returns the v1.1 media upload base URL, defaulting to production.
+func (t *TwitterPublisher) uploadBase() string {
+	return baseURLOrDefault(t.uploadBaseURL, DefaultTwitterUploadBase)
+}
+
 // Publish implements PlatformPublisher. It rejects short-form posts and
 // publishes either text-only tweets or tweets with media attachments.
 func (t *TwitterPublisher) Publish(post *models.Post, cred *models.PlatformCredentials) models.PublishResult {
@@ -80,42 +115,34 @@ func (t *TwitterPublisher) Publish(post *models.Post, cred *models.PlatformCrede
 
 	if cred == nil || cred.AccessToken == "" {
 		utils.Warnf("twitter publish missing credentials post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.Twitter,
-			Success:  false,
-			Message:  "Missing Twitter credentials",
-		}
+		return reauthResult(models.Twitter, models.ErrorCodeMissingCredentials, "Missing Twitter credentials")
 	}
 
 	// Check if token is expired
 	tokenValidator := utils.NewTokenValidator()
 	if tokenValidator.IsTokenExpired(cred) {
 		utils.Warnf("twitter token expired post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.Twitter,
-			Success:  false,
-			Message:  "Twitter token has expired. Please reconnect your account via OAuth",
-		}
+		return reauthResult(models.Twitter, models.ErrorCodeTokenExpired,
+			"Twitter token has expired. Please reconnect your account via OAuth")
 	}
 
 	// Twitter/X does NOT support short-form video posts (Reels/Shorts).
 	if post.PostType == models.PostTypeShort {
 		utils.Warnf("twitter publish rejected: shorts not supported post_id=%s", post.ID)
-		return models.PublishResult{
-			Platform: models.Twitter,
-			Success:  false,
-			Message:  "Twitter does not support short-form video posts. Use post_type 'normal' instead",
-		}
+		return errorResult(models.Twitter, models.ErrorCategoryUnsupported,
+			"Twitter does not support short-form video posts. Use post_type 'normal' instead")
 	}
 
 	// Twitter/X does NOT support stories.
 	if post.PostType == models.PostTypeStory {
 		utils.Warnf("twitter publish rejected: stories not supported post_id=%s", post.ID)
-		return models.PublishResult{
-			Platform: models.Twitter,
-			Success:  false,
-			Message:  "Twitter does not support stories. Use post_type 'normal' instead",
-		}
+		return errorResult(models.Twitter, models.ErrorCategoryUnsupported,
+			"Twitter does not support stories. Use post_type 'normal' instead")
+	}
+
+	// Long content of a threaded post becomes a thread
+	if content := post.ContentFor(models.Twitter); Threaded(post, models.Twitter) && utils.RuneLen(content) > twitterMaxTextLength {
+		return t.publishThread(post, utils.SplitRunes(content, twitterMaxTextLength), cred.AccessToken, newRetryBudget())
 	}
 
 	// Publish with or without media
@@ -124,19 +151,20 @@ func (t *TwitterPublisher) Publish(post *models.Post, cred *models.PlatformCrede
 
 	if len(post.Media) > 0 {
 		utils.Infof("twitter publish mode=media post_id=%s media_count=%d", post.ID, len(post.Media))
-		tweetID, err = t.publishWithMedia(post, cred.AccessToken)
+		tweetID, err = t.publishWithMedia(post, caption(post, models.Twitter), cred.AccessToken, nil)
 	} else {
 		utils.Infof("twitter publish mode=text post_id=%s", post.ID)
-		tweetID, err = t.publishTextOnly(post.Content, cred.AccessToken)
+		tweetID, err = t.publishTextOnly(caption(post, models.Twitter), cred.AccessToken, nil)
+	}
+
+	if errors.Is(err, errTwitterDuplicate) {
+		utils.Warnf("twitter publish rejected as duplicate post_id=%s", post.ID)
+		return errorResult(models.Twitter, models.ErrorCategoryInvalidContent, twitterDuplicateMessage)
 	}
 
 	if err != nil {
 		utils.Errorf("twitter publish failed post_id=%s err=%v", post.ID, err)
-		return models.PublishResult{
-			Platform: models.Twitter,
-			Success:  false,
-			Message:  fmt.Sprintf("Error publishing to Twitter: %v", err),
-		}
+		return failureResult(models.Twitter, fmt.Sprintf("Error publishing to Twitter: %v", err), err)
 	}
 
 	utils.Infof("twitter publish succeeded post_id=%s external_tweet_id=%s", post.ID, tweetID)
@@ -149,19 +177,69 @@ func (t *TwitterPublisher) Publish(post *models.Post, cred *models.PlatformCrede
 	}
 }
 
+// publishThread publishes parts as a thread: the first tweet carries the
+// post's media and every following one replies to the one before. The
+// result's PostID is the first tweet. When a reply fails the tweets
+// published so far stay up, and the result says how far the thread got.
+// Retries of all its tweets wait within budget.
+func (t *TwitterPublisher) publishThread(post *models.Post, parts []string, accessToken string, budget *retryBudget) models.PublishResult {
+	utils.Infof("twitter publish mode=thread post_id=%s tweets=%d media_count=%d", post.ID, len(parts), len(post.Media))
+
+	var firstID string
+	var err error
+	if len(post.Media) > 0 {
+		firstID, err = t.publishWithMedia(post, parts[0], accessToken, budget)
+	} else {
+		firstID, err = t.publishTextOnly(parts[0], accessToken, budget)
+	}
+	if errors.Is(err, errTwitterDuplicate) {
+		utils.Warnf("twitter thread rejected as duplicate post_id=%s", post.ID)
+		return errorResult(models.Twitter, models.ErrorCategoryInvalidContent, twitterDuplicateMessage)
+	}
+	if err != nil {
+		utils.Errorf("twitter thread failed post_id=%s err=%v", post.ID, err)
+		return failureResult(models.Twitter, fmt.Sprintf("Error publishing to Twitter: %v", err), err)
+	}
+
+	previousID := firstID
+	for i, text := range parts[1:] {
+		previousID, err = t.createTweet(map[string]interface{}{
+			"text":  text,
+			"reply": map[string]string{"in_reply_to_tweet_id": previousID},
+		}, accessToken, budget)
+		if err != nil {
+			utils.Errorf("twitter thread reply failed post_id=%s tweet=%d/%d err=%v", post.ID, i+2, len(parts), err)
+			result := failureResult(models.Twitter,
+				fmt.Sprintf("Published %d of %d tweets of the thread on Twitter, then failed: %v", i+1, len(parts), err), err)
+			result.PostID = firstID
+			return result
+		}
+	}
+
+	utils.Infof("twitter thread published post_id=%s external_tweet_id=%s tweets=%d", post.ID, firstID, len(parts))
+
+	return models.PublishResult{
+		Platform: models.Twitter,
+		Success:  true,
+		Message:  fmt.Sprintf("Published successfully on Twitter as a thread of %d tweets", len(parts)),
+		PostID:   firstID,
+	}
+}
+
 // publishTextOnly creates a text-only tweet via Twitter API v2.
-func (t *TwitterPublisher) publishTextOnly(text string, accessToken string) (string, error) {
+func (t *TwitterPublisher) publishTextOnly(text string, accessToken string, budget *retryBudget) (string, error) {
 	utils.Debugf("twitter posting text content")
 
 	payload := map[string]interface{}{
 		"text": text,
 	}
 
-	return t.createTweet(payload, accessToken)
+	return t.createTweet(payload, accessToken, budget)
 }
 
-// publishWithMedia uploads media attachments then creates a tweet referencing them.
-func (t *TwitterPublisher) publishWithMedia(post *models.Post, accessToken string) (string, error) {
+// publishWithMedia uploads media attachments then creates a tweet with text
+// referencing them.
+func (t *TwitterPublisher) publishWithMedia(post *models.Post, text, accessToken string, budget *retryBudget) (string, error) {
 	mediaIDs := []string{}
 
 	for _, media := range post.Media {
@@ -170,35 +248,52 @@ func (t *TwitterPublisher) publishWithMedia(post *models.Post, accessToken strin
 			return "", fmt.Errorf("failed to upload media %s: %w", media.ID, err)
 		}
 		utils.Debugf("twitter media uploaded media_id=%s twitter_media_id=%s", media.ID, mediaID)
+
+		// Subtitles can only be attached once the video has finished processing,
+		// which uploadMedia waits for.
+		if media.Type == models.MediaVideo && post.Subtitles != "" {
+			if err := t.attachSubtitles(mediaID, post.Subtitles, post.SubtitleLanguage, accessToken); err != nil {
+				return "", err
+			}
+		}
+
+		// Twitter only takes alt text for images and GIFs
+		if media.Type != models.MediaVideo && media.AltText != "" {
+			if err := t.setAltText(mediaID, media.AltText, accessToken); err != nil {
+				return "", err
+			}
+		}
 		mediaIDs = append(mediaIDs, mediaID)
 	}
 
 	// Twitter allows up to 4 images or 1 video per tweet
 	payload := map[string]interface{}{
-		"text": post.Content,
+		"text": text,
 		"media": map[string]interface{}{
 			"media_ids": mediaIDs,
 		},
 	}
 
-	return t.createTweet(payload, accessToken)
+	return t.createTweet(payload, accessToken, budget)
 }
 
-// createTweet calls POST /2/tweets and returns the tweet ID.
-func (t *TwitterPublisher) createTweet(payload map[string]interface{}, accessToken string) (string, error) {
+// createTweet calls POST /2/tweets and returns the tweet ID. Retries wait
+// within budget; nil gives the call a budget of its own.
+func (t *TwitterPublisher) createTweet(payload map[string]interface{}, accessToken string, budget *retryBudget) (string, error) {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal tweet payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.x.com/2/tweets", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-
-	resp, err := t.httpClient().Do(req)
+	resp, err := retryDo(t.httpClient(), func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", t.apiBase()+"/2/tweets", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		return req, nil
+	}, config.Load().PlatformRetryAttempts, budget)
 	if err != nil {
 		return "", fmt.Errorf("twitter API request failed: %w", err)
 	}
@@ -206,10 +301,14 @@ func (t *TwitterPublisher) createTweet(payload map[string]interface{}, accessTok
 
 	body, _ := io.ReadAll(resp.Body)
 
+	if resp.StatusCode == http.StatusForbidden && isTwitterDuplicateError(body) {
+		return "", errTwitterDuplicate
+	}
+
 	if resp.StatusCode != http.StatusCreated {
 		errMsg := t.parseTwitterError(body)
 		utils.Errorf("twitter create tweet API error status=%d body=%s", resp.StatusCode, errMsg)
-		return "", fmt.Errorf("Twitter API error (status %d): %s", resp.StatusCode, errMsg)
+		return "", httpError(resp.StatusCode, fmt.Errorf("Twitter API error (status %d): %s", resp.StatusCode, errMsg))
 	}
 
 	var tweetResp twitterTweetResponse
@@ -224,19 +323,38 @@ func (t *TwitterPublisher) createTweet(payload map[string]interface{}, accessTok
 	return tweetResp.Data.ID, nil
 }
 
+// twitterMediaCategory picks the upload media_category for a file. An explicit
+// override on the media wins; otherwise it is derived from the MIME type.
+// Animated GIFs must be uploaded as tweet_gif or Twitter treats them as stills.
+func twitterMediaCategory(media *models.Media) string {
+	if models.TwitterMediaCategories[media.TwitterMediaCategory] {
+		return media.TwitterMediaCategory
+	}
+	switch {
+	case media.Type == models.MediaVideo:
+		return "tweet_video"
+	case media.MimeType == "image/gif":
+		return "tweet_gif"
+	default:
+		return "tweet_image"
+	}
+}
+
 // uploadMedia uploads a single media file to Twitter via the v1.1 media upload endpoint.
-// For images it uses the simple upload; for videos it uses the chunked INIT/APPEND/FINALIZE flow.
+// Still images under the simple-upload limit use the simple upload; videos,
+// GIFs and larger images use the chunked INIT/APPEND/FINALIZE flow.
 func (t *TwitterPublisher) uploadMedia(media *models.Media, accessToken string) (string, error) {
-	if media.Type == models.MediaVideo {
-		return t.uploadMediaChunked(media, accessToken)
+	category := twitterMediaCategory(media)
+	if category != "tweet_image" || media.Size > twitterSimpleUploadMaxBytes {
+		return t.uploadMediaChunked(media, category, accessToken)
 	}
 
 	// Simple upload for images
-	return t.uploadMediaSimple(media, accessToken)
+	return t.uploadMediaSimple(media, category, accessToken)
 }
 
 // uploadMediaSimple performs a simple multipart media upload (suitable for images).
-func (t *TwitterPublisher) uploadMediaSimple(media *models.Media, accessToken string) (string, error) {
+func (t *TwitterPublisher) uploadMediaSimple(media *models.Media, category, accessToken string) (string, error) {
 	utils.Debugf("twitter simple media upload media_id=%s path=%s", media.ID, media.Path)
 
 	file, err := os.Open(media.Path)
@@ -256,9 +374,10 @@ func (t *TwitterPublisher) uploadMediaSimple(media *models.Media, accessToken st
 	if _, err := io.Copy(part, file); err != nil {
 		return "", fmt.Errorf("failed to copy media data: %w", err)
 	}
+	writer.WriteField("media_category", category)
 	writer.Close()
 
-	req, err := http.NewRequest("POST", "https://upload.x.com/1.1/media/upload.json", &buf)
+	req, err := http.NewRequest("POST", t.uploadBase()+"/1.1/media/upload.json", &buf)
 	if err != nil {
 		return "", err
 	}
@@ -275,7 +394,7 @@ func (t *TwitterPublisher) uploadMediaSimple(media *models.Media, accessToken st
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		errMsg := t.parseTwitterError(body)
-		return "", fmt.Errorf("twitter media upload failed (status %d): %s", resp.StatusCode, errMsg)
+		return "", httpError(resp.StatusCode, fmt.Errorf("twitter media upload failed (status %d): %s", resp.StatusCode, errMsg))
 	}
 
 	var uploadResp twitterMediaUploadResponse
@@ -291,9 +410,9 @@ func (t *TwitterPublisher) uploadMediaSimple(media *models.Media, accessToken st
 	return uploadResp.MediaIDString, nil
 }
 
-// uploadMediaChunked uses the INIT / APPEND / FINALIZE flow for video uploads.
-func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken string) (string, error) {
-	utils.Debugf("twitter chunked media upload media_id=%s path=%s", media.ID, media.Path)
+// uploadMediaChunked uses the INIT / APPEND / FINALIZE flow for video, GIF and large image uploads.
+func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, category, accessToken string) (string, error) {
+	utils.Debugf("twitter chunked media upload media_id=%s path=%s media_category=%s", media.ID, media.Path, category)
 
 	fileInfo, err := os.Stat(media.Path)
 	if err != nil {
@@ -307,11 +426,29 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 		mediaType = "video/mp4"
 	}
 
+	file, err := os.Open(media.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open video file: %w", err)
+	}
+	defer file.Close()
+
+	mediaIDStr, err := t.uploadChunked(file, totalBytes, mediaType, category, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	utils.Debugf("twitter chunked media upload success twitter_media_id=%s", mediaIDStr)
+	return mediaIDStr, nil
+}
+
+// uploadChunked runs the INIT / APPEND / FINALIZE flow for data read from r
+// and waits for any server-side processing. It returns the Twitter media ID.
+func (t *TwitterPublisher) uploadChunked(r io.Reader, totalBytes int64, mediaType, mediaCategory, accessToken string) (string, error) {
 	// --- INIT ---
-	initPayload := fmt.Sprintf("command=INIT&media_type=%s&total_bytes=%d&media_category=tweet_video",
-		mediaType, totalBytes)
+	initPayload := fmt.Sprintf("command=INIT&media_type=%s&total_bytes=%d&media_category=%s",
+		url.QueryEscape(mediaType), totalBytes, mediaCategory)
 
-	req, err := http.NewRequest("POST", "https://upload.x.com/1.1/media/upload.json",
+	req, err := http.NewRequest("POST", t.uploadBase()+"/1.1/media/upload.json",
 		strings.NewReader(initPayload))
 	if err != nil {
 		return "", err
@@ -327,7 +464,8 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		return "", fmt.Errorf("twitter INIT failed (status %d): %s", resp.StatusCode, t.parseTwitterError(body))
+		return "", httpError(resp.StatusCode,
+			fmt.Errorf("twitter INIT failed (status %d): %s", resp.StatusCode, t.parseTwitterError(body)))
 	}
 
 	var initResp twitterMediaUploadResponse
@@ -342,16 +480,10 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 
 	// --- APPEND (upload in 5 MB chunks) ---
 	const chunkSize = 5 * 1024 * 1024 // 5 MB
-	file, err := os.Open(media.Path)
-	if err != nil {
-		return "", fmt.Errorf("failed to open video file: %w", err)
-	}
-	defer file.Close()
-
 	segmentIndex := 0
 	chunkBuf := make([]byte, chunkSize)
 	for {
-		n, readErr := file.Read(chunkBuf)
+		n, readErr := io.ReadFull(r, chunkBuf)
 		if n == 0 && readErr != nil {
 			break
 		}
@@ -360,9 +492,9 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 		encoded := base64.StdEncoding.EncodeToString(chunk)
 
 		appendPayload := fmt.Sprintf("command=APPEND&media_id=%s&segment_index=%d&media_data=%s",
-			mediaIDStr, segmentIndex, encoded)
+			mediaIDStr, segmentIndex, url.QueryEscape(encoded))
 
-		appendReq, err := http.NewRequest("POST", "https://upload.x.com/1.1/media/upload.json",
+		appendReq, err := http.NewRequest("POST", t.uploadBase()+"/1.1/media/upload.json",
 			strings.NewReader(appendPayload))
 		if err != nil {
 			return "", err
@@ -378,8 +510,8 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 		appendResp.Body.Close()
 
 		if appendResp.StatusCode != http.StatusNoContent && appendResp.StatusCode != http.StatusOK {
-			return "", fmt.Errorf("twitter APPEND failed (segment %d, status %d): %s",
-				segmentIndex, appendResp.StatusCode, t.parseTwitterError(appendBody))
+			return "", httpError(appendResp.StatusCode, fmt.Errorf("twitter APPEND failed (segment %d, status %d): %s",
+				segmentIndex, appendResp.StatusCode, t.parseTwitterError(appendBody)))
 		}
 
 		segmentIndex++
@@ -392,7 +524,7 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 	// --- FINALIZE ---
 	finalizePayload := fmt.Sprintf("command=FINALIZE&media_id=%s", mediaIDStr)
 
-	finalizeReq, err := http.NewRequest("POST", "https://upload.x.com/1.1/media/upload.json",
+	finalizeReq, err := http.NewRequest("POST", t.uploadBase()+"/1.1/media/upload.json",
 		strings.NewReader(finalizePayload))
 	if err != nil {
 		return "", err
@@ -408,8 +540,8 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 
 	finalizeBody, _ := io.ReadAll(finalizeResp.Body)
 	if finalizeResp.StatusCode != http.StatusOK && finalizeResp.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("twitter FINALIZE failed (status %d): %s",
-			finalizeResp.StatusCode, t.parseTwitterError(finalizeBody))
+		return "", httpError(finalizeResp.StatusCode, fmt.Errorf("twitter FINALIZE failed (status %d): %s",
+			finalizeResp.StatusCode, t.parseTwitterError(finalizeBody)))
 	}
 
 	var finalResp twitterMediaUploadResponse
@@ -424,14 +556,100 @@ func (t *TwitterPublisher) uploadMediaChunked(media *models.Media, accessToken s
 		}
 	}
 
-	utils.Debugf("twitter chunked media upload success twitter_media_id=%s", mediaIDStr)
 	return mediaIDStr, nil
 }
 
+// attachSubtitles uploads an SRT file and associates it with an already
+// processed video via media/subtitles/create.
+func (t *TwitterPublisher) attachSubtitles(videoMediaID, srt, language, accessToken string) error {
+	if language == "" {
+		language = "en"
+	}
+
+	subtitleMediaID, err := t.uploadChunked(strings.NewReader(srt), int64(len(srt)), "text/plain", "subtitles", accessToken)
+	if err != nil {
+		return fmt.Errorf("failed to upload subtitles: %w", err)
+	}
+	utils.Debugf("twitter subtitles uploaded video_media_id=%s subtitle_media_id=%s language=%s", videoMediaID, subtitleMediaID, language)
+
+	payload := map[string]interface{}{
+		"media_id":       videoMediaID,
+		"media_category": "TweetVideo",
+		"subtitle_info": map[string]interface{}{
+			"subtitles": []map[string]string{{
+				"media_id":      subtitleMediaID,
+				"language_code": strings.ToUpper(language),
+				"display_name":  strings.ToUpper(language),
+			}},
+		},
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subtitles payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", t.uploadBase()+"/1.1/media/subtitles/create.json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := t.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("twitter subtitles create request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return httpError(resp.StatusCode,
+			fmt.Errorf("twitter subtitles create failed (status %d): %s", resp.StatusCode, t.parseTwitterError(body)))
+	}
+
+	return nil
+}
+
+// setAltText sets the accessibility description of an uploaded image via
+// media/metadata/create. It must be called before a tweet references the
+// media.
+func (t *TwitterPublisher) setAltText(mediaID, altText, accessToken string) error {
+	payload := map[string]interface{}{
+		"media_id": mediaID,
+		"alt_text": map[string]string{"text": utils.TruncateRunes(altText, twitterMaxAltTextLength)},
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alt text payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", t.uploadBase()+"/1.1/media/metadata/create.json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := t.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("twitter media metadata request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return httpError(resp.StatusCode,
+			fmt.Errorf("twitter alt text failed (status %d): %s", resp.StatusCode, t.parseTwitterError(body)))
+	}
+
+	utils.Debugf("twitter alt text set twitter_media_id=%s", mediaID)
+	return nil
+}
+
 // waitForMediaProcessing polls the media STATUS endpoint until processing completes.
 func (t *TwitterPublisher) waitForMediaProcessing(mediaID, accessToken string) error {
 	for attempt := 0; attempt < 30; attempt++ {
-		statusURL := fmt.Sprintf("https://upload.x.com/1.1/media/upload.json?command=STATUS&media_id=%s", mediaID)
+		statusURL := fmt.Sprintf("%s/1.1/media/upload.json?command=STATUS&media_id=%s", t.uploadBase(), mediaID)
 
 		req, err := http.NewRequest("GET", statusURL, nil)
 		if err != nil {
@@ -457,7 +675,8 @@ func (t *TwitterPublisher) waitForMediaProcessing(mediaID, accessToken string) e
 		}
 
 		if statusResp.ProcessingInfo.Error != nil {
-			return fmt.Errorf("twitter media processing error: %s", statusResp.ProcessingInfo.Error.Message)
+			return newPublishError(models.ErrorCategoryMediaError,
+				fmt.Errorf("twitter media processing error: %s", statusResp.ProcessingInfo.Error.Message))
 		}
 
 		if statusResp.ProcessingInfo.State == "succeeded" {
@@ -465,7 +684,7 @@ func (t *TwitterPublisher) waitForMediaProcessing(mediaID, accessToken string) e
 		}
 
 		if statusResp.ProcessingInfo.State == "failed" {
-			return fmt.Errorf("twitter media processing failed")
+			return newPublishError(models.ErrorCategoryMediaError, fmt.Errorf("twitter media processing failed"))
 		}
 
 		waitSecs := statusResp.ProcessingInfo.CheckAfterSecs
@@ -476,11 +695,21 @@ func (t *TwitterPublisher) waitForMediaProcessing(mediaID, accessToken string) e
 		time.Sleep(time.Duration(waitSecs) * time.Second)
 	}
 
-	return fmt.Errorf("twitter media processing timeout")
+	return newPublishError(models.ErrorCategoryTransient, fmt.Errorf("twitter media processing timeout"))
+}
+
+// isTwitterDuplicateError reports whether an error body is Twitter's
+// "duplicate content" rejection.
+func isTwitterDuplicateError(body []byte) bool {
+	return strings.Contains(strings.ToLower(string(body)), "duplicate content")
 }
 
 // parseTwitterError extracts a human-readable error from a Twitter API error body.
 func (t *TwitterPublisher) parseTwitterError(body []byte) string {
+	if isTwitterDuplicateError(body) {
+		return twitterDuplicateMessage
+	}
+
 	var errResp twitterErrorResponse
 	if err := json.Unmarshal(body, &errResp); err == nil {
 		if errResp.Detail != "" {
@@ -494,4 +723,97 @@ func (t *TwitterPublisher) parseTwitterError(body []byte) string {
 		}
 	}
 	return string(body)
-}
\ No newline at end of file
+}
+
+// RefreshToken implements TokenRefresher. Twitter access tokens last two
+// hours; the refresh token (granted through the offline.access scope) is
+// rotated on every refresh, so both are replaced.
+func (t *TwitterPublisher) RefreshToken(cred *models.PlatformCredentials) error {
+	cfg := config.Load()
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", cred.RefreshToken)
+	form.Set("client_id", cfg.TwitterClientID)
+
+	req, err := http.NewRequest("POST", t.apiBase()+"/2/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// Confidential clients authenticate with client_id:client_secret
+	if cfg.TwitterClientSecret != "" {
+		req.SetBasicAuth(cfg.TwitterClientID, cfg.TwitterClientSecret)
+	}
+
+	resp, err := t.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("twitter token refresh request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("twitter token refresh failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return fmt.Errorf("failed to parse token refresh response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return fmt.Errorf("twitter token refresh returned empty access token")
+	}
+
+	cred.AccessToken = tokenResp.AccessToken
+	if tokenResp.RefreshToken != "" {
+		cred.RefreshToken = tokenResp.RefreshToken
+	}
+	cred.ExpiresAt = nil
+	if tokenResp.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+		cred.ExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+// RevokeToken implements TokenRevoker. Revoking the refresh token ends the
+// whole grant, so it is preferred over the access token.
+func (t *TwitterPublisher) RevokeToken(cred *models.PlatformCredentials) error {
+	cfg := config.Load()
+
+	form := url.Values{}
+	form.Set("client_id", cfg.TwitterClientID)
+	if cred.RefreshToken != "" {
+		form.Set("token", cred.RefreshToken)
+		form.Set("token_type_hint", "refresh_token")
+	} else {
+		form.Set("token", cred.AccessToken)
+		form.Set("token_type_hint", "access_token")
+	}
+
+	req, err := http.NewRequest("POST", t.apiBase()+"/2/oauth2/revoke", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cfg.TwitterClientSecret != "" {
+		req.SetBasicAuth(cfg.TwitterClientID, cfg.TwitterClientSecret)
+	}
+
+	resp, err := t.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("twitter token revoke request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("twitter token revoke failed (status %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package publishers
 
 import (
+	"SocialMediaAPI/config"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/utils"
 	"bytes"
@@ -8,13 +9,30 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
+const (
+	youtubeMaxTitleLength = 100
+	youtubeShortsSuffix   = " #Shorts"
+	youtubeShortsTag      = "Shorts"
+
+	// YouTubeMaxTagsLength is the limit on all of a video's tags together, as
+	// counted by YouTubeTagsLength.
+	YouTubeMaxTagsLength = 500
+
+	// YouTubeDefaultCategoryID is "People & Blogs", which is assignable in every region.
+	YouTubeDefaultCategoryID = "22"
+)
+
 // YouTubePublisher implements PlatformPublisher for the YouTube Data API v3.
 type YouTubePublisher struct {
-	client *http.Client
+	client       *http.Client
+	baseURL      string
+	oauthBaseURL string // Google OAuth 2.0 base for token refresh and revocation
 }
 
 // youtubeErrorResponse represents a YouTube Data API error.
@@ -32,10 +50,12 @@ type youtubeErrorResponse struct {
 
 // youtubeVideoSnippet holds the snippet part of a YouTube video resource.
 type youtubeVideoSnippet struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Tags        []string `json:"tags,omitempty"`
-	CategoryID  string   `json:"categoryId"`
+	Title                string   `json:"title"`
+	Description          string   `json:"description"`
+	Tags                 []string `json:"tags,omitempty"`
+	CategoryID           string   `json:"categoryId"`
+	DefaultLanguage      string   `json:"defaultLanguage,omitempty"`
+	DefaultAudioLanguage string   `json:"defaultAudioLanguage,omitempty"`
 }
 
 // youtubeVideoStatus holds the status part of a YouTube video resource.
@@ -59,22 +79,44 @@ type youtubeInsertResponse struct {
 	} `json:"snippet"`
 }
 
-// NewYouTubePublisher creates a YouTubePublisher with an injectable http.Client.
-// If nil is passed a default client with a generous timeout is used.
-func NewYouTubePublisher(client *http.Client) *YouTubePublisher {
+// YouTubeCategory is a single entry from the videoCategories.list API.
+type YouTubeCategory struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Assignable bool   `json:"assignable"`
+}
+
+// NewYouTubePublisher creates a YouTubePublisher with an injectable http.Client,
+// API base URL and Google OAuth base URL. If nil is passed a default client
+// with a generous timeout is used; empty base URLs use production.
+func NewYouTubePublisher(client *http.Client, baseURL, oauthBaseURL string) *YouTubePublisher {
 	if client == nil {
-		client = &http.Client{Timeout: 120 * time.Second}
+		client = utils.NewHTTPClient(120 * time.Second)
+	}
+	return &YouTubePublisher{
+		client:       client,
+		baseURL:      baseURLOrDefault(baseURL, DefaultYouTubeAPIBase),
+		oauthBaseURL: baseURLOrDefault(oauthBaseURL, DefaultGoogleOAuthBase),
 	}
-	return &YouTubePublisher{client: client}
 }
 
 func (y *YouTubePublisher) httpClient() *http.Client {
 	if y.client == nil {
-		y.client = &http.Client{Timeout: 120 * time.Second}
+		y.client = utils.NewHTTPClient(120 * time.Second)
 	}
 	return y.client
 }
 
+// apiBase returns the Google API base URL, defaulting to production.
+func (y *YouTubePublisher) apiBase() string {
+	return baseURLOrDefault(y.baseURL, DefaultYouTubeAPIBase)
+}
+
+// oauthBase returns the Google OAuth 2.0 base URL, defaulting to production.
+func (y *YouTubePublisher) oauthBase() string {
+	return baseURLOrDefault(y.oauthBaseURL, DefaultGoogleOAuthBase)
+}
+
 // Publish implements PlatformPublisher.
 // YouTube requires a video attachment for every post.
 // Short-form posts are published as YouTube Shorts.
@@ -83,32 +125,22 @@ func (y *YouTubePublisher) Publish(post *models.Post, cred *models.PlatformCrede
 
 	if cred == nil || cred.AccessToken == "" {
 		utils.Warnf("youtube publish missing credentials post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.YouTube,
-			Success:  false,
-			Message:  "Missing YouTube credentials",
-		}
+		return reauthResult(models.YouTube, models.ErrorCodeMissingCredentials, "Missing YouTube credentials")
 	}
 
 	// Check if token is expired
 	tokenValidator := utils.NewTokenValidator()
 	if tokenValidator.IsTokenExpired(cred) {
 		utils.Warnf("youtube token expired post_id=%s user_id=%s", post.ID, post.UserID)
-		return models.PublishResult{
-			Platform: models.YouTube,
-			Success:  false,
-			Message:  "YouTube token has expired. Please reconnect your account via OAuth",
-		}
+		return reauthResult(models.YouTube, models.ErrorCodeTokenExpired,
+			"YouTube token has expired. Please reconnect your account via OAuth")
 	}
 
 	// YouTube does NOT support stories.
 	if post.PostType == models.PostTypeStory {
 		utils.Warnf("youtube publish rejected: stories not supported post_id=%s", post.ID)
-		return models.PublishResult{
-			Platform: models.YouTube,
-			Success:  false,
-			Message:  "YouTube does not support stories. Use post_type 'normal' or 'short' instead",
-		}
+		return errorResult(models.YouTube, models.ErrorCategoryUnsupported,
+			"YouTube does not support stories. Use post_type 'normal' or 'short' instead")
 	}
 
 	// YouTube always requires a video
@@ -122,11 +154,15 @@ func (y *YouTubePublisher) Publish(post *models.Post, cred *models.PlatformCrede
 
 	if videoMedia == nil {
 		utils.Warnf("youtube publish no video found post_id=%s", post.ID)
-		return models.PublishResult{
-			Platform: models.YouTube,
-			Success:  false,
-			Message:  "YouTube requires a video attachment",
-		}
+		return errorResult(models.YouTube, models.ErrorCategoryInvalidContent, "YouTube requires a video attachment")
+	}
+
+	// Posts created before the declaration was required have none; never
+	// guess the audience on the creator's behalf.
+	if post.MadeForKids == nil {
+		utils.Warnf("youtube publish missing made_for_kids declaration post_id=%s", post.ID)
+		return errorResult(models.YouTube, models.ErrorCategoryInvalidContent,
+			"YouTube requires a made_for_kids declaration. Set made_for_kids to true or false on the post")
 	}
 
 	isShort := post.PostType == models.PostTypeShort
@@ -134,17 +170,22 @@ func (y *YouTubePublisher) Publish(post *models.Post, cred *models.PlatformCrede
 	videoID, err := y.uploadVideo(post, videoMedia, cred.AccessToken, isShort)
 	if err != nil {
 		utils.Errorf("youtube publish failed post_id=%s err=%v", post.ID, err)
-		return models.PublishResult{
-			Platform: models.YouTube,
-			Success:  false,
-			Message:  fmt.Sprintf("Error publishing to YouTube: %v", err),
-		}
+		return failureResult(models.YouTube, fmt.Sprintf("Error publishing to YouTube: %v", err), err)
 	}
 
 	msg := "Published successfully on YouTube"
 	if isShort {
 		msg = "Published successfully as YouTube Short"
 	}
+
+	// The video is already up, so a thumbnail that can't be set (custom
+	// thumbnails need a verified channel) doesn't fail the publish
+	if post.Thumbnail != nil {
+		if err := y.setThumbnail(videoID, post.Thumbnail, cred.AccessToken); err != nil {
+			utils.Warnf("youtube thumbnail not set post_id=%s video_id=%s err=%v", post.ID, videoID, err)
+			msg += fmt.Sprintf(" (custom thumbnail not set: %v)", err)
+		}
+	}
 	utils.Infof("youtube publish succeeded post_id=%s video_id=%s is_short=%t", post.ID, videoID, isShort)
 
 	return models.PublishResult{
@@ -160,35 +201,43 @@ func (y *YouTubePublisher) Publish(post *models.Post, cred *models.PlatformCrede
 //  1. POST metadata to initiate a resumable upload → get upload URI
 //  2. PUT the raw video bytes to the upload URI → get the completed video resource
 func (y *YouTubePublisher) uploadVideo(post *models.Post, media *models.Media, accessToken string, isShort bool) (string, error) {
-	// Build video metadata
-	title := post.Content
-	if len(title) > 100 {
-		title = title[:100]
-	}
+	// Build video metadata.
+	// YouTube limits titles to 100 characters (runes, not bytes); longer
+	// content only gets here when the post allows truncation. The description
+	// always carries the full content.
+	title := caption(post, models.YouTube)
 	if title == "" {
 		title = "Untitled"
 	}
-	description := post.Content
+	description := post.ContentFor(models.YouTube)
 
-	// For Shorts, prepend #Shorts tag so YouTube recognises it
-	tags := []string{}
+	// For Shorts, append the #Shorts tag so YouTube recognises it. The
+	// content limit for Shorts already leaves room for the suffix.
+	tags := append([]string{}, post.Tags...)
 	if isShort {
-		tags = append(tags, "Shorts")
-		if len(title) <= 92 {
-			title = title + " #Shorts"
+		if !containsFold(tags, youtubeShortsTag) && YouTubeTagsLength(append(tags, youtubeShortsTag)) <= YouTubeMaxTagsLength {
+			tags = append(tags, youtubeShortsTag)
 		}
+		title += youtubeShortsSuffix
+	}
+
+	categoryID := post.CategoryID
+	if categoryID == "" {
+		categoryID = YouTubeDefaultCategoryID
 	}
 
 	videoResource := youtubeVideoResource{
 		Snippet: &youtubeVideoSnippet{
-			Title:       title,
-			Description: description,
-			Tags:        tags,
-			CategoryID:  "22", // "People & Blogs" — safe default
+			Title:                title,
+			Description:          description,
+			Tags:                 tags,
+			CategoryID:           categoryID,
+			DefaultLanguage:      post.DefaultLanguage,
+			DefaultAudioLanguage: post.DefaultAudioLanguage,
 		},
 		Status: &youtubeVideoStatus{
 			PrivacyStatus:           mapToYouTubePrivacy(post.PrivacyLevel),
-			SelfDeclaredMadeForKids: false,
+			SelfDeclaredMadeForKids: *post.MadeForKids,
 			PaidProductPlacement:    post.IsSponsored,
 		},
 	}
@@ -218,7 +267,7 @@ func (y *YouTubePublisher) initiateResumableUpload(resource youtubeVideoResource
 		return "", fmt.Errorf("failed to marshal video metadata: %w", err)
 	}
 
-	endpoint := "https://www.googleapis.com/upload/youtube/v3/videos?uploadType=resumable&part=snippet,status"
+	endpoint := y.apiBase() + "/upload/youtube/v3/videos?uploadType=resumable&part=snippet,status"
 
 	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(metadataJSON))
 	if err != nil {
@@ -239,7 +288,7 @@ func (y *YouTubePublisher) initiateResumableUpload(resource youtubeVideoResource
 	if resp.StatusCode != http.StatusOK {
 		errMsg := y.parseYouTubeError(body)
 		utils.Errorf("youtube initiate upload API error status=%d body=%s", resp.StatusCode, errMsg)
-		return "", fmt.Errorf("YouTube API error (status %d): %s", resp.StatusCode, errMsg)
+		return "", y.classifyError(resp.StatusCode, body, fmt.Errorf("YouTube API error (status %d): %s", resp.StatusCode, errMsg))
 	}
 
 	uploadURI := resp.Header.Get("Location")
@@ -288,7 +337,7 @@ func (y *YouTubePublisher) uploadVideoFile(uploadURI string, media *models.Media
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		errMsg := y.parseYouTubeError(body)
-		return "", fmt.Errorf("YouTube upload failed (status %d): %s", resp.StatusCode, errMsg)
+		return "", y.classifyError(resp.StatusCode, body, fmt.Errorf("YouTube upload failed (status %d): %s", resp.StatusCode, errMsg))
 	}
 
 	var insertResp youtubeInsertResponse
@@ -304,6 +353,192 @@ func (y *YouTubePublisher) uploadVideoFile(uploadURI string, media *models.Media
 	return insertResp.ID, nil
 }
 
+// setThumbnail uploads media as the custom thumbnail of a video via
+// thumbnails.set. YouTube answers 403 when the channel may not use custom
+// thumbnails because it isn't verified.
+func (y *YouTubePublisher) setThumbnail(videoID string, media *models.Media, accessToken string) error {
+	utils.Debugf("youtube set thumbnail start video_id=%s media_id=%s", videoID, media.ID)
+
+	file, err := os.Open(media.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open thumbnail file: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat thumbnail file: %w", err)
+	}
+
+	contentType := media.MimeType
+	if contentType == "" {
+		contentType = "image/jpeg"
+	}
+
+	endpoint := y.apiBase() + "/upload/youtube/v3/thumbnails/set?videoId=" + url.QueryEscape(videoID)
+	req, err := http.NewRequest("POST", endpoint, file)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = stat.Size()
+
+	resp, err := y.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("youtube thumbnail request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("channel is not allowed custom thumbnails, verify it on YouTube: %s", y.parseYouTubeError(body))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("YouTube thumbnail error (status %d): %s", resp.StatusCode, y.parseYouTubeError(body))
+	}
+
+	utils.Debugf("youtube set thumbnail success video_id=%s media_id=%s", videoID, media.ID)
+	return nil
+}
+
+// ListVideoCategories fetches the video categories available in a region via
+// videoCategories.list. Only assignable categories can be used when uploading.
+func (y *YouTubePublisher) ListVideoCategories(accessToken, regionCode string) ([]YouTubeCategory, error) {
+	endpoint := y.apiBase() + "/youtube/v3/videoCategories?part=snippet&regionCode=" + url.QueryEscape(regionCode)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := y.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("youtube categories request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, y.classifyError(resp.StatusCode, body, fmt.Errorf("YouTube API error (status %d): %s", resp.StatusCode, y.parseYouTubeError(body)))
+	}
+
+	var listResp struct {
+		Items []struct {
+			ID      string `json:"id"`
+			Snippet struct {
+				Title      string `json:"title"`
+				Assignable bool   `json:"assignable"`
+			} `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &listResp); err != nil {
+		return nil, fmt.Errorf("failed to parse YouTube categories response: %w", err)
+	}
+
+	categories := make([]YouTubeCategory, 0, len(listResp.Items))
+	for _, item := range listResp.Items {
+		categories = append(categories, YouTubeCategory{
+			ID:         item.ID,
+			Title:      item.Snippet.Title,
+			Assignable: item.Snippet.Assignable,
+		})
+	}
+
+	utils.Debugf("youtube categories fetched region=%s count=%d", regionCode, len(categories))
+	return categories, nil
+}
+
+// PostStatus implements StatusChecker using videos.list with the status and
+// processingDetails parts.
+func (y *YouTubePublisher) PostStatus(videoID string, cred *models.PlatformCredentials) (models.PlatformPostStatus, error) {
+	status := models.PlatformPostStatus{Platform: models.YouTube, ExternalPostID: videoID}
+	endpoint := y.apiBase() + "/youtube/v3/videos?part=status,processingDetails&id=" + url.QueryEscape(videoID)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return status, err
+	}
+	req.Header.Set("Authorization", "Bearer "+cred.AccessToken)
+
+	resp, err := y.httpClient().Do(req)
+	if err != nil {
+		return status, fmt.Errorf("youtube video status request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return status, y.classifyError(resp.StatusCode, body, fmt.Errorf("YouTube API error (status %d): %s", resp.StatusCode, y.parseYouTubeError(body)))
+	}
+
+	var listResp struct {
+		Items []struct {
+			Status struct {
+				UploadStatus    string `json:"uploadStatus"`
+				FailureReason   string `json:"failureReason"`
+				RejectionReason string `json:"rejectionReason"`
+				PrivacyStatus   string `json:"privacyStatus"`
+			} `json:"status"`
+			ProcessingDetails struct {
+				ProcessingStatus string `json:"processingStatus"`
+			} `json:"processingDetails"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(body, &listResp); err != nil {
+		return status, fmt.Errorf("failed to parse YouTube video status response: %w", err)
+	}
+
+	if len(listResp.Items) == 0 {
+		status.State = models.PlatformStateRemoved
+		return status, nil
+	}
+
+	video := listResp.Items[0]
+	status.URL = "https://www.youtube.com/watch?v=" + url.QueryEscape(videoID)
+	status.Detail = video.Status.UploadStatus
+	switch {
+	case video.Status.UploadStatus == "failed":
+		status.State = models.PlatformStateFailed
+		status.Detail += ": " + video.Status.FailureReason
+	case video.Status.UploadStatus == "rejected":
+		status.State = models.PlatformStateFailed
+		status.Detail += ": " + video.Status.RejectionReason
+	case video.Status.UploadStatus == "deleted":
+		status.State = models.PlatformStateRemoved
+	case video.Status.UploadStatus == "uploaded" || video.ProcessingDetails.ProcessingStatus == "processing":
+		status.State = models.PlatformStateProcessing
+	case video.Status.PrivacyStatus == "private":
+		status.State = models.PlatformStatePrivate
+	default:
+		status.State = models.PlatformStateLive
+	}
+	return status, nil
+}
+
+// classifyError categorizes a YouTube API error from its reason, falling back
+// to the HTTP status.
+func (y *YouTubePublisher) classifyError(statusCode int, body []byte, err error) error {
+	var errResp youtubeErrorResponse
+	json.Unmarshal(body, &errResp)
+	for _, e := range errResp.Error.Errors {
+		switch e.Reason {
+		case "insufficientPermissions":
+			return newAuthError(models.ErrorCodeInsufficientScope, err)
+		case "quotaExceeded", "rateLimitExceeded", "userRateLimitExceeded", "uploadLimitExceeded":
+			return newPublishError(models.ErrorCategoryRateLimited, err)
+		case "backendError", "internalError":
+			return newPublishError(models.ErrorCategoryTransient, err)
+		case "invalidVideoMetadata", "invalidTitle", "invalidDescription", "invalidTags", "invalidCategoryId":
+			return newPublishError(models.ErrorCategoryInvalidContent, err)
+		}
+	}
+	return httpError(statusCode, err)
+}
+
 // parseYouTubeError extracts a human-readable error from a YouTube API error body.
 func (y *YouTubePublisher) parseYouTubeError(body []byte) string {
 	var errResp youtubeErrorResponse
@@ -331,3 +566,109 @@ func mapToYouTubePrivacy(level models.PrivacyLevel) string {
 		return "public"
 	}
 }
+
+// YouTubeTagsLength counts tags the way YouTube applies its 500-character
+// limit: each tag's characters, plus the quotes around a tag containing a
+// space, plus a comma between tags.
+func YouTubeTagsLength(tags []string) int {
+	total := 0
+	for i, tag := range tags {
+		total += utils.RuneLen(tag)
+		if strings.Contains(tag, " ") {
+			total += 2
+		}
+		if i > 0 {
+			total++
+		}
+	}
+	return total
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshToken implements TokenRefresher. Google access tokens last an hour;
+// the refresh token comes from the access_type=offline consent and Google
+// does not return a new one on refresh, so the stored one is kept.
+func (y *YouTubePublisher) RefreshToken(cred *models.PlatformCredentials) error {
+	cfg := config.Load()
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", cred.RefreshToken)
+	form.Set("client_id", cfg.YouTubeClientID)
+	form.Set("client_secret", cfg.YouTubeClientSecret)
+
+	req, err := http.NewRequest("POST", y.oauthBase()+"/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := y.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("youtube token refresh request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("youtube token refresh failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return fmt.Errorf("failed to parse token refresh response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return fmt.Errorf("google returned empty access token")
+	}
+
+	cred.AccessToken = tokenResp.AccessToken
+	cred.ExpiresAt = nil
+	if tokenResp.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+		cred.ExpiresAt = &expiresAt
+	}
+	return nil
+}
+
+// RevokeToken implements TokenRevoker. Revoking the refresh token also
+// revokes every access token issued from it, so it is preferred.
+func (y *YouTubePublisher) RevokeToken(cred *models.PlatformCredentials) error {
+	token := cred.RefreshToken
+	if token == "" {
+		token = cred.AccessToken
+	}
+
+	form := url.Values{}
+	form.Set("token", token)
+
+	req, err := http.NewRequest("POST", y.oauthBase()+"/revoke", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := y.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("youtube token revoke request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("youtube token revoke failed (status %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -1,30 +1,58 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"SocialMediaAPI/config"
 	"SocialMediaAPI/database"
 	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	// ErrInvalidCredentials is returned by Login for an unknown email or a
+	// wrong password, without telling the two apart.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrInvalidResetToken is returned by ResetPassword for an unknown,
+	// expired or already used reset token.
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	// ErrPasswordTooShort is returned when a new password has fewer than
+	// MinPasswordLength characters.
+	ErrPasswordTooShort = errors.New("password is too short")
+)
+
+// MinPasswordLength is the minimum length of a password set through a reset.
+const MinPasswordLength = 8
+
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
+	Role   string `json:"role"`
 	jwt.RegisteredClaims
 }
 
 type AuthService struct {
-	db *database.Database
+	db     *database.Database
+	mailer Mailer
 }
 
-func NewAuthService(db *database.Database) *AuthService {
-	return &AuthService{db: db}
+// NewAuthService creates the auth service. mailer sends password reset
+// emails; nil means NoopMailer.
+func NewAuthService(db *database.Database, mailer Mailer) *AuthService {
+	if mailer == nil {
+		mailer = NoopMailer{}
+	}
+	return &AuthService{db: db, mailer: mailer}
 }
 
 func (a *AuthService) Register(req models.RegisterRequest) (*models.User, error) {
@@ -38,6 +66,7 @@ func (a *AuthService) Register(req models.RegisterRequest) (*models.User, error)
 		Email:     req.Email,
 		Password:  string(hashedPassword),
 		Name:      req.Name,
+		Role:      models.RoleUser,
 		CreatedAt: time.Now(),
 	}
 
@@ -45,17 +74,49 @@ func (a *AuthService) Register(req models.RegisterRequest) (*models.User, error)
 		return nil, err
 	}
 
+	// Without ADMIN_EMAIL the first account becomes admin. The role is granted
+	// after the insert so two concurrent first registrations cannot both win.
+	// The ADMIN_EMAIL account is only promoted by SeedAdmin at startup, since
+	// an unverified registration could otherwise claim the address first.
+	if config.Load().AdminEmail == "" {
+		promoted, err := a.db.PromoteFirstUser(user.ID)
+		if err != nil {
+			utils.Errorf("first admin promotion failed user=%s err=%v", user.ID, err)
+		} else if promoted {
+			user.Role = models.RoleAdmin
+			utils.Infof("admin role granted to first user user=%s", user.ID)
+		}
+	}
+
 	return user, nil
 }
 
+// SeedAdmin promotes the existing ADMIN_EMAIL user (if any) to admin. It runs
+// at startup, so the operator registers the ADMIN_EMAIL account first and
+// then restarts the server.
+func (a *AuthService) SeedAdmin() error {
+	adminEmail := config.Load().AdminEmail
+	if adminEmail == "" {
+		return nil
+	}
+	found, err := a.db.SetUserRoleByEmail(adminEmail, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if found {
+		utils.Infof("admin role granted email=%s", adminEmail)
+	}
+	return nil
+}
+
 func (a *AuthService) Login(req models.LoginRequest) (*models.User, error) {
 	user, err := a.db.GetUserByEmail(req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		return nil, ErrInvalidCredentials
 	}
 
 	return user, nil
@@ -65,6 +126,7 @@ func (a *AuthService) GenerateToken(user *models.User) (string, error) {
 	claims := Claims{
 		UserID: user.ID,
 		Email:  user.Email,
+		Role:   user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -84,9 +146,119 @@ func (a *AuthService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	// Reject tokens of deleted users and tokens issued before an admin revoked
+	// the user's sessions. IssuedAt has second precision, so a token from the
+	// same second as the revocation is treated as revoked.
+	user, err := a.db.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if user.TokensRevokedAt != nil {
+		if claims.IssuedAt == nil || !claims.IssuedAt.Time.After(user.TokensRevokedAt.Truncate(time.Second)) {
+			return nil, fmt.Errorf("token has been revoked")
+		}
+	}
+	// The role comes from the database, not the token, so a promotion or
+	// demotion applies to tokens already issued
+	claims.Role = user.Role
+
+	return claims, nil
+}
+
+// RequestPasswordReset emails a single-use reset token to the user with the
+// given email. An unknown email is not an error, so callers cannot use the
+// result to find out which emails are registered. Requesting a new token
+// invalidates the previous ones.
+func (a *AuthService) RequestPasswordReset(email string) error {
+	if n, err := a.db.DeleteExpiredPasswordResets(time.Now()); err != nil {
+		utils.Warnf("password reset purge failed err=%v", err)
+	} else if n > 0 {
+		utils.Infof("password reset purge removed=%d", n)
+	}
+
+	user, err := a.db.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	cfg := config.Load()
+	now := time.Now()
+	reset := &models.PasswordReset{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: now.Add(cfg.PasswordResetTTL),
+		CreatedAt: now,
+	}
+	if err := a.db.DeletePasswordResets(user.ID); err != nil {
+		return err
+	}
+	if err := a.db.CreatePasswordReset(reset); err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	body.WriteString("Someone asked to reset the password of your account.\n\n")
+	if cfg.PasswordResetURL != "" {
+		body.WriteString("Open this link to choose a new password:\n\n")
+		body.WriteString(cfg.PasswordResetURL + "?token=" + token + "\n\n")
+	} else {
+		body.WriteString("Use this token to choose a new password:\n\n")
+		body.WriteString(token + "\n\n")
+	}
+	fmt.Fprintf(&body, "It expires in %d minutes and can be used once. If you did not ask for this, ignore this email.\n",
+		int(cfg.PasswordResetTTL.Minutes()))
+
+	if err := a.mailer.Send(user.Email, "Reset your password", body.String()); err != nil {
+		return err
+	}
+	utils.Infof("password reset requested user_id=%s", user.ID)
+	return nil
+}
+
+// ResetPassword redeems a reset token and sets the user's new password. All
+// sessions issued before the reset are revoked.
+func (a *AuthService) ResetPassword(token, newPassword string) error {
+	if utils.RuneLen(newPassword) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+
+	reset, err := a.db.ConsumePasswordReset(hashResetToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil {
+		return ErrInvalidResetToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := a.db.UpdateUserPassword(reset.UserID, string(hashedPassword)); err != nil {
+		return err
 	}
+	if err := a.db.RevokeUserTokens(reset.UserID); err != nil {
+		return err
+	}
+	utils.Infof("password reset completed user_id=%s", reset.UserID)
+	return nil
+}
 
-	return nil, fmt.Errorf("invalid token")
-}
\ No newline at end of file
+// hashResetToken returns the hex SHA-256 of a reset token, the form in which
+// tokens are stored and looked up.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// uncategorizedFailure labels failures a publisher did not classify.
+const uncategorizedFailure = "uncategorized"
+
+type failureKey struct {
+	platform models.Platform
+	category string
+}
+
+// PublishMetrics counts failed platform publishes by platform and error
+// category, so operators can tell auth problems from rate limits or bad
+// content across the fleet. The counters are exposed on /metrics in the
+// Prometheus text format and summarized periodically in the logs.
+type PublishMetrics struct {
+	mu          sync.Mutex
+	failures    map[failureKey]uint64
+	lastSummary map[failureKey]uint64
+}
+
+func NewPublishMetrics() *PublishMetrics {
+	return &PublishMetrics{
+		failures:    make(map[failureKey]uint64),
+		lastSummary: make(map[failureKey]uint64),
+	}
+}
+
+// RecordResult counts result if it is a failure.
+func (m *PublishMetrics) RecordResult(result models.PublishResult) {
+	if result.Success {
+		return
+	}
+	category := result.ErrorCategory
+	if category == "" {
+		category = uncategorizedFailure
+	}
+
+	m.mu.Lock()
+	m.failures[failureKey{platform: result.Platform, category: category}]++
+	m.mu.Unlock()
+}
+
+// snapshot returns the counters sorted by platform, then category.
+func (m *PublishMetrics) snapshot() ([]failureKey, map[failureKey]uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make(map[failureKey]uint64, len(m.failures))
+	keys := make([]failureKey, 0, len(m.failures))
+	for k, v := range m.failures {
+		counts[k] = v
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].platform != keys[j].platform {
+			return keys[i].platform < keys[j].platform
+		}
+		return keys[i].category < keys[j].category
+	})
+	return keys, counts
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format.
+func (m *PublishMetrics) WritePrometheus(w io.Writer) error {
+	keys, counts := m.snapshot()
+
+	var b strings.Builder
+	b.WriteString("# HELP publish_failures_total Failed platform publishes by platform and error category.\n")
+	b.WriteString("# TYPE publish_failures_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "publish_failures_total{platform=%q,category=%q} %d\n", k.platform, k.category, counts[k])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// LogSummary logs the failures recorded since the previous summary, one
+// line per platform and category, or nothing when there were none.
+func (m *PublishMetrics) LogSummary() {
+	keys, counts := m.snapshot()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var total uint64
+	var parts []string
+	for _, k := range keys {
+		delta := counts[k] - m.lastSummary[k]
+		m.lastSummary[k] = counts[k]
+		if delta == 0 {
+			continue
+		}
+		total += delta
+		parts = append(parts, fmt.Sprintf("%s/%s=%d", k.platform, k.category, delta))
+	}
+	if total == 0 {
+		return
+	}
+	utils.Warnf("publish failure summary total=%d breakdown=%s", total, strings.Join(parts, ","))
+}
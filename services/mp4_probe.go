@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// maxMoovSize caps how much of the moov box is read into memory. The box
+// only holds metadata and sample tables, so even long videos stay well below.
+const maxMoovSize = 64 << 20
+
+// VideoInfo is the track metadata read from an MP4 file.
+type VideoInfo struct {
+	Width    int
+	Height   int
+	Duration time.Duration
+}
+
+// BitrateKbps returns the average bitrate of a file of size bytes with this
+// duration, or 0 when the duration is unknown.
+func (v VideoInfo) BitrateKbps(size int64) int {
+	if v.Duration <= 0 {
+		return 0
+	}
+	return int(float64(size*8) / v.Duration.Seconds() / 1000)
+}
+
+// ProbeMP4 reads the display resolution of the first video track and the
+// movie duration from the MP4 file at path. Only the box headers and the
+// moov box are read, wherever moov sits in the file.
+func ProbeMP4(path string) (VideoInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	defer f.Close()
+
+	for {
+		boxType, size, err := readBoxHeader(f)
+		if err == io.EOF {
+			return VideoInfo{}, fmt.Errorf("no moov box found")
+		}
+		if err != nil {
+			return VideoInfo{}, err
+		}
+
+		if boxType == "moov" {
+			if size < 0 || size > maxMoovSize {
+				return VideoInfo{}, fmt.Errorf("moov box too large (%d bytes)", size)
+			}
+			moov := make([]byte, size)
+			if _, err := io.ReadFull(f, moov); err != nil {
+				return VideoInfo{}, fmt.Errorf("failed to read moov box: %w", err)
+			}
+			return parseMoov(moov)
+		}
+
+		if size < 0 {
+			return VideoInfo{}, fmt.Errorf("no moov box found")
+		}
+		if _, err := f.Seek(size, io.SeekCurrent); err != nil {
+			return VideoInfo{}, err
+		}
+	}
+}
+
+// readBoxHeader reads a box header from r and returns the box type and the
+// size of its payload. A size of -1 means the box runs to the end of the file.
+func readBoxHeader(r io.Reader) (string, int64, error) {
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return "", 0, io.EOF
+		}
+		return "", 0, err
+	}
+	size := int64(binary.BigEndian.Uint32(hdr[0:4]))
+	boxType := string(hdr[4:8])
+
+	switch size {
+	case 0:
+		return boxType, -1, nil
+	case 1:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return "", 0, err
+		}
+		size = int64(binary.BigEndian.Uint64(ext[:])) - 16
+	default:
+		size -= 8
+	}
+	if size < 0 {
+		return "", 0, fmt.Errorf("invalid %q box size", boxType)
+	}
+	return boxType, size, nil
+}
+
+// childBoxes returns the types and payloads of the boxes directly inside
+// data, in file order.
+func childBoxes(data []byte) ([]string, [][]byte) {
+	var types []string
+	var payloads [][]byte
+	for len(data) >= 8 {
+		size := int(binary.BigEndian.Uint32(data[0:4]))
+		boxType := string(data[4:8])
+		header := 8
+		if size == 1 && len(data) >= 16 {
+			size = int(binary.BigEndian.Uint64(data[8:16]))
+			header = 16
+		} else if size == 0 {
+			size = len(data)
+		}
+		if size < header || size > len(data) {
+			break
+		}
+		types = append(types, boxType)
+		payloads = append(payloads, data[header:size])
+		data = data[size:]
+	}
+	return types, payloads
+}
+
+func parseMoov(moov []byte) (VideoInfo, error) {
+	var info VideoInfo
+	types, payloads := childBoxes(moov)
+	for i, boxType := range types {
+		switch boxType {
+		case "mvhd":
+			info.Duration = parseMvhdDuration(payloads[i])
+		case "trak":
+			if info.Width > 0 {
+				continue
+			}
+			if w, h, ok := parseVideoTrak(payloads[i]); ok {
+				info.Width, info.Height = w, h
+			}
+		}
+	}
+	if info.Width == 0 || info.Height == 0 {
+		return info, fmt.Errorf("no video track found")
+	}
+	return info, nil
+}
+
+// parseMvhdDuration returns the movie duration from an mvhd payload.
+func parseMvhdDuration(b []byte) time.Duration {
+	var timescale, duration uint64
+	switch {
+	case len(b) >= 32 && b[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(b[20:24]))
+		duration = binary.BigEndian.Uint64(b[24:32])
+	case len(b) >= 20 && b[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(b[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(b[16:20]))
+	}
+	if timescale == 0 {
+		return 0
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
+
+// parseVideoTrak returns the display size of a trak when it is a video
+// track. The size comes from tkhd, swapped when the track matrix rotates it
+// by 90 or 270 degrees (portrait phone recordings).
+func parseVideoTrak(trak []byte) (int, int, bool) {
+	var tkhd []byte
+	isVideo := false
+	types, payloads := childBoxes(trak)
+	for i, boxType := range types {
+		switch boxType {
+		case "tkhd":
+			tkhd = payloads[i]
+		case "mdia":
+			mdiaTypes, mdiaPayloads := childBoxes(payloads[i])
+			for j, t := range mdiaTypes {
+				// hdlr: version/flags (4), pre_defined (4), handler_type (4)
+				if t == "hdlr" && len(mdiaPayloads[j]) >= 12 && string(mdiaPayloads[j][8:12]) == "vide" {
+					isVideo = true
+				}
+			}
+		}
+	}
+	// tkhd ends with the 3x3 matrix (36 bytes), then width and height as
+	// 16.16 fixed-point numbers.
+	if !isVideo || len(tkhd) < 44 {
+		return 0, 0, false
+	}
+	end := len(tkhd)
+	width := int(binary.BigEndian.Uint32(tkhd[end-8:end-4]) >> 16)
+	height := int(binary.BigEndian.Uint32(tkhd[end-4:end]) >> 16)
+	matrix := tkhd[end-44 : end-8]
+	a := int32(binary.BigEndian.Uint32(matrix[0:4]))
+	b := int32(binary.BigEndian.Uint32(matrix[4:8]))
+	if a == 0 && b != 0 {
+		width, height = height, width
+	}
+	return width, height, width > 0 && height > 0
+}
@@ -1,53 +1,52 @@
 package services
 
 import (
+	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
 	"crypto/rand"
 	"encoding/hex"
-	"sync"
 	"time"
 )
 
+// oauthStateTTL is how long a user has to complete an OAuth flow.
+const oauthStateTTL = 10 * time.Minute
+
 // OAuthState stores temporary state for OAuth flows
-type OAuthState struct {
-	UserID    string
-	Platform  string
-	CreatedAt time.Time
-}
+type OAuthState = models.OAuthState
 
-// OAuthStateService manages OAuth state tokens
+// OAuthStateService manages OAuth state tokens. States live in the
+// oauth_states table, so a callback can land on any instance and pending
+// flows survive a restart.
 type OAuthStateService struct {
-	mu            sync.RWMutex
-	states        map[string]*OAuthState
-	codeVerifiers map[string]string // state -> code_verifier (for PKCE flows like TikTok)
+	db *database.Database
 }
 
-func NewOAuthStateService() *OAuthStateService {
-	service := &OAuthStateService{
-		states:        make(map[string]*OAuthState),
-		codeVerifiers: make(map[string]string),
-	}
-	
+func NewOAuthStateService(db *database.Database) *OAuthStateService {
+	service := &OAuthStateService{db: db}
+
 	// Cleanup expired states every 10 minutes
 	go service.cleanupExpired()
-	
+
 	return service
 }
 
 // GenerateState creates a new state token
 func (s *OAuthStateService) GenerateState(userID, platform string) string {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	// Generate random state
 	bytes := make([]byte, 32)
 	rand.Read(bytes)
 	state := hex.EncodeToString(bytes)
 
-	// Store state
-	s.states[state] = &OAuthState{
+	// Store state; a state that fails to save is simply rejected on callback
+	err := s.db.CreateOAuthState(&models.OAuthState{
+		State:     state,
 		UserID:    userID,
 		Platform:  platform,
 		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		utils.Errorf("oauth state save failed user_id=%s platform=%s err=%v", userID, platform, err)
 	}
 
 	return state
@@ -55,39 +54,40 @@ func (s *OAuthStateService) GenerateState(userID, platform string) string {
 
 // ValidateState validates and consumes a state token
 func (s *OAuthStateService) ValidateState(state string) (*OAuthState, bool) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	oauthState, exists := s.states[state]
-	if !exists {
+	oauthState, err := s.db.ConsumeOAuthState(state, time.Now().Add(-oauthStateTTL))
+	if err != nil {
+		utils.Errorf("oauth state lookup failed err=%v", err)
 		return nil, false
 	}
-
-	// Check if expired (10 minutes)
-	if time.Since(oauthState.CreatedAt) > 10*time.Minute {
-		delete(s.states, state)
+	if oauthState == nil {
 		return nil, false
 	}
 
-	// Delete state after use (one-time use)
-	delete(s.states, state)
+	// Delete state after use (one-time use). PKCE states are kept, already
+	// marked used, until GetCodeVerifier takes the verifier.
+	if oauthState.CodeVerifier == "" {
+		if err := s.db.DeleteOAuthState(state); err != nil {
+			utils.Warnf("oauth state delete failed err=%v", err)
+		}
+	}
 
 	return oauthState, true
 }
 
 // StoreCodeVerifier stores a PKCE code verifier associated with an OAuth state token.
 func (s *OAuthStateService) StoreCodeVerifier(state, codeVerifier string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.codeVerifiers[state] = codeVerifier
+	if err := s.db.SetOAuthCodeVerifier(state, codeVerifier); err != nil {
+		utils.Errorf("oauth code verifier save failed err=%v", err)
+	}
 }
 
 // GetCodeVerifier retrieves and deletes the PKCE code verifier for a state token.
 func (s *OAuthStateService) GetCodeVerifier(state string) string {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	cv := s.codeVerifiers[state]
-	delete(s.codeVerifiers, state)
+	cv, err := s.db.TakeOAuthCodeVerifier(state)
+	if err != nil {
+		utils.Errorf("oauth code verifier lookup failed err=%v", err)
+		return ""
+	}
 	return cv
 }
 
@@ -97,14 +97,13 @@ func (s *OAuthStateService) cleanupExpired() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		s.mu.Lock()
-		now := time.Now()
-		for state, oauthState := range s.states {
-			if now.Sub(oauthState.CreatedAt) > 10*time.Minute {
-				delete(s.states, state)
-				delete(s.codeVerifiers, state)
-			}
+		deleted, err := s.db.DeleteExpiredOAuthStates(time.Now().Add(-oauthStateTTL))
+		if err != nil {
+			utils.Warnf("oauth state cleanup failed err=%v", err)
+			continue
+		}
+		if deleted > 0 {
+			utils.Debugf("oauth state cleanup removed=%d", deleted)
 		}
-		s.mu.Unlock()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// publishPollInterval is how often an idle worker looks for due publish
+	// jobs when it isn't woken by Enqueue, e.g. for jobs queued by another
+	// instance.
+	publishPollInterval = 5 * time.Second
+
+	// publishRecoveryInterval is how often the queue looks for work left
+	// unfinished by a process that stopped.
+	publishRecoveryInterval = 5 * time.Minute
+)
+
+// PublishQueue publishes scheduled, retried and async posts on a fixed
+// number of workers. The queue itself is the publish_jobs table: Enqueue
+// adds a job per platform and workers claim due jobs with ClaimPublishJobs,
+// so queued work survives a restart and is shared by every instance.
+//
+// Delivery is at least once: jobs left running longer than
+// PUBLISH_RECOVERY_AFTER_MINUTES are queued again, and platforms that
+// already have a successful result are skipped when a job runs again.
+type PublishQueue struct {
+	db        *database.Database
+	publisher *PublisherService
+	wake      chan struct{}
+	quit      chan struct{}
+	wg        sync.WaitGroup
+	stopOnce  sync.Once
+}
+
+func NewPublishQueue(db *database.Database, publisher *PublisherService) *PublishQueue {
+	return &PublishQueue{
+		db:        db,
+		publisher: publisher,
+		wake:      make(chan struct{}, 1),
+		quit:      make(chan struct{}),
+	}
+}
+
+// Start launches PUBLISH_WORKERS workers and the recovery of stale work.
+func (q *PublishQueue) Start() {
+	workers := config.Load().PublishWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	go q.recoverStale()
+	log.Printf("Publish queue started with %d workers", workers)
+}
+
+// Enqueue queues post, which must already be saved as "publishing", for
+// publishing to its platforms that are not done yet, and wakes a worker.
+func (q *PublishQueue) Enqueue(post *models.Post) error {
+	if err := q.db.EnqueuePublishJobs(post.ID, targetPlatforms(post), time.Now()); err != nil {
+		return err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Stop stops claiming jobs and waits until the workers have finished the
+// posts they are publishing, or ctx is done. Jobs cut short stay running and
+// are queued again after the restart.
+func (q *PublishQueue) Stop(ctx context.Context) {
+	q.stopOnce.Do(func() { close(q.quit) })
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Println("Publish queue stopped")
+	case <-ctx.Done():
+		log.Println("Publish queue stop timed out; unfinished jobs will be recovered")
+	}
+}
+
+func (q *PublishQueue) work() {
+	defer q.wg.Done()
+	ticker := time.NewTicker(publishPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.quit:
+			return
+		default:
+		}
+
+		if q.publishNext() {
+			continue
+		}
+
+		select {
+		case <-q.quit:
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishNext claims the due jobs of one post and publishes them. It reports
+// whether there was anything to do.
+func (q *PublishQueue) publishNext() bool {
+	jobs, err := q.db.ClaimPublishJobs(1)
+	if err != nil {
+		log.Printf("Error claiming publish jobs: %v", err)
+		return false
+	}
+	if len(jobs) == 0 {
+		return false
+	}
+
+	postID := jobs[0].PostID
+	platforms := make([]models.Platform, len(jobs))
+	for i, job := range jobs {
+		platforms[i] = job.Platform
+	}
+
+	post, err := q.db.GetPost(postID)
+	if err != nil {
+		// The post was deleted since; its jobs went with it
+		log.Printf("Error loading post %s for publish jobs: %v", postID, err)
+		return true
+	}
+
+	log.Printf("Publishing queued post: %s (platforms %v)", post.ID, platforms)
+	q.publisher.PublishJobs(post, platforms, TriggerQueue)
+	return true
+}
+
+// recoverStale queues work left behind by a stopped process, on start and
+// then periodically: jobs running for longer than
+// PUBLISH_RECOVERY_AFTER_MINUTES, and posts that have been "publishing" that
+// long, e.g. ones claimed by the scheduler just before a crash.
+func (q *PublishQueue) recoverStale() {
+	ticker := time.NewTicker(publishRecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		staleBefore := time.Now().Add(-config.Load().PublishRecoveryAfter)
+
+		if n, err := q.db.RequeueStalePublishJobs(staleBefore); err != nil {
+			log.Printf("Error requeueing stale publish jobs: %v", err)
+		} else if n > 0 {
+			log.Printf("Requeued %d publish jobs left running", n)
+		}
+
+		posts, err := q.db.ClaimStalePublishingPosts(staleBefore, config.Load().SchedulerBatchSize)
+		if err != nil {
+			log.Printf("Error claiming stale publishing posts: %v", err)
+		}
+		for _, post := range posts {
+			log.Printf("Recovering post left publishing: %s", post.ID)
+			if err := q.Enqueue(post); err != nil {
+				log.Printf("Error queueing recovered post %s: %v", post.ID, err)
+			}
+		}
+
+		select {
+		case <-q.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,66 +1,445 @@
 package services
 
 import (
+	"SocialMediaAPI/config"
 	"SocialMediaAPI/database"
 	"SocialMediaAPI/models"
 	"SocialMediaAPI/publishers"
 	"SocialMediaAPI/utils"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// PublishTrigger tells the publisher who started a publish. Publishes from
+// the queue (scheduled, retried and async posts) notify the owner on
+// failure; interactive callers get the results in the response.
+type PublishTrigger int
+
+const (
+	TriggerInteractive PublishTrigger = iota
+	TriggerQueue
+)
+
+// ErrPublishBusy is returned by interactive publishes when every global
+// publish slot (MAX_GLOBAL_PUBLISH_CONCURRENCY) is taken. Nothing was
+// published; the caller should try again later.
+var ErrPublishBusy = errors.New("too many publishes in progress")
+
+// refreshSaveAttempts is how many times a refreshed token is saved before
+// giving up. Platforms that rotate refresh tokens (Twitter, TikTok) have
+// already invalidated the stored one, so losing the new one means the user
+// has to reconnect.
+const refreshSaveAttempts = 3
+
 type PublisherService struct {
 	db         *database.Database
 	publishers map[models.Platform]publishers.PlatformPublisher
+	metrics    *PublishMetrics
+	mailer     Mailer
+
+	// slots bounds the platform publishes in flight across all posts, so a
+	// burst of large uploads can't exhaust the host. nil means no limit.
+	slots chan struct{}
+
+	// refreshLocks serializes token refreshes per credential, so concurrent
+	// publishes don't spend a rotating refresh token twice.
+	refreshLocks sync.Map // credential ID -> *sync.Mutex
 }
 
-func NewPublisherService(db *database.Database) *PublisherService {
+// NewPublisherService creates the publisher service. mailer sends failure
+// notifications; nil means NoopMailer.
+func NewPublisherService(db *database.Database, mailer Mailer) *PublisherService {
+	if mailer == nil {
+		mailer = NoopMailer{}
+	}
+	cfg := config.Load()
+	var slots chan struct{}
+	if cfg.MaxGlobalPublishConcurrency > 0 {
+		slots = make(chan struct{}, cfg.MaxGlobalPublishConcurrency)
+	}
 	return &PublisherService{
-		db: db,
+		db:     db,
+		mailer: mailer,
+		slots:  slots,
 		publishers: map[models.Platform]publishers.PlatformPublisher{
-			models.Twitter:   publishers.NewTwitterPublisher(nil),
-			models.Facebook:  publishers.NewFacebookPublisher(nil),
-			models.LinkedIn:  &publishers.LinkedInPublisher{},
-			models.Instagram: publishers.NewInstagramPublisher(nil),
-			models.TikTok:    publishers.NewTikTokPublisher(nil),
-			models.YouTube:   publishers.NewYouTubePublisher(nil),
+			models.Twitter:   publishers.NewTwitterPublisher(nil, cfg.TwitterAPIBase, cfg.TwitterUploadBase),
+			models.Facebook:  publishers.NewFacebookPublisher(nil, cfg.FacebookGraphBase),
+			models.LinkedIn:  publishers.NewLinkedInPublisher(nil, cfg.LinkedInAPIBase),
+			models.Instagram: publishers.NewInstagramPublisher(nil, cfg.InstagramGraphBase),
+			models.TikTok:    publishers.NewTikTokPublisher(nil, cfg.TikTokAPIBase),
+			models.YouTube:   publishers.NewYouTubePublisher(nil, cfg.YouTubeAPIBase, cfg.GoogleOAuthBase),
+			models.Threads:   publishers.NewThreadsPublisher(nil, cfg.ThreadsGraphBase),
 		},
+		metrics: NewPublishMetrics(),
+	}
+}
+
+// ValidatePostTypePlatforms checks that every platform allowed for a post
+// type (POST_TYPE_*_PLATFORMS) has a registered publisher.
+func (ps *PublisherService) ValidatePostTypePlatforms() error {
+	for _, postType := range []models.PostType{models.PostTypeNormal, models.PostTypeShort, models.PostTypeStory} {
+		for _, p := range publishers.PostTypePlatforms(postType) {
+			if _, ok := ps.publishers[p]; !ok {
+				return fmt.Errorf("POST_TYPE_%s_PLATFORMS: unknown platform %q", strings.ToUpper(string(postType)), p)
+			}
+		}
+	}
+	return nil
+}
+
+// Metrics returns the publish failure counters.
+func (ps *PublisherService) Metrics() *PublishMetrics {
+	return ps.metrics
+}
+
+// SupportedPlatforms returns every platform that has a registered publisher,
+// sorted by name.
+func (ps *PublisherService) SupportedPlatforms() []models.Platform {
+	platforms := make([]models.Platform, 0, len(ps.publishers))
+	for p := range ps.publishers {
+		platforms = append(platforms, p)
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i] < platforms[j] })
+	return platforms
+}
+
+// PublishPost publishes post to its platforms. A test-mode post only goes to
+// its test platform, leaving the others untouched.
+func (ps *PublisherService) PublishPost(post *models.Post, trigger PublishTrigger) ([]models.PublishResult, error) {
+	if post.TestMode {
+		utils.Infof("test mode publish post_id=%s platform=%s", post.ID, testPlatform(post))
+	}
+	return ps.publishTo(post, targetPlatforms(post), trigger)
+}
+
+// PublishJobs publishes post to the platforms of its claimed publish jobs.
+// A platform that already has a successful result is not published again,
+// so a job that runs twice never duplicates a post.
+func (ps *PublisherService) PublishJobs(post *models.Post, platforms []models.Platform, trigger PublishTrigger) ([]models.PublishResult, error) {
+	published, err := ps.db.GetPublishedPlatforms(post.ID)
+	if err != nil {
+		utils.Errorf("failed to load published platforms post_id=%s err=%v", post.ID, err)
+	}
+	done := make(map[models.Platform]bool, len(published))
+	for _, p := range published {
+		done[p] = true
+	}
+
+	pending := []models.Platform{}
+	for _, p := range platforms {
+		if !done[p] {
+			pending = append(pending, p)
+			continue
+		}
+		utils.Infof("publish job already published post_id=%s platform=%s", post.ID, p)
+		if err := ps.db.FinishPublishJob(post.ID, p, true, ""); err != nil {
+			utils.Errorf("failed to finish publish job post_id=%s platform=%s err=%v", post.ID, p, err)
+		}
+	}
+
+	if len(pending) == 0 {
+		// Every platform went out before the job was interrupted
+		now := time.Now()
+		post.Status = models.StatusPublished
+		post.PublishedAt = &now
+		post.NextRetryAt = nil
+		post.UpdatedAt = now
+		if err := ps.db.UpdatePost(post); err != nil {
+			utils.Errorf("failed to update post status post_id=%s status=%s err=%v", post.ID, post.Status, err)
+		}
+		return nil, nil
+	}
+
+	return ps.publishTo(post, pending, trigger)
+}
+
+// targetPlatforms returns the platforms post is published to: its test
+// platform alone in test mode, else all of its platforms.
+func targetPlatforms(post *models.Post) []models.Platform {
+	if post.TestMode {
+		return []models.Platform{testPlatform(post)}
+	}
+	return post.Platforms
+}
+
+// testPlatform returns the only platform a test-mode post is published to:
+// its test_platform, or else the first of its platforms.
+func testPlatform(post *models.Post) models.Platform {
+	if post.TestPlatform != "" {
+		return post.TestPlatform
 	}
+	return post.Platforms[0]
 }
 
-func (ps *PublisherService) PublishPost(post *models.Post) []models.PublishResult {
-	utils.Infof("starting publish post_id=%s user_id=%s platforms=%d media=%d", post.ID, post.UserID, len(post.Platforms), len(post.Media))
+// RetryPost re-publishes a failed post to the platforms that have not
+// succeeded yet.
+func (ps *PublisherService) RetryPost(post *models.Post, trigger PublishTrigger) ([]models.PublishResult, error) {
+	published, err := ps.db.GetPublishedPlatforms(post.ID)
+	if err != nil {
+		utils.Errorf("failed to load published platforms post_id=%s err=%v", post.ID, err)
+		return ps.PublishPost(post, trigger)
+	}
+
+	done := make(map[models.Platform]bool, len(published))
+	for _, p := range published {
+		done[p] = true
+	}
+	pending := []models.Platform{}
+	for _, p := range targetPlatforms(post) {
+		if !done[p] {
+			pending = append(pending, p)
+		}
+	}
+
+	utils.Infof("retrying publish post_id=%s attempt=%d platforms=%v", post.ID, post.RetryCount, pending)
+	return ps.publishTo(post, pending, trigger)
+}
+
+// PlatformStatuses asks each of post's platforms for the current state of
+// the published post, using the external IDs recorded by successful
+// publishes. Platforms are queried in parallel; a failed query is reported
+// as unknown rather than failing the whole call.
+func (ps *PublisherService) PlatformStatuses(post *models.Post) ([]models.PlatformPostStatus, error) {
+	externalIDs, err := ps.db.GetExternalPostIDs(post.ID)
+	if err != nil {
+		return nil, err
+	}
 
 	var wg sync.WaitGroup
-	results := make([]models.PublishResult, len(post.Platforms))
+	statuses := make([]models.PlatformPostStatus, len(post.Platforms))
 
 	for i, platform := range post.Platforms {
+		status := models.PlatformPostStatus{Platform: platform, ExternalPostID: externalIDs[platform]}
+
+		checker, ok := ps.publishers[platform].(publishers.StatusChecker)
+		switch {
+		case status.ExternalPostID == "":
+			status.State = models.PlatformStateNotPublished
+		case !ok:
+			status.State = models.PlatformStateUnsupported
+		}
+		if status.State != "" {
+			statuses[i] = status
+			continue
+		}
+
+		wg.Add(1)
+		go func(idx int, status models.PlatformPostStatus) {
+			defer wg.Done()
+
+			credentials, err := ps.credentialsFor(post, status.Platform)
+			if err != nil || credentials == nil || credentials.AccessToken == "" {
+				status.State = models.PlatformStateUnknown
+				status.Detail = "No credentials for " + string(status.Platform) + ". Reconnect the account"
+				statuses[idx] = status
+				return
+			}
+
+			checked, err := checker.PostStatus(status.ExternalPostID, credentials)
+			if err != nil {
+				utils.Warnf("platform status check failed post_id=%s platform=%s err=%v", post.ID, status.Platform, err)
+				status.State = models.PlatformStateUnknown
+				status.Detail = err.Error()
+				statuses[idx] = status
+				return
+			}
+			statuses[idx] = checked
+		}(i, status)
+	}
+
+	wg.Wait()
+	return statuses, nil
+}
+
+// credentialsFor loads the credential post publishes to platform with: the
+// account chosen in post.Accounts, or else the user's primary account. A
+// chosen account that is gone is not replaced by another one.
+func (ps *PublisherService) credentialsFor(post *models.Post, platform models.Platform) (*models.PlatformCredentials, error) {
+	if id := post.Accounts[platform]; id != "" {
+		return ps.db.GetCredentialsByID(post.UserID, id)
+	}
+	return ps.db.GetCredentials(post.UserID, platform)
+}
+
+// refreshIfExpired renews an expired access token through the platform's
+// publisher when it supports refreshing and a refresh token is stored, and
+// saves the new token. Credentials that are still valid, or can't be
+// refreshed, are returned unchanged for the publisher to judge. A new token
+// that cannot be saved is an error, as the stored refresh token may already
+// have been rotated away.
+func (ps *PublisherService) refreshIfExpired(userID string, platform models.Platform, cred *models.PlatformCredentials, publisher publishers.PlatformPublisher) (*models.PlatformCredentials, error) {
+	refresher, ok := publisher.(publishers.TokenRefresher)
+	tokenValidator := utils.NewTokenValidator()
+	if !ok || cred.RefreshToken == "" || !tokenValidator.IsTokenExpired(cred) {
+		return cred, nil
+	}
+
+	lock, _ := ps.refreshLocks.LoadOrStore(cred.ID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// Another publish may have refreshed it while we waited for the lock
+	current, err := ps.db.GetCredentialsByID(userID, cred.ID)
+	if err == nil && current != nil {
+		cred = current
+		if !tokenValidator.IsTokenExpired(cred) {
+			return cred, nil
+		}
+	}
+
+	if err := refresher.RefreshToken(cred); err != nil {
+		return nil, err
+	}
+	cred.UpdatedAt = time.Now()
+	var saveErr error
+	for attempt := 1; attempt <= refreshSaveAttempts; attempt++ {
+		if saveErr = ps.db.SaveCredentials(cred); saveErr == nil {
+			break
+		}
+		utils.Errorf("failed to save refreshed credentials user_id=%s platform=%s attempt=%d err=%v", cred.UserID, platform, attempt, saveErr)
+		if attempt < refreshSaveAttempts {
+			time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+		}
+	}
+	if saveErr != nil {
+		// The refresh token may have been rotated, so the stored one is dead
+		// and so is this credential once the new token expires
+		return nil, fmt.Errorf("save refreshed token: %w", saveErr)
+	}
+	utils.Infof("token refreshed user_id=%s platform=%s", cred.UserID, platform)
+	return cred, nil
+}
+
+// FreshCredentials returns cred with its access token refreshed when it has
+// expired, for callers outside a publish that call the platform's API with it
+// (see refreshIfExpired).
+func (ps *PublisherService) FreshCredentials(cred *models.PlatformCredentials) (*models.PlatformCredentials, error) {
+	return ps.refreshIfExpired(cred.UserID, cred.Platform, cred, ps.publishers[cred.Platform])
+}
+
+// RevokeCredentials revokes cred's tokens with its platform, when the
+// platform supports revocation.
+func (ps *PublisherService) RevokeCredentials(cred *models.PlatformCredentials) error {
+	revoker, ok := ps.publishers[cred.Platform].(publishers.TokenRevoker)
+	if !ok {
+		return nil
+	}
+	return revoker.RevokeToken(cred)
+}
+
+// publishTo publishes post to the given platforms and records the outcome on
+// the post. A failed scheduled post is given a next_retry_at when at least one
+// failure is worth retrying.
+//
+// Each platform publish holds a global publish slot. Queued publishes wait
+// for free slots; an interactive publish takes its slots up front and fails
+// with ErrPublishBusy, leaving the post untouched, when there aren't enough.
+func (ps *PublisherService) publishTo(post *models.Post, platforms []models.Platform, trigger PublishTrigger) ([]models.PublishResult, error) {
+	reserved := 0
+	if trigger == TriggerInteractive {
+		var ok bool
+		if reserved, ok = ps.tryAcquireSlots(len(platforms)); !ok {
+			utils.Warnf("publish refused, no free publish slots post_id=%s platforms=%d", post.ID, len(platforms))
+			return nil, ErrPublishBusy
+		}
+	}
+
+	utils.Infof("starting publish post_id=%s user_id=%s platforms=%d media=%d", post.ID, post.UserID, len(platforms), len(post.Media))
+
+	var wg sync.WaitGroup
+	results := make([]models.PublishResult, len(platforms))
+
+	for i, platform := range platforms {
 		wg.Add(1)
 		go func(idx int, plt models.Platform) {
 			defer wg.Done()
+			if idx >= reserved {
+				ps.acquireSlot()
+			}
+			defer ps.releaseSlot()
 			utils.Debugf("processing platform post_id=%s platform=%s", post.ID, plt)
 
 			publisher, ok := ps.publishers[plt]
 			if !ok {
 				utils.Warnf("platform not supported post_id=%s platform=%s", post.ID, plt)
 				results[idx] = models.PublishResult{
-					Platform: plt,
-					Success:  false,
-					Message:  "Platform not supported",
+					Platform:      plt,
+					Success:       false,
+					Message:       "Platform not supported",
+					ErrorCategory: models.ErrorCategoryUnsupported,
 				}
 				return
 			}
 
-			credentials, err := ps.db.GetCredentials(post.UserID, plt)
+			if err := ps.db.StartPublishJob(post.ID, plt); err != nil {
+				utils.Errorf("failed to start publish job post_id=%s platform=%s err=%v", post.ID, plt, err)
+			}
+
+			credentials, err := ps.credentialsFor(post, plt)
 			if err != nil {
 				utils.Warnf("credentials lookup failed post_id=%s user_id=%s platform=%s err=%v", post.ID, post.UserID, plt, err)
 			} else if credentials == nil || credentials.AccessToken == "" {
 				utils.Warnf("credentials missing or empty post_id=%s user_id=%s platform=%s", post.ID, post.UserID, plt)
 			} else {
 				utils.Debugf("credentials loaded post_id=%s user_id=%s platform=%s", post.ID, post.UserID, plt)
+				credentials, err = ps.refreshIfExpired(post.UserID, plt, credentials, publisher)
+				if err != nil {
+					utils.Errorf("token refresh failed post_id=%s user_id=%s platform=%s err=%v", post.ID, post.UserID, plt, err)
+					result := models.PublishResult{
+						Platform:      plt,
+						Success:       false,
+						Message:       fmt.Sprintf("%s token has expired and could not be refreshed. Please reconnect your account via OAuth", plt),
+						NeedsReauth:   true,
+						ErrorCode:     models.ErrorCodeTokenExpired,
+						ErrorCategory: models.ErrorCategoryAuth,
+					}
+					results[idx] = result
+					ps.saveResult(post.ID, result)
+					return
+				}
 			}
 
-			result := publisher.Publish(post, credentials)
+			// Content may have become too long since the post was created
+			// (e.g. MAX_CAPTION_LENGTH was lowered), so check it again here,
+			// along with media no platform can publish.
+			var result models.PublishResult
+			err = publishers.CheckContentLength(post, plt)
+			if err == nil {
+				err = publishers.CheckAnimatedMedia(post)
+			}
+			if err == nil {
+				err = publishers.CheckVideoLimits(post, plt)
+			}
+			// A permission the user unchecked when connecting would only
+			// surface as a generic 403 from the platform
+			missingScope := ""
+			if credentials != nil {
+				missingScope = publishers.MissingScope(plt, credentials.GrantedScopes)
+			}
+			if err != nil {
+				result = models.PublishResult{
+					Platform:      plt,
+					Success:       false,
+					Message:       err.Error(),
+					ErrorCategory: models.ErrorCategoryInvalidContent,
+				}
+			} else if missingScope != "" {
+				utils.Warnf("publish skipped, scope not granted post_id=%s platform=%s scope=%s", post.ID, plt, missingScope)
+				result = models.PublishResult{
+					Platform:      plt,
+					Success:       false,
+					Message:       fmt.Sprintf("%s permission %q was not granted. Please reconnect your account via OAuth and allow it", plt, missingScope),
+					NeedsReauth:   true,
+					ErrorCode:     models.ErrorCodeInsufficientScope,
+					ErrorCategory: models.ErrorCategoryAuth,
+				}
+			} else {
+				result = publisher.Publish(post, credentials)
+			}
 			results[idx] = result
 			if result.Success {
 				utils.Infof("platform publish success post_id=%s platform=%s external_post_id=%s", post.ID, plt, result.PostID)
@@ -68,22 +447,22 @@ func (ps *PublisherService) PublishPost(post *models.Post) []models.PublishResul
 				utils.Errorf("platform publish failed post_id=%s platform=%s message=%s", post.ID, plt, result.Message)
 			}
 
-			if err := ps.db.SavePublishResult(post.ID, result); err != nil {
-				utils.Errorf("failed to save publish result post_id=%s platform=%s err=%v", post.ID, plt, err)
-			}
+			ps.saveResult(post.ID, result)
 		}(i, platform)
 	}
 
 	wg.Wait()
 
 	allSucceeded := len(results) > 0
-	for _, result := range results {
+	for i, result := range results {
+		results[i].TestMode = post.TestMode
+		ps.metrics.RecordResult(result)
 		if !result.Success {
 			allSucceeded = false
-			break
 		}
 	}
 
+	post.NextRetryAt = nil
 	if allSucceeded {
 		now := time.Now()
 		post.PublishedAt = &now
@@ -93,6 +472,12 @@ func (ps *PublisherService) PublishPost(post *models.Post) []models.PublishResul
 		post.PublishedAt = nil
 		post.Status = models.StatusFailed
 		utils.Warnf("post publish completed status=failed post_id=%s", post.ID)
+		ps.scheduleRetry(post, results)
+		if post.NextRetryAt != nil {
+			if err := ps.db.RetryPublishJobs(post.ID, *post.NextRetryAt); err != nil {
+				utils.Errorf("failed to queue publish job retries post_id=%s err=%v", post.ID, err)
+			}
+		}
 	}
 
 	post.UpdatedAt = time.Now()
@@ -102,7 +487,169 @@ func (ps *PublisherService) PublishPost(post *models.Post) []models.PublishResul
 		utils.Debugf("post status persisted post_id=%s status=%s", post.ID, post.Status)
 	}
 
+	ps.recordPublishAudit(post, results)
+	if trigger != TriggerInteractive {
+		ps.notifyFailure(post, results)
+	}
+
 	utils.Infof("finished publish post_id=%s success=%t", post.ID, allSucceeded)
 
-	return results
+	return results, nil
+}
+
+// acquireSlot blocks until a global publish slot is free and takes it.
+func (ps *PublisherService) acquireSlot() {
+	if ps.slots != nil {
+		ps.slots <- struct{}{}
+	}
+}
+
+// releaseSlot frees a slot taken by acquireSlot or tryAcquireSlots.
+func (ps *PublisherService) releaseSlot() {
+	if ps.slots != nil {
+		<-ps.slots
+	}
+}
+
+// tryAcquireSlots takes n global publish slots at once without waiting, or
+// none when fewer are free. n is capped at the limit, so a post with more
+// platforms than MAX_GLOBAL_PUBLISH_CONCURRENCY can still be published; it
+// returns how many slots were taken.
+func (ps *PublisherService) tryAcquireSlots(n int) (int, bool) {
+	if ps.slots == nil {
+		return n, true
+	}
+	if n > cap(ps.slots) {
+		n = cap(ps.slots)
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case ps.slots <- struct{}{}:
+		default:
+			for ; i > 0; i-- {
+				<-ps.slots
+			}
+			return 0, false
+		}
+	}
+	return n, true
+}
+
+// saveResult stores the outcome of publishing post to a platform in
+// publish_results, post_platform_status and on its publish job. Errors are
+// only logged.
+func (ps *PublisherService) saveResult(postID string, result models.PublishResult) {
+	if err := ps.db.SavePublishResult(postID, result); err != nil {
+		utils.Errorf("failed to save publish result post_id=%s platform=%s err=%v", postID, result.Platform, err)
+	}
+	if err := ps.db.SetPlatformStatus(postID, result); err != nil {
+		utils.Errorf("failed to save platform status post_id=%s platform=%s err=%v", postID, result.Platform, err)
+	}
+	if err := ps.db.FinishPublishJob(postID, result.Platform, result.Success, result.Message); err != nil {
+		utils.Errorf("failed to finish publish job post_id=%s platform=%s err=%v", postID, result.Platform, err)
+	}
+}
+
+// scheduleRetry sets next_retry_at on a failed scheduled post from the
+// configured backoff schedule, unless the retries are used up or none of the
+// failures is transient or rate limited.
+func (ps *PublisherService) scheduleRetry(post *models.Post, results []models.PublishResult) {
+	if post.ScheduledFor == nil {
+		return
+	}
+
+	retryable := false
+	for _, result := range results {
+		if !result.Success && result.Retryable() {
+			retryable = true
+			break
+		}
+	}
+	if !retryable {
+		utils.Infof("post publish not retried: no retryable failures post_id=%s", post.ID)
+		return
+	}
+
+	cfg := config.Load()
+	if post.RetryCount >= cfg.PublishRetryMaxAttempts || len(cfg.PublishRetrySchedule) == 0 {
+		utils.Warnf("post publish retries exhausted post_id=%s retry_count=%d", post.ID, post.RetryCount)
+		return
+	}
+
+	idx := post.RetryCount
+	if idx >= len(cfg.PublishRetrySchedule) {
+		idx = len(cfg.PublishRetrySchedule) - 1
+	}
+	next := time.Now().Add(cfg.PublishRetrySchedule[idx])
+	post.NextRetryAt = &next
+	utils.Infof("post publish retry scheduled post_id=%s retry_count=%d next_retry_at=%s", post.ID, post.RetryCount, next.Format(time.RFC3339))
+}
+
+// notifyFailure emails the owner of a queued post that failed for
+// good, i.e. with no automatic retry pending, if they opted in with
+// notify_publish_failures. Errors are only logged.
+func (ps *PublisherService) notifyFailure(post *models.Post, results []models.PublishResult) {
+	if post.Status != models.StatusFailed || post.NextRetryAt != nil {
+		return
+	}
+
+	settings, err := ps.db.GetUserSettings(post.UserID)
+	if err != nil {
+		utils.Warnf("publish failure email skipped: settings lookup failed post_id=%s err=%v", post.ID, err)
+		return
+	}
+	if !settings.NotifyPublishFailures {
+		return
+	}
+	user, err := ps.db.GetUserByID(post.UserID)
+	if err != nil {
+		utils.Warnf("publish failure email skipped: owner lookup failed post_id=%s err=%v", post.ID, err)
+		return
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Your scheduled post could not be published to every platform.\n\n")
+	fmt.Fprintf(&body, "Post: %s\n", utils.TruncateRunes(post.Content, 80))
+	if post.ScheduledFor != nil {
+		fmt.Fprintf(&body, "Scheduled for: %s\n", post.ScheduledFor.UTC().Format(time.RFC1123))
+	}
+	body.WriteString("\n")
+	for _, result := range results {
+		if !result.Success {
+			fmt.Fprintf(&body, "- %s: %s\n", result.Platform, result.Message)
+		}
+	}
+	body.WriteString("\n")
+
+	cfg := config.Load()
+	if cfg.PostRetryURL != "" {
+		fmt.Fprintf(&body, "Retry it here: %s\n", strings.ReplaceAll(cfg.PostRetryURL, "{id}", post.ID))
+	} else {
+		fmt.Fprintf(&body, "Retry it with: POST %s/api/posts/%s/retry\n", cfg.BaseURL, post.ID)
+	}
+	body.WriteString("\nYou get this email because publish failure notifications are enabled in your settings.\n")
+
+	if err := ps.mailer.Send(user.Email, "Your scheduled post failed to publish", body.String()); err != nil {
+		utils.Errorf("publish failure email failed post_id=%s err=%v", post.ID, err)
+		return
+	}
+	utils.Infof("publish failure email sent post_id=%s user_id=%s", post.ID, post.UserID)
+}
+
+// recordPublishAudit writes one audit entry per platform with the outcome.
+func (ps *PublisherService) recordPublishAudit(post *models.Post, results []models.PublishResult) {
+	for _, result := range results {
+		details := result.Message
+		if result.PostID != "" {
+			details = fmt.Sprintf("external_post_id=%s", result.PostID)
+		}
+		ps.db.RecordAudit(models.AuditEntry{
+			UserID:   post.UserID,
+			Action:   models.AuditPostPublished,
+			Platform: string(result.Platform),
+			PostID:   post.ID,
+			Success:  result.Success,
+			Details:  details,
+		})
+	}
 }
@@ -1,44 +1,126 @@
 package services
 
 import (
+	"SocialMediaAPI/config"
 	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
 type Scheduler struct {
-	cron      *cron.Cron
-	db        *database.Database
-	publisher *PublisherService
+	cron        *cron.Cron
+	db          *database.Database
+	publisher   *PublisherService
+	queue       *PublishQueue
+	tokenExpiry *TokenExpiryNotifier
 }
 
-func NewScheduler(db *database.Database, publisher *PublisherService) *Scheduler {
+func NewScheduler(db *database.Database, publisher *PublisherService, queue *PublishQueue, tokenExpiry *TokenExpiryNotifier) *Scheduler {
 	return &Scheduler{
-		cron:      cron.New(),
-		db:        db,
-		publisher: publisher,
+		cron:        cron.New(),
+		db:          db,
+		publisher:   publisher,
+		queue:       queue,
+		tokenExpiry: tokenExpiry,
 	}
 }
 
 func (s *Scheduler) Start() {
-	s.cron.AddFunc("@every 1m", func() {
-		posts, err := s.db.ClaimScheduledPosts()
-		if err != nil {
-			log.Printf("Error claiming scheduled posts: %v", err)
-			return
-		}
+	// A tick that is still queueing when the next one is due makes that
+	// one skip, so a slow batch never piles up parallel ticks.
+	s.cron.AddJob("@every 1m", cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(cron.FuncJob(s.publishDue)))
 
-		for _, post := range posts {
-			log.Printf("Publishing scheduled post: %s", post.ID)
-			s.publisher.PublishPost(post)
-		}
-	})
+	interval := config.Load().PublishFailureSummaryInterval
+	s.cron.AddFunc(fmt.Sprintf("@every %s", interval), s.publisher.Metrics().LogSummary)
+
+	// Warn users about expiring platform tokens once a day
+	s.cron.AddFunc("@daily", s.tokenExpiry.Run)
+
+	s.cron.AddFunc("@daily", s.cleanupPublishResults)
 
 	s.cron.Start()
 	log.Println("Scheduler started")
 }
 
+// publishDue claims up to SCHEDULER_BATCH_SIZE due posts and retries and
+// queues them for the publish workers. Anything left over is claimed by the
+// next tick.
+func (s *Scheduler) publishDue() {
+	cfg := config.Load()
+	closed := s.closedWindowUsers(time.Now())
+
+	posts, err := s.db.ClaimScheduledPosts(closed, cfg.SchedulerBatchSize)
+	if err != nil {
+		log.Printf("Error claiming scheduled posts: %v", err)
+		return
+	}
+
+	for _, post := range posts {
+		log.Printf("Queueing scheduled post: %s", post.ID)
+		s.enqueue(post)
+	}
+
+	remaining := cfg.SchedulerBatchSize - len(posts)
+	if remaining <= 0 {
+		return
+	}
+
+	retries, err := s.db.ClaimRetryPosts(closed, remaining)
+	if err != nil {
+		log.Printf("Error claiming posts due for retry: %v", err)
+		return
+	}
+
+	for _, post := range retries {
+		log.Printf("Queueing retry of failed post: %s (attempt %d)", post.ID, post.RetryCount)
+		s.enqueue(post)
+	}
+}
+
+// enqueue queues a claimed post. A post that can't be queued stays
+// "publishing" and is recovered by the queue later.
+func (s *Scheduler) enqueue(post *models.Post) {
+	if err := s.queue.Enqueue(post); err != nil {
+		log.Printf("Error queueing post %s: %v", post.ID, err)
+	}
+}
+
+// cleanupPublishResults deletes publish results older than
+// PUBLISH_RESULTS_RETENTION_DAYS, keeping the latest per post and platform.
+func (s *Scheduler) cleanupPublishResults() {
+	cutoff := time.Now().Add(-config.Load().PublishResultsRetention)
+	n, err := s.db.DeleteOldPublishResults(cutoff)
+	if err != nil {
+		log.Printf("Error deleting old publish results: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Deleted %d publish results older than %s", n, cutoff.Format(time.RFC3339))
+	}
+}
+
+// closedWindowUsers returns the users whose publish window is closed at now.
+// Their due posts are skipped this tick and go out once the window opens.
+func (s *Scheduler) closedWindowUsers(now time.Time) []string {
+	settings, err := s.db.GetPublishWindowSettings()
+	if err != nil {
+		log.Printf("Error loading publish windows (publishing without window checks): %v", err)
+		return nil
+	}
+
+	closed := []string{}
+	for _, st := range settings {
+		if !st.InPublishWindow(now) {
+			closed = append(closed, st.UserID)
+		}
+	}
+	return closed
+}
+
 func (s *Scheduler) Stop() {
 	s.cron.Stop()
 }
\ No newline at end of file
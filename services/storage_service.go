@@ -2,11 +2,13 @@ package services
 
 import (
 	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"bufio"
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"os"
 	"path/filepath"
 	"strings"
@@ -28,6 +30,9 @@ var allowedFileTypes = map[string][]string{
 	"video/mp4":  {".mp4"},
 }
 
+// fileTypeHeaderSize is how many leading bytes are inspected for magic numbers.
+const fileTypeHeaderSize = 512
+
 // allowedExtToMIME is the reverse lookup: extension → expected MIME types.
 var allowedExtToMIME = map[string][]string{
 	".jpg":  {"image/jpeg"},
@@ -38,21 +43,21 @@ var allowedExtToMIME = map[string][]string{
 	".mp4":  {"video/mp4"},
 }
 
-// DetectFileType reads the file header and uses magic-number matching to
-// determine the real MIME type. Returns the filetype.Type and resets the reader.
+// DetectFileType peeks at the stream header and uses magic-number matching to
+// determine the real MIME type. Nothing is consumed from r, so the caller can
+// keep streaming the whole file from it afterwards.
 // This is exported so the handler layer can also do early content-based checks.
-func DetectFileType(file multipart.File) (ftypes.Type, error) {
-	// filetype needs at least 262 bytes; we read 512 to be safe.
-	buf := make([]byte, 512)
-	n, err := file.Read(buf)
-	if err != nil {
+func DetectFileType(r *bufio.Reader) (ftypes.Type, error) {
+	// filetype needs at least 262 bytes; we peek 512 to be safe.
+	head, err := r.Peek(fileTypeHeaderSize)
+	if len(head) == 0 {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
 		return ftypes.Unknown, fmt.Errorf("unable to read file header for type detection: %w", err)
 	}
-	if _, err := file.Seek(0, 0); err != nil {
-		return ftypes.Unknown, fmt.Errorf("unable to reset file reader: %w", err)
-	}
 
-	kind, err := filetype.Match(buf[:n])
+	kind, err := filetype.Match(head)
 	if err != nil {
 		return ftypes.Unknown, fmt.Errorf("file type detection failed: %w", err)
 	}
@@ -60,6 +65,22 @@ func DetectFileType(file multipart.File) (ftypes.Type, error) {
 	return kind, nil
 }
 
+// IsAnimatedWebP reports whether head, the start of a WebP file, is an
+// animated WebP: an extended (VP8X) file with the animation flag set or an
+// ANIM chunk in the header. filetype reports these as plain image/webp.
+func IsAnimatedWebP(head []byte) bool {
+	if len(head) < 21 || string(head[0:4]) != "RIFF" || string(head[8:12]) != "WEBP" || string(head[12:16]) != "VP8X" {
+		return false
+	}
+	const animationFlag = 0x02
+	return head[20]&animationFlag != 0 || bytes.Contains(head[21:], []byte("ANIM"))
+}
+
+// NewFileTypeReader wraps r in a buffered reader large enough for DetectFileType.
+func NewFileTypeReader(r io.Reader) *bufio.Reader {
+	return bufio.NewReaderSize(r, fileTypeHeaderSize)
+}
+
 // IsAllowedMIME checks whether a MIME string is in the allowed set.
 func IsAllowedMIME(mime string) bool {
 	_, ok := allowedFileTypes[mime]
@@ -73,6 +94,8 @@ type StorageService struct {
 	maxVideoSize      int64
 }
 
+// NewStorageService stores uploads under uploadDir. baseURL is the host
+// media URLs are built on, usually config.MediaBaseURL().
 func NewStorageService(uploadDir, baseURL string, maxImageSize, maxVideoSize int64) (*StorageService, error) {
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
 		return nil, err
@@ -86,14 +109,21 @@ func NewStorageService(uploadDir, baseURL string, maxImageSize, maxVideoSize int
 	}, nil
 }
 
-func (s *StorageService) SaveFile(file multipart.File, header *multipart.FileHeader, userID string) (*models.Media, error) {
+// SaveStream validates and writes an uploaded file read from src, without
+// buffering it in memory. originalName is only used for its extension.
+// baseURL overrides the service's base for the media URL when non-empty.
+// Validation runs in order: extension, magic number, then size while the
+// bytes are copied to disk.
+func (s *StorageService) SaveStream(src io.Reader, originalName, userID, baseURL string) (*models.Media, error) {
+	file := NewFileTypeReader(src)
+
 	// Reject empty files
-	if header.Size == 0 {
+	if _, err := file.Peek(1); err == io.EOF {
 		return nil, fmt.Errorf("empty files are not allowed")
 	}
 
 	// --- Extension validation ---
-	ext := strings.ToLower(filepath.Ext(header.Filename))
+	ext := strings.ToLower(filepath.Ext(originalName))
 	if ext == "" {
 		return nil, fmt.Errorf("file must have an extension (e.g. .jpg, .png, .mp4)")
 	}
@@ -128,20 +158,25 @@ func (s *StorageService) SaveFile(file multipart.File, header *multipart.FileHea
 		return nil, fmt.Errorf("file extension %s does not match detected content type %s; possible file spoofing", ext, detectedMIME)
 	}
 
-	// --- Determine media type and enforce per-type size limits ---
+	// --- Determine media type and its size limit ---
+	// The total size is unknown until the stream ends, so the limit is
+	// enforced while copying below.
 	var mediaType models.MediaType
+	var maxSize int64
 	if strings.HasPrefix(detectedMIME, "image/") {
 		mediaType = models.MediaImage
-		if header.Size > s.maxImageSize {
-			return nil, fmt.Errorf("image size %d bytes exceeds maximum allowed size of %d bytes (%d MB)",
-				header.Size, s.maxImageSize, s.maxImageSize/(1<<20))
-		}
+		maxSize = s.maxImageSize
 	} else if strings.HasPrefix(detectedMIME, "video/") {
 		mediaType = models.MediaVideo
-		if header.Size > s.maxVideoSize {
-			return nil, fmt.Errorf("video size %d bytes exceeds maximum allowed size of %d bytes (%d MB)",
-				header.Size, s.maxVideoSize, s.maxVideoSize/(1<<20))
-		}
+		maxSize = s.maxVideoSize
+	}
+
+	// Animated WebP is stored as an image but flagged, so publishers can
+	// refuse it instead of posting a still frame.
+	isAnimated := false
+	if detectedMIME == "image/webp" {
+		head, _ := file.Peek(fileTypeHeaderSize)
+		isAnimated = IsAnimatedWebP(head)
 	}
 
 	// --- Sanitize filename: use only the validated extension, discard original name ---
@@ -163,14 +198,8 @@ func (s *StorageService) SaveFile(file multipart.File, header *multipart.FileHea
 	}
 	defer dst.Close()
 
-	// Copy with a size-limited reader to prevent the actual bytes from exceeding the declared size.
-	// This guards against tampered Content-Length headers.
-	var maxSize int64
-	if mediaType == models.MediaVideo {
-		maxSize = s.maxVideoSize
-	} else {
-		maxSize = s.maxImageSize
-	}
+	// Copy with a size-limited reader so the stream can never exceed the
+	// per-type limit, whatever Content-Length the client declared.
 	limitedReader := io.LimitReader(file, maxSize+1)
 	written, err := io.Copy(dst, limitedReader)
 	if err != nil {
@@ -179,19 +208,36 @@ func (s *StorageService) SaveFile(file multipart.File, header *multipart.FileHea
 	}
 	if written > maxSize {
 		os.Remove(filePath)
-		return nil, fmt.Errorf("file stream exceeded maximum allowed size of %d MB", maxSize/(1<<20))
+		return nil, fmt.Errorf("%s exceeds maximum allowed size of %d bytes (%d MB)", mediaType, maxSize, maxSize/(1<<20))
+	}
+
+	// Record the video resolution and bitrate so platform limits can be
+	// checked before publishing. A file we cannot parse is still stored;
+	// the platform will judge it.
+	var video VideoInfo
+	if detectedMIME == "video/mp4" {
+		if video, err = ProbeMP4(filePath); err != nil {
+			utils.Warnf("mp4 probe failed path=%s err=%v", filePath, err)
+		}
 	}
 
+	if baseURL == "" {
+		baseURL = s.baseURL
+	}
 	media := &models.Media{
-		ID:        uuid.New().String(),
-		UserID:    userID,
-		Filename:  filename,
-		Path:      filePath,
-		URL:       fmt.Sprintf("%s/uploads/%s/%s", s.baseURL, userID, filename),
-		Type:      mediaType,
-		Size:      written,
-		MimeType:  detectedMIME,
-		CreatedAt: time.Now(),
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Filename:    filename,
+		Path:        filePath,
+		URL:         fmt.Sprintf("%s/uploads/%s/%s", baseURL, userID, filename),
+		Type:        mediaType,
+		Size:        written,
+		MimeType:    detectedMIME,
+		IsAnimated:  isAnimated,
+		Width:       video.Width,
+		Height:      video.Height,
+		BitrateKbps: video.BitrateKbps(written),
+		CreatedAt:   time.Now(),
 	}
 
 	return media, nil
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenExpiryNotifier emails users whose platform tokens are about to
+// expire, so they reconnect before a scheduled post fails. Each credential
+// is only reported once until it is reconnected.
+type TokenExpiryNotifier struct {
+	db     *database.Database
+	mailer Mailer
+}
+
+// NewTokenExpiryNotifier creates the notifier. nil mailer means NoopMailer.
+func NewTokenExpiryNotifier(db *database.Database, mailer Mailer) *TokenExpiryNotifier {
+	if mailer == nil {
+		mailer = NoopMailer{}
+	}
+	return &TokenExpiryNotifier{db: db, mailer: mailer}
+}
+
+// Run sends one reminder per user covering every credential that expires
+// within TOKEN_EXPIRY_WARNING_DAYS. Failures are logged and retried on the
+// next run.
+func (n *TokenExpiryNotifier) Run() {
+	warning := config.Load().TokenExpiryWarning
+	if warning <= 0 {
+		return
+	}
+
+	creds, err := n.db.GetCredentialsExpiringBefore(time.Now().Add(warning))
+	if err != nil {
+		utils.Errorf("token expiry check failed err=%v", err)
+		return
+	}
+
+	byUser := make(map[string][]*models.PlatformCredentials)
+	userIDs := []string{}
+	for _, cred := range creds {
+		if _, ok := byUser[cred.UserID]; !ok {
+			userIDs = append(userIDs, cred.UserID)
+		}
+		byUser[cred.UserID] = append(byUser[cred.UserID], cred)
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		if err := n.notify(userID, byUser[userID]); err != nil {
+			utils.Errorf("token expiry email failed user_id=%s err=%v", userID, err)
+			continue
+		}
+		sent++
+	}
+	if sent > 0 {
+		utils.Infof("token expiry emails sent users=%d credentials=%d", sent, len(creds))
+	}
+}
+
+func (n *TokenExpiryNotifier) notify(userID string, creds []*models.PlatformCredentials) error {
+	user, err := n.db.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var body strings.Builder
+	body.WriteString("Some of your connected accounts need to be reconnected soon, or posts to them will fail:\n\n")
+	ids := make([]string, len(creds))
+	for i, cred := range creds {
+		ids[i] = cred.ID
+		account := string(cred.Platform)
+		if cred.PlatformUsername != "" {
+			account += " (" + cred.PlatformUsername + ")"
+		}
+		verb := "expires"
+		if cred.ExpiresAt.Before(now) {
+			verb = "expired"
+		}
+		fmt.Fprintf(&body, "- %s %s on %s\n", account, verb, cred.ExpiresAt.UTC().Format(time.RFC1123))
+	}
+	body.WriteString("\nReconnect them from your dashboard, or with GET /api/auth/{platform}.\n")
+
+	if err := n.mailer.Send(user.Email, "Reconnect your social accounts", body.String()); err != nil {
+		return err
+	}
+	return n.db.MarkCredentialsExpiryNotified(ids)
+}
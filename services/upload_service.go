@@ -0,0 +1,243 @@
+package services
+
+import (
+	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUploadNotFound       = errors.New("upload not found")
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match the bytes received so far")
+	ErrUploadLocked         = errors.New("another request is already appending to this upload")
+	ErrUploadTooLarge       = errors.New("upload length exceeds the maximum upload size")
+	// ErrUploadRejected wraps the validation error of a completed upload whose
+	// content was not accepted (wrong type, too large for its media type, ...).
+	ErrUploadRejected = errors.New("upload rejected")
+)
+
+// UploadService implements resumable uploads (the tus protocol core): an
+// upload is created with its total length, the client appends chunks at the
+// current offset, and a dropped connection only loses the unfinished chunk.
+// When the last byte arrives the partial file goes through the same
+// validation as a regular upload and becomes a Media item.
+type UploadService struct {
+	db      *database.Database
+	storage *StorageService
+	dir     string
+	maxSize int64
+	expiry  time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]bool // uploads currently being appended to
+}
+
+func NewUploadService(db *database.Database, storage *StorageService, dir string, maxSize int64, expiry time.Duration) (*UploadService, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &UploadService{
+		db:       db,
+		storage:  storage,
+		dir:      dir,
+		maxSize:  maxSize,
+		expiry:   expiry,
+		inFlight: make(map[string]bool),
+	}, nil
+}
+
+func (s *UploadService) partialPath(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+// Create registers a new upload of length bytes and creates its empty partial file.
+func (s *UploadService) Create(userID, filename string, length int64, altText, twitterMediaCategory string) (*models.MediaUpload, error) {
+	if length > s.maxSize {
+		return nil, ErrUploadTooLarge
+	}
+	s.purgeExpired()
+
+	now := time.Now()
+	upload := &models.MediaUpload{
+		ID:                   uuid.New().String(),
+		UserID:               userID,
+		Filename:             filename,
+		Length:               length,
+		AltText:              altText,
+		TwitterMediaCategory: twitterMediaCategory,
+		ExpiresAt:            now.Add(s.expiry),
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	f, err := os.OpenFile(s.partialPath(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	if err := s.db.CreateUpload(upload); err != nil {
+		os.Remove(s.partialPath(upload.ID))
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get returns the user's upload. Uploads of other users and expired uploads
+// are reported as ErrUploadNotFound.
+func (s *UploadService) Get(userID, id string) (*models.MediaUpload, error) {
+	upload, err := s.db.GetUpload(id)
+	if err == sql.ErrNoRows {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if upload.UserID != userID || time.Now().After(upload.ExpiresAt) {
+		return nil, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// Append writes body to the upload at offset, which must equal the number of
+// bytes received so far. Whatever arrives before the connection drops is
+// kept. When the upload completes, the returned Media is the stored file,
+// with its URL built on baseURL (see StorageService.SaveStream).
+func (s *UploadService) Append(userID, id string, offset int64, body io.Reader, baseURL string) (*models.MediaUpload, *models.Media, error) {
+	s.mu.Lock()
+	if s.inFlight[id] {
+		s.mu.Unlock()
+		return nil, nil, ErrUploadLocked
+	}
+	s.inFlight[id] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.inFlight, id)
+		s.mu.Unlock()
+	}()
+
+	upload, err := s.Get(userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if upload.Complete() || offset != upload.Offset {
+		return upload, nil, ErrUploadOffsetMismatch
+	}
+
+	// The stored offset is the truth: bytes past it were written by an append
+	// whose offset update failed or never ran, and the client sends them again
+	f, err := os.OpenFile(s.partialPath(id), os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := f.Truncate(upload.Offset); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if _, err := f.Seek(upload.Offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	written, copyErr := io.Copy(f, io.LimitReader(body, upload.Length-upload.Offset))
+	closeErr := f.Close()
+
+	if written > 0 {
+		upload.Offset += written
+		if err := s.db.UpdateUploadOffset(id, upload.Offset); err != nil {
+			return nil, nil, err
+		}
+	}
+	if copyErr != nil {
+		utils.Warnf("resumable upload interrupted upload_id=%s offset=%d err=%v", id, upload.Offset, copyErr)
+		return upload, nil, copyErr
+	}
+	if closeErr != nil {
+		return nil, nil, closeErr
+	}
+
+	if !upload.Complete() {
+		return upload, nil, nil
+	}
+
+	media, err := s.finalize(upload, baseURL)
+	if err != nil {
+		return upload, nil, err
+	}
+	return upload, media, nil
+}
+
+// finalize validates the completed partial file and stores it as media.
+// A rejected file is discarded together with its upload.
+func (s *UploadService) finalize(upload *models.MediaUpload, baseURL string) (*models.Media, error) {
+	path := s.partialPath(upload.ID)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	media, err := s.storage.SaveStream(f, upload.Filename, upload.UserID, baseURL)
+	f.Close()
+	if err != nil {
+		s.discard(upload.ID)
+		return nil, fmt.Errorf("%w: %v", ErrUploadRejected, err)
+	}
+	media.AltText = upload.AltText
+	media.TwitterMediaCategory = upload.TwitterMediaCategory
+
+	if err := s.db.CreateMedia(media); err != nil {
+		s.storage.DeleteFile(media)
+		return nil, err
+	}
+	if err := s.db.CompleteUpload(upload.ID, media.ID); err != nil {
+		utils.Errorf("resumable upload complete failed upload_id=%s media_id=%s err=%v", upload.ID, media.ID, err)
+	}
+	upload.MediaID = media.ID
+	os.Remove(path)
+
+	utils.Infof("resumable upload complete upload_id=%s media_id=%s size=%d", upload.ID, media.ID, media.Size)
+	return media, nil
+}
+
+// Terminate discards an upload and its partial file.
+func (s *UploadService) Terminate(userID, id string) error {
+	if _, err := s.Get(userID, id); err != nil {
+		return err
+	}
+	return s.discard(id)
+}
+
+func (s *UploadService) discard(id string) error {
+	if err := s.db.DeleteUpload(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.partialPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// purgeExpired removes expired uploads and their partial files. It runs
+// opportunistically when uploads are created; failures are only logged.
+func (s *UploadService) purgeExpired() {
+	ids, err := s.db.DeleteExpiredUploads(time.Now())
+	if err != nil {
+		utils.Warnf("resumable upload purge failed err=%v", err)
+		return
+	}
+	for _, id := range ids {
+		os.Remove(s.partialPath(id))
+	}
+	if len(ids) > 0 {
+		utils.Infof("resumable upload purge removed=%d", len(ids))
+	}
+}
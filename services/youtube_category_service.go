@@ -0,0 +1,112 @@
+package services
+
+import (
+	"SocialMediaAPI/config"
+	"SocialMediaAPI/database"
+	"SocialMediaAPI/models"
+	"SocialMediaAPI/publishers"
+	"SocialMediaAPI/utils"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// youtubeCategoryCacheTTL controls how long a region's category list is reused.
+// YouTube's category list changes very rarely, so a long TTL is safe.
+const youtubeCategoryCacheTTL = 24 * time.Hour
+
+var (
+	// ErrYouTubeNotConnected is returned by ListCategories when the user has no
+	// YouTube credential to fetch an uncached region with.
+	ErrYouTubeNotConnected = errors.New("YouTube account is not connected")
+	// ErrInvalidYouTubeCategory is wrapped by ValidateCategory's error when the
+	// category does not exist or cannot be assigned to videos.
+	ErrInvalidYouTubeCategory = errors.New("invalid YouTube category")
+)
+
+type youtubeCategoryCacheEntry struct {
+	categories []publishers.YouTubeCategory
+	fetchedAt  time.Time
+}
+
+// YouTubeCategoryService lists YouTube video categories per region and caches
+// the result, since the list is identical for every user in the same region.
+type YouTubeCategoryService struct {
+	db          *database.Database
+	publisher   *publishers.YouTubePublisher
+	credentials *PublisherService // refreshes expired YouTube tokens
+	mu          sync.RWMutex
+	cache       map[string]youtubeCategoryCacheEntry
+}
+
+func NewYouTubeCategoryService(db *database.Database, credentials *PublisherService) *YouTubeCategoryService {
+	cfg := config.Load()
+	return &YouTubeCategoryService{
+		db:          db,
+		publisher:   publishers.NewYouTubePublisher(nil, cfg.YouTubeAPIBase, cfg.GoogleOAuthBase),
+		credentials: credentials,
+		cache:       make(map[string]youtubeCategoryCacheEntry),
+	}
+}
+
+// ListCategories returns the categories for a region, fetching them with the
+// user's YouTube token when the region is not cached yet.
+func (s *YouTubeCategoryService) ListCategories(userID, regionCode string) ([]publishers.YouTubeCategory, error) {
+	s.mu.RLock()
+	entry, ok := s.cache[regionCode]
+	s.mu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < youtubeCategoryCacheTTL {
+		return entry.categories, nil
+	}
+
+	cred, err := s.db.GetCredentials(userID, models.YouTube)
+	if err != nil {
+		return nil, err
+	}
+	if cred == nil || cred.AccessToken == "" {
+		return nil, ErrYouTubeNotConnected
+	}
+	// YouTube access tokens last an hour
+	cred, err = s.credentials.FreshCredentials(cred)
+	if err != nil {
+		return nil, fmt.Errorf("refresh YouTube token: %w", err)
+	}
+
+	categories, err := s.publisher.ListVideoCategories(cred.AccessToken, regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[regionCode] = youtubeCategoryCacheEntry{categories: categories, fetchedAt: time.Now()}
+	s.mu.Unlock()
+
+	return categories, nil
+}
+
+// ValidateCategory checks that categoryID is assignable in the given region;
+// the error wraps ErrInvalidYouTubeCategory when it is not. When YouTube is
+// not connected yet the check is skipped and nil is returned; the upload
+// itself will surface the error. Any other failure to fetch the list is
+// returned as is.
+func (s *YouTubeCategoryService) ValidateCategory(userID, regionCode, categoryID string) error {
+	categories, err := s.ListCategories(userID, regionCode)
+	if errors.Is(err, ErrYouTubeNotConnected) {
+		utils.Infof("youtube category validation skipped, not connected user_id=%s region=%s", userID, regionCode)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, c := range categories {
+		if c.ID == categoryID {
+			if !c.Assignable {
+				return fmt.Errorf("%w: %s (%s) is not assignable to videos", ErrInvalidYouTubeCategory, c.ID, c.Title)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s does not exist in region %s", ErrInvalidYouTubeCategory, categoryID, regionCode)
+}
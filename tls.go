@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// certCheckInterval is how often GetCertificate stats the certificate files
+// for changes, so handshakes don't each hit the filesystem.
+const certCheckInterval = 10 * time.Second
+
+// certReloader serves the TLS certificate from TLS_CERT_FILE/TLS_KEY_FILE
+// through tls.Config.GetCertificate, so a renewed certificate can be picked
+// up without restarting the server: it is reloaded when the files' mtime
+// changes, or on SIGHUP.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	ocspFile string // DER-encoded OCSP response stapled to the certificate; optional
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTimes  [3]time.Time // cert, key and OCSP file mtimes the current cert was loaded from
+	lastCheck time.Time
+}
+
+func newCertReloader(certFile, keyFile, ocspFile string) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile, ocspFile: ocspFile}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// reload reads the certificate, key and OCSP response from disk. The
+// previous certificate stays in use when any of them cannot be loaded.
+func (c *certReloader) reload() error {
+	modTimes := c.fileModTimes()
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS certificate: %w", err)
+	}
+	if c.ocspFile != "" {
+		staple, err := os.ReadFile(c.ocspFile)
+		if err != nil {
+			return fmt.Errorf("read OCSP response: %w", err)
+		}
+		cert.OCSPStaple = staple
+	}
+
+	c.mu.Lock()
+	c.cert = &cert
+	c.modTimes = modTimes
+	c.lastCheck = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// fileModTimes stats the certificate, key and OCSP files. A file that
+// cannot be stat'ed gets the zero time.
+func (c *certReloader) fileModTimes() [3]time.Time {
+	var modTimes [3]time.Time
+	for i, path := range []string{c.certFile, c.keyFile, c.ocspFile} {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.reloadIfChanged()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// reloadIfChanged reloads the certificate when any of its files has a new
+// mtime, checking at most once per certCheckInterval.
+func (c *certReloader) reloadIfChanged() {
+	c.mu.Lock()
+	if time.Since(c.lastCheck) < certCheckInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.lastCheck = time.Now()
+	loaded := c.modTimes
+	c.mu.Unlock()
+
+	if c.fileModTimes() == loaded {
+		return
+	}
+	// Renewal tools may write the cert and key separately; a failed load
+	// keeps the current certificate and is retried on the next check.
+	if err := c.reload(); err != nil {
+		log.Printf("TLS certificate changed on disk but reload failed: %v", err)
+		return
+	}
+	log.Println("TLS certificate reloaded after file change")
+}
+
+// reloadOnSIGHUP reloads the certificate every time the process gets SIGHUP.
+func (c *certReloader) reloadOnSIGHUP() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := c.reload(); err != nil {
+				log.Printf("TLS certificate reload failed, keeping the current one: %v", err)
+				continue
+			}
+			log.Println("TLS certificate reloaded")
+		}
+	}()
+}
+
+// tlsCipherSuites maps TLS_CIPHER_SUITES names (as in crypto/tls, e.g.
+// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) to their IDs. Only suites Go
+// considers secure are accepted. An empty list keeps Go's defaults.
+func tlsCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedPair writes a new self-signed certificate and key for cn to
+// certFile and keyFile, sets both files' mtime to modTime and returns the
+// certificate's DER bytes.
+func writeSelfSignedPair(t *testing.T, certFile, keyFile, cn string, modTime time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), modTime)
+	writeFile(t, keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), modTime)
+	return der
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// servedCert forces the next GetCertificate to check the files and returns
+// the DER bytes of the certificate it serves.
+func servedCert(t *testing.T, c *certReloader) []byte {
+	t.Helper()
+	c.mu.Lock()
+	c.lastCheck = time.Now().Add(-2 * certCheckInterval)
+	c.mu.Unlock()
+
+	cert, err := c.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Certificate[0]
+}
+
+func TestCertReloaderReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	start := time.Now().Add(-time.Hour)
+
+	oldDER := writeSelfSignedPair(t, certFile, keyFile, "old.example.com", start)
+	c, err := newCertReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+	if got := servedCert(t, c); !bytes.Equal(got, oldDER) {
+		t.Fatal("initial certificate not served")
+	}
+
+	newDER := writeSelfSignedPair(t, certFile, keyFile, "new.example.com", start.Add(time.Minute))
+	if got := servedCert(t, c); !bytes.Equal(got, newDER) {
+		t.Error("renewed certificate not served after the files changed")
+	}
+}
+
+func TestCertReloaderSkipsCheckWithinInterval(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	start := time.Now().Add(-time.Hour)
+
+	oldDER := writeSelfSignedPair(t, certFile, keyFile, "old.example.com", start)
+	c, err := newCertReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+
+	writeSelfSignedPair(t, certFile, keyFile, "new.example.com", start.Add(time.Minute))
+	cert, err := c.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cert.Certificate[0], oldDER) {
+		t.Error("certificate reloaded before certCheckInterval elapsed")
+	}
+}
+
+func TestCertReloaderKeepsCertificateWhenKeyIsBroken(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	start := time.Now().Add(-time.Hour)
+
+	oldDER := writeSelfSignedPair(t, certFile, keyFile, "old.example.com", start)
+	c, err := newCertReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+
+	writeFile(t, keyFile, []byte("not a key"), start.Add(time.Minute))
+	if got := servedCert(t, c); !bytes.Equal(got, oldDER) {
+		t.Error("certificate replaced although the new key could not be loaded")
+	}
+
+	// Once the pair is valid again the next check picks it up.
+	newDER := writeSelfSignedPair(t, certFile, keyFile, "new.example.com", start.Add(2*time.Minute))
+	if got := servedCert(t, c); !bytes.Equal(got, newDER) {
+		t.Error("certificate not reloaded after the key was fixed")
+	}
+}
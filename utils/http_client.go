@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"SocialMediaAPI/config"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewHTTPClient returns a client for outbound platform API calls. Idle
+// connections are kept per host so repeated calls to the same API reuse
+// TCP/TLS connections; dial and handshake are bounded separately from the
+// overall request timeout. Requests go through outboundTransport.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &outboundTransport{next: transport, userAgent: config.Load().HTTPUserAgent},
+	}
+}
+
+// outboundTransport is the RoundTripper behind every platform API and OAuth
+// client. It sets the User-Agent (platforms flag or throttle Go's default),
+// logs each call at debug level and counts it in OutboundMetrics. Only the
+// method, host, status and duration are logged: URLs can carry tokens in
+// their query, and headers and bodies carry credentials.
+type outboundTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *outboundTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	OutboundMetrics.Record(req.URL.Host, status, duration)
+	if err != nil {
+		Debugf("outbound request failed method=%s host=%s duration=%s err=%v", req.Method, req.URL.Host, duration, err)
+	} else {
+		Debugf("outbound request method=%s host=%s status=%d duration=%s", req.Method, req.URL.Host, status, duration)
+	}
+	return resp, err
+}
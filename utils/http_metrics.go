@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutboundMetrics counts the requests sent through NewHTTPClient clients.
+var OutboundMetrics = newHTTPMetrics()
+
+type httpMetricsKey struct {
+	host   string
+	status string
+}
+
+// HTTPMetrics counts outbound requests by host and status class (2xx, 4xx,
+// ..., or "error" when no response came back) and sums their duration per
+// host, so slow or failing platform APIs show up on /metrics.
+type HTTPMetrics struct {
+	mu        sync.Mutex
+	requests  map[httpMetricsKey]uint64
+	durations map[string]time.Duration
+}
+
+func newHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{
+		requests:  make(map[httpMetricsKey]uint64),
+		durations: make(map[string]time.Duration),
+	}
+}
+
+// Record counts one request to host. status is 0 when the request failed
+// without a response.
+func (m *HTTPMetrics) Record(host string, status int, duration time.Duration) {
+	class := "error"
+	if status > 0 {
+		class = fmt.Sprintf("%dxx", status/100)
+	}
+
+	m.mu.Lock()
+	m.requests[httpMetricsKey{host: host, status: class}]++
+	m.durations[host] += duration
+	m.mu.Unlock()
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format.
+func (m *HTTPMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	keys := make([]httpMetricsKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	hosts := make([]string, 0, len(m.durations))
+	for h := range m.durations {
+		hosts = append(hosts, h)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].host != keys[j].host {
+			return keys[i].host < keys[j].host
+		}
+		return keys[i].status < keys[j].status
+	})
+	sort.Strings(hosts)
+
+	var b strings.Builder
+	b.WriteString("# HELP outbound_requests_total Outbound HTTP requests by host and status class.\n")
+	b.WriteString("# TYPE outbound_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "outbound_requests_total{host=%q,status=%q} %d\n", k.host, k.status, m.requests[k])
+	}
+	b.WriteString("# HELP outbound_request_duration_seconds_total Time spent on outbound HTTP requests by host.\n")
+	b.WriteString("# TYPE outbound_request_duration_seconds_total counter\n")
+	for _, h := range hosts {
+		fmt.Fprintf(&b, "outbound_request_duration_seconds_total{host=%q} %g\n", h, m.durations[h].Seconds())
+	}
+	m.mu.Unlock()
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
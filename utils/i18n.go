@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is used when a request names no supported language, and
+// for keys missing from another language's catalog.
+const DefaultLanguage = "en"
+
+// Localize returns the message for key in lang, formatted with args like
+// fmt.Sprintf. It falls back to English when lang or the key is not in the
+// catalog, and to the key itself when no language has it.
+func Localize(lang, key string, args ...interface{}) string {
+	format, ok := messages[lang][key]
+	if !ok {
+		format, ok = messages[DefaultLanguage][key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// RequestLanguage returns the supported language the client prefers most
+// according to its Accept-Language header, or DefaultLanguage. Regional tags
+// match their base language ("es-MX" selects "es").
+func RequestLanguage(r *http.Request) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{lang: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.lang == "*" {
+			return DefaultLanguage
+		}
+		if _, ok := messages[c.lang]; ok {
+			return c.lang
+		}
+		base, _, _ := strings.Cut(c.lang, "-")
+		if _, ok := messages[base]; ok {
+			return base
+		}
+	}
+	return DefaultLanguage
+}
+
+// LocalizeRequest is Localize in the language of r's Accept-Language header.
+func LocalizeRequest(r *http.Request, key string, args ...interface{}) string {
+	return Localize(RequestLanguage(r), key, args...)
+}
@@ -0,0 +1,188 @@
+package utils
+
+// messages is the catalog used by Localize: language -> key -> fmt format.
+// Every key must exist in English; other languages may omit keys, which then
+// fall back to English. Keep the format verbs in the same order in every
+// language.
+var messages = map[string]map[string]string{
+	"en": {
+		// Generic request and auth errors
+		"request.invalid_payload":       "Invalid request payload",
+		"request.body_too_large":        "Request body too large; maximum allowed is %s",
+		"auth.missing_header":           "Missing authorization header",
+		"auth.invalid_header":           "Invalid authorization header",
+		"auth.invalid_token":            "Invalid token",
+		"auth.invalid_credentials":      "invalid credentials",
+		"auth.insufficient_permissions": "Insufficient permissions",
+		"auth.token_generation_failed":  "Error generating token",
+		"auth.user_not_in_context":      "User ID not found in request context",
+		"auth.reset_email_sent":         "If an account exists for that email, a password reset email has been sent",
+		"auth.invalid_reset_token":      "Invalid or expired reset token",
+		"auth.password_too_short":       "Password must be at least %d characters",
+		"auth.reset_failed":             "Error resetting password",
+		"auth.password_reset":           "Password has been reset. Log in with your new password",
+
+		// Post validation
+		"post.content_required":          "Content is required",
+		"post.platform_required":         "At least one platform is required",
+		"post.test_platform_invalid":     "test_platform %s is not one of the post's platforms",
+		"post.account_invalid":           "accounts.%s is not a connected account of that platform, or the platform is not selected",
+		"post.content_platform_invalid":  "platform_content.%s is not one of the selected platforms",
+		"post.invalid_post_type":         "Invalid post_type. Must be 'normal', 'short', or 'story'",
+		"post.invalid_privacy_level":     "Invalid privacy_level. Must be 'public', 'followers', 'friends', or 'private'",
+		"post.platform_not_for_type":     "%s does not support %s posts; supported platforms: %s",
+		"post.short_requires_video":      "Short posts require at least one video media attachment",
+		"post.story_requires_media":      "Story posts require at least one image or video media attachment",
+		"post.story_single_media":        "A story is a single image or video; attach only one media file",
+		"post.content_over_limit":        "Content exceeds the character limit of: %s",
+		"post.content_over_limit_hint":   "Shorten the content or set allow_truncation to true to cut it to each platform's limit",
+		"post.invalid_media_ids":         "Invalid media IDs",
+		"post.media_not_found":           "One or more media IDs were not found",
+		"post.media_access_denied":       "Access denied to media",
+		"post.subtitles_format":          "subtitles must be in SRT format",
+		"post.animated_webp_unsupported": "animated WebP cannot be published; convert it to GIF or MP4",
+		"post.video_over_limit":          "video exceeds the resolution or bitrate limit for %s",
+		"post.media_required":            "Missing media required by: %s",
+		"post.thumbnail_not_found":       "thumbnail media not found",
+		"post.thumbnail_not_image":       "thumbnail must be an image",
+		"post.subtitles_too_large":       "subtitles must be at most 512 KB",
+		"post.subtitles_require_video":   "subtitles require a video media attachment",
+		"post.create_scheduled_failed":   "Error creating post scheduled for future",
+		"post.create_failed":             "Error creating post now",
+		"post.queue_failed":              "Error queueing the post for publishing. It was saved as a draft; try again later",
+		"post.template_and_content":      "Provide either content or template_id, not both",
+		"post.template_not_found":        "Template not found",
+		"post.template_missing_vars":     "Missing template variables: %s",
+		"post.made_for_kids_required":    "YouTube posts require made_for_kids to be set to true or false",
+		"post.tags_too_long":             "Tags must be at most %d characters in total",
+		"post.invalid_tag":               "Tags must not be empty or contain < or >",
+		"post.not_found":                 "Post not found",
+		"post.access_denied":             "Access denied",
+		"post.not_editable":              "Only draft and scheduled posts can be edited",
+		"post.update_failed":             "Error updating post",
+
+		// Publish results
+		"publish.failed":         "Failed to publish to one or more platforms",
+		"publish.busy":           "Too many posts are being published right now. The post was saved as a draft; try again shortly",
+		"publish.failed_hint":    "Check publish_response.results for platform-specific details",
+		"publish.failed_summary": "Failed platforms: %s",
+	},
+	"es": {
+		"request.invalid_payload":       "Cuerpo de la solicitud no válido",
+		"request.body_too_large":        "Cuerpo de la solicitud demasiado grande; el máximo permitido es %s",
+		"auth.missing_header":           "Falta la cabecera de autorización",
+		"auth.invalid_header":           "Cabecera de autorización no válida",
+		"auth.invalid_token":            "Token no válido",
+		"auth.invalid_credentials":      "credenciales no válidas",
+		"auth.insufficient_permissions": "Permisos insuficientes",
+		"auth.token_generation_failed":  "Error al generar el token",
+		"auth.user_not_in_context":      "No se encontró el ID de usuario en la solicitud",
+		"auth.reset_email_sent":         "Si existe una cuenta con ese correo, se ha enviado un correo para restablecer la contraseña",
+		"auth.invalid_reset_token":      "Token de restablecimiento no válido o caducado",
+		"auth.password_too_short":       "La contraseña debe tener al menos %d caracteres",
+		"auth.reset_failed":             "Error al restablecer la contraseña",
+		"auth.password_reset":           "La contraseña se ha restablecido. Inicia sesión con tu nueva contraseña",
+
+		"post.content_required":          "El contenido es obligatorio",
+		"post.platform_required":         "Se requiere al menos una plataforma",
+		"post.test_platform_invalid":     "test_platform %s no está entre las plataformas de la publicación",
+		"post.account_invalid":           "accounts.%s no es una cuenta conectada de esa plataforma, o la plataforma no está seleccionada",
+		"post.content_platform_invalid":  "platform_content.%s no es una de las plataformas seleccionadas",
+		"post.invalid_post_type":         "post_type no válido. Debe ser 'normal', 'short' o 'story'",
+		"post.invalid_privacy_level":     "privacy_level no válido. Debe ser 'public', 'followers', 'friends' o 'private'",
+		"post.platform_not_for_type":     "%s no admite publicaciones de tipo %s; plataformas admitidas: %s",
+		"post.short_requires_video":      "Las publicaciones cortas requieren al menos un vídeo adjunto",
+		"post.story_requires_media":      "Las historias requieren al menos una imagen o un vídeo adjunto",
+		"post.story_single_media":        "Una historia es una sola imagen o vídeo; adjunta solo un archivo",
+		"post.content_over_limit":        "El contenido supera el límite de caracteres de: %s",
+		"post.content_over_limit_hint":   "Acorta el contenido o establece allow_truncation en true para recortarlo al límite de cada plataforma",
+		"post.invalid_media_ids":         "IDs de medios no válidos",
+		"post.media_not_found":           "No se encontraron uno o más IDs de medios",
+		"post.media_access_denied":       "Acceso denegado al medio",
+		"post.subtitles_format":          "los subtítulos deben estar en formato SRT",
+		"post.animated_webp_unsupported": "los WebP animados no se pueden publicar; conviértelos a GIF o MP4",
+		"post.video_over_limit":          "el vídeo supera el límite de resolución o tasa de bits de %s",
+		"post.media_required":            "Falta el contenido multimedia que requiere: %s",
+		"post.thumbnail_not_found":       "no se encontró el medio de la miniatura",
+		"post.thumbnail_not_image":       "la miniatura debe ser una imagen",
+		"post.subtitles_too_large":       "los subtítulos no pueden superar los 512 KB",
+		"post.subtitles_require_video":   "los subtítulos requieren un vídeo adjunto",
+		"post.create_scheduled_failed":   "Error al crear la publicación programada",
+		"post.create_failed":             "Error al crear la publicación",
+		"post.queue_failed":              "Error al poner la publicación en cola. Se guardó como borrador; inténtalo más tarde",
+		"post.template_and_content":      "Indica content o template_id, no ambos",
+		"post.template_not_found":        "Plantilla no encontrada",
+		"post.template_missing_vars":     "Faltan variables de la plantilla: %s",
+		"post.made_for_kids_required":    "Las publicaciones de YouTube requieren indicar made_for_kids como true o false",
+		"post.tags_too_long":             "Las etiquetas no pueden superar los %d caracteres en total",
+		"post.invalid_tag":               "Las etiquetas no pueden estar vacías ni contener < o >",
+		"post.not_found":                 "Publicación no encontrada",
+		"post.access_denied":             "Acceso denegado",
+		"post.not_editable":              "Solo se pueden editar publicaciones en borrador o programadas",
+		"post.update_failed":             "Error al actualizar la publicación",
+
+		"publish.failed":         "No se pudo publicar en una o más plataformas",
+		"publish.busy":           "Se están publicando demasiadas publicaciones ahora mismo. La publicación se guardó como borrador; inténtalo de nuevo en breve",
+		"publish.failed_hint":    "Consulta publish_response.results para ver los detalles de cada plataforma",
+		"publish.failed_summary": "Plataformas con error: %s",
+	},
+	"fr": {
+		"request.invalid_payload":       "Corps de requête invalide",
+		"request.body_too_large":        "Corps de requête trop volumineux ; le maximum autorisé est %s",
+		"auth.missing_header":           "En-tête d'autorisation manquant",
+		"auth.invalid_header":           "En-tête d'autorisation invalide",
+		"auth.invalid_token":            "Jeton invalide",
+		"auth.invalid_credentials":      "identifiants invalides",
+		"auth.insufficient_permissions": "Permissions insuffisantes",
+		"auth.token_generation_failed":  "Erreur lors de la génération du jeton",
+		"auth.user_not_in_context":      "ID utilisateur introuvable dans la requête",
+		"auth.reset_email_sent":         "Si un compte existe pour cette adresse, un e-mail de réinitialisation du mot de passe a été envoyé",
+		"auth.invalid_reset_token":      "Jeton de réinitialisation invalide ou expiré",
+		"auth.password_too_short":       "Le mot de passe doit contenir au moins %d caractères",
+		"auth.reset_failed":             "Erreur lors de la réinitialisation du mot de passe",
+		"auth.password_reset":           "Le mot de passe a été réinitialisé. Connectez-vous avec votre nouveau mot de passe",
+
+		"post.content_required":          "Le contenu est obligatoire",
+		"post.platform_required":         "Au moins une plateforme est requise",
+		"post.test_platform_invalid":     "test_platform %s ne fait pas partie des plateformes de la publication",
+		"post.account_invalid":           "accounts.%s n'est pas un compte connecté de cette plateforme, ou la plateforme n'est pas sélectionnée",
+		"post.content_platform_invalid":  "platform_content.%s ne fait pas partie des plateformes sélectionnées",
+		"post.invalid_post_type":         "post_type invalide. Valeurs possibles : 'normal', 'short' ou 'story'",
+		"post.invalid_privacy_level":     "privacy_level invalide. Valeurs possibles : 'public', 'followers', 'friends' ou 'private'",
+		"post.platform_not_for_type":     "%s ne prend pas en charge les publications de type %s ; plateformes prises en charge : %s",
+		"post.short_requires_video":      "Les publications courtes nécessitent au moins une vidéo jointe",
+		"post.story_requires_media":      "Les stories nécessitent au moins une image ou une vidéo jointe",
+		"post.story_single_media":        "Une story est une seule image ou vidéo ; joignez un seul fichier",
+		"post.content_over_limit":        "Le contenu dépasse la limite de caractères de : %s",
+		"post.content_over_limit_hint":   "Raccourcissez le contenu ou passez allow_truncation à true pour le couper à la limite de chaque plateforme",
+		"post.invalid_media_ids":         "IDs de médias invalides",
+		"post.media_not_found":           "Un ou plusieurs IDs de médias sont introuvables",
+		"post.media_access_denied":       "Accès refusé au média",
+		"post.subtitles_format":          "les sous-titres doivent être au format SRT",
+		"post.animated_webp_unsupported": "les WebP animés ne peuvent pas être publiés ; convertissez-les en GIF ou MP4",
+		"post.video_over_limit":          "la vidéo dépasse la limite de résolution ou de débit pour %s",
+		"post.media_required":            "Média requis manquant pour : %s",
+		"post.thumbnail_not_found":       "média de la miniature introuvable",
+		"post.thumbnail_not_image":       "la miniature doit être une image",
+		"post.subtitles_too_large":       "les sous-titres ne doivent pas dépasser 512 Ko",
+		"post.subtitles_require_video":   "les sous-titres nécessitent une vidéo jointe",
+		"post.create_scheduled_failed":   "Erreur lors de la création de la publication programmée",
+		"post.create_failed":             "Erreur lors de la création de la publication",
+		"post.queue_failed":              "Erreur lors de la mise en file de la publication. Elle a été enregistrée comme brouillon ; réessayez plus tard",
+		"post.template_and_content":      "Indiquez content ou template_id, pas les deux",
+		"post.template_not_found":        "Modèle introuvable",
+		"post.template_missing_vars":     "Variables de modèle manquantes : %s",
+		"post.made_for_kids_required":    "Les publications YouTube doivent indiquer made_for_kids à true ou false",
+		"post.tags_too_long":             "Les tags ne doivent pas dépasser %d caractères au total",
+		"post.invalid_tag":               "Les tags ne doivent pas être vides ni contenir < ou >",
+		"post.not_found":                 "Publication introuvable",
+		"post.access_denied":             "Accès refusé",
+		"post.not_editable":              "Seules les publications en brouillon ou programmées peuvent être modifiées",
+		"post.update_failed":             "Erreur lors de la mise à jour de la publication",
+
+		"publish.failed":         "Échec de la publication sur une ou plusieurs plateformes",
+		"publish.busy":           "Trop de publications sont en cours. La publication a été enregistrée comme brouillon ; réessayez dans un instant",
+		"publish.failed_hint":    "Consultez publish_response.results pour le détail par plateforme",
+		"publish.failed_summary": "Plateformes en échec : %s",
+	},
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"SocialMediaAPI/config"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// ClientIP returns the client IP from the request.
+// It prefers X-Real-IP, then X-Forwarded-For, then the connection
+// remote address (with port stripped).
+func ClientIP(r *http.Request) string {
+	// X-Real-IP is typically set by a reverse proxy (nginx, etc.)
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	// X-Forwarded-For can contain a comma-separated chain; the first entry
+	// is the original client IP.
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		if i := strings.IndexByte(xff, ','); i >= 0 {
+			return xff[:i]
+		}
+		return xff
+	}
+	// Fall back to the TCP remote address (strip port)
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// TrustedClientIP returns the client IP for records that must not be spoofed,
+// such as the audit log. The connection remote address is used unless it is
+// one of TRUSTED_PROXIES. Then X-Forwarded-For is walked from the right, as
+// each proxy appends the address it received the request from, and the first
+// address that is not a trusted proxy is the client; entries further left
+// were sent by the client and are ignored.
+func TrustedClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !isTrustedProxy(r.RemoteAddr) {
+		return ip
+	}
+
+	// Several X-Forwarded-For headers form one list, in order
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !isTrustedProxy(hop) {
+			return hop
+		}
+		ip = hop
+	}
+	// Every hop is a trusted proxy: the leftmost one is the closest to the
+	// client that can be trusted
+	return ip
+}
+
+// RequestScheme returns the scheme the client used: "https" for TLS
+// connections, the X-Forwarded-Proto header when the request comes from one
+// of TRUSTED_PROXIES (a TLS-terminating proxy), and "http" otherwise.
+func RequestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	if isTrustedProxy(r.RemoteAddr) {
+		// A proxy chain appends its own value; the first is the client's
+		proto := r.Header.Get("X-Forwarded-Proto")
+		if i := strings.IndexByte(proto, ','); i >= 0 {
+			proto = proto[:i]
+		}
+		proto = strings.ToLower(strings.TrimSpace(proto))
+		if proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+	return "http"
+}
+
+// MediaBaseURL returns the base media URLs are built on for a request. It is
+// config.MediaBaseURL() when MEDIA_CDN_BASE or BASE_URL is set; otherwise
+// the host the client reached, with the scheme from RequestScheme.
+func MediaBaseURL(r *http.Request) string {
+	cfg := config.Load()
+	if cfg.BaseURLExplicit || cfg.MediaCDNBase != "" || r.Host == "" {
+		return cfg.MediaBaseURL()
+	}
+	return RequestScheme(r) + "://" + r.Host
+}
+
+func isTrustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, proxy := range config.Load().TrustedProxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			if prefix.Contains(addr) {
+				return true
+			}
+		} else if ip, err := netip.ParseAddr(proxy); err == nil && ip.Unmap() == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllowedRedirect reports whether target is safe to redirect a browser to:
+// either a path on this server ("/oauth/success", not "//host" or "/\host",
+// which browsers treat as another host) or an absolute http(s) URL whose
+// origin (scheme://host[:port]) appears in allowedOrigins.
+func IsAllowedRedirect(target string, allowedOrigins []string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme == "" && u.Host == "" {
+		return strings.HasPrefix(target, "/") &&
+			!strings.HasPrefix(target, "//") &&
+			!strings.HasPrefix(target, "/\\")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	for _, allowed := range allowedOrigins {
+		if strings.ToLower(strings.TrimRight(allowed, "/")) == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"SocialMediaAPI/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrSignatureInvalid = errors.New("invalid media URL signature")
+	ErrSignatureExpired = errors.New("media URL signature has expired")
+)
+
+// SignURL adds expires and signature query parameters to rawURL. The
+// signature is an HMAC-SHA256 of the URL path and expiry, keyed with
+// MEDIA_SIGNING_KEY, so it stays valid whichever host serves the path.
+func SignURL(rawURL string, expiresAt time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	q := u.Query()
+	q.Set("expires", expires)
+	q.Set("signature", urlSignature(u.Path, expires))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// VerifySignedURL checks the expires and signature parameters of a request
+// for path, as produced by SignURL.
+func VerifySignedURL(path string, query url.Values) error {
+	expires := query.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	expected := urlSignature(path, expires)
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(expected)) {
+		return ErrSignatureInvalid
+	}
+	if time.Now().Unix() > unix {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func urlSignature(path, expires string) string {
+	mac := hmac.New(sha256.New, config.Load().MediaSigningKey)
+	mac.Write([]byte(path + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// templateVarPattern matches a {{name}} placeholder, with optional spaces
+// inside the braces, or an escaped \{{ that stands for a literal "{{".
+var templateVarPattern = regexp.MustCompile(`\\\{\{|\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+// TemplateVariables returns the distinct variable names referenced by a
+// content template, sorted.
+func TemplateVariables(template string) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, m := range templateVarPattern.FindAllStringSubmatch(template, -1) {
+		if name := m[1]; name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MissingTemplateVariables returns the variables referenced by template that
+// are not in vars, sorted.
+func MissingTemplateVariables(template string, vars map[string]string) []string {
+	missing := []string{}
+	for _, name := range TemplateVariables(template) {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// RenderTemplate replaces every {{name}} in template with vars[name]. Values
+// are inserted as-is and never expanded again, and \{{ renders a literal
+// "{{". It fails, naming every missing variable, when a referenced variable
+// is not in vars.
+func RenderTemplate(template string, vars map[string]string) (string, error) {
+	if missing := MissingTemplateVariables(template, vars); len(missing) > 0 {
+		return "", fmt.Errorf("missing template variables: %s", strings.Join(missing, ", "))
+	}
+
+	return templateVarPattern.ReplaceAllStringFunc(template, func(match string) string {
+		if match == `\{{` {
+			return "{{"
+		}
+		name := templateVarPattern.FindStringSubmatch(match)[1]
+		return vars[name]
+	}), nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncateRunes shortens s to at most max runes (Unicode code points).
+// Platform length limits are expressed in characters, not bytes, so slicing
+// a string by byte index would cut multibyte characters (accents, emoji, CJK)
+// in half and produce invalid UTF-8.
+func TruncateRunes(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	count := 0
+	for i := range s {
+		if count == max {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
+
+// RuneLen returns the number of runes in s.
+func RuneLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// SplitRunes breaks s into parts of at most max runes, e.g. the tweets of a
+// thread. Each part ends at the last sentence end (".", "!" or "?" before
+// whitespace, or a line break) that fits, as long as that keeps at least half
+// of the part; otherwise at the last whitespace. A word longer than max is
+// split where it reaches the limit.
+func SplitRunes(s string, max int) []string {
+	parts := []string{}
+	rest := strings.TrimSpace(s)
+	for max > 0 && utf8.RuneCountInString(rest) > max {
+		head := TruncateRunes(rest, max)
+		// Include the byte after head, so a sentence or word ending exactly
+		// at the limit is recognised
+		window := rest[:len(head)+1]
+
+		cut := lastSentenceEnd(window)
+		if cut < len(head)/2 {
+			cut = strings.LastIndexAny(window, " \t\n")
+		}
+		if cut <= 0 {
+			cut = len(head)
+		}
+		parts = append(parts, strings.TrimSpace(rest[:cut]))
+		rest = strings.TrimSpace(rest[cut:])
+	}
+	if rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+// lastSentenceEnd returns the index just past the last sentence end in s,
+// or -1 when there is none.
+func lastSentenceEnd(s string) int {
+	for i := len(s) - 1; i > 0; i-- {
+		switch {
+		case s[i] == '\n':
+			return i
+		case (s[i] == ' ' || s[i] == '\t') && strings.IndexByte(".!?", s[i-1]) >= 0:
+			return i
+		}
+	}
+	return -1
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"SocialMediaAPI/config"
@@ -13,6 +14,12 @@ import (
 
 type TokenValidator struct{}
 
+// validatorClient is shared by every TokenValidator so token checks reuse
+// connections.
+var validatorClient = sync.OnceValue(func() *http.Client {
+	return NewHTTPClient(config.Load().OAuthHTTPTimeout)
+})
+
 func NewTokenValidator() *TokenValidator {
 	return &TokenValidator{}
 }
@@ -31,9 +38,9 @@ func (t *TokenValidator) IsTokenExpired(cred *models.PlatformCredentials) bool {
 // ValidateFacebookToken checks if a Facebook token is still valid
 func (t *TokenValidator) ValidateFacebookToken(accessToken string) bool {
 	cfg := config.Load()
-	url := fmt.Sprintf("https://graph.facebook.com/%s/me?access_token=%s", cfg.FacebookVersion, accessToken)
+	url := fmt.Sprintf("%s/%s/me?access_token=%s", cfg.FacebookGraphBase, cfg.FacebookVersion, accessToken)
 
-	resp, err := http.Get(url)
+	resp, err := validatorClient().Get(url)
 	if err != nil {
 		return false
 	}
@@ -56,14 +63,15 @@ func (t *TokenValidator) RefreshFacebookToken(cred *models.PlatformCredentials)
 	// Attempt to exchange for long-lived token
 	// Facebook allows exchanging user tokens for long-lived versions (60 days)
 	exchangeURL := fmt.Sprintf(
-		"https://graph.facebook.com/%s/oauth/access_token?grant_type=fb_exchange_token&client_id=%s&client_secret=%s&fb_exchange_token=%s",
+		"%s/%s/oauth/access_token?grant_type=fb_exchange_token&client_id=%s&client_secret=%s&fb_exchange_token=%s",
+		cfg.FacebookGraphBase,
 		cfg.FacebookVersion,
 		cfg.FacebookAppID,
 		cfg.FacebookAppSecret,
 		cred.AccessToken,
 	)
 
-	resp, err := http.Get(exchangeURL)
+	resp, err := validatorClient().Get(exchangeURL)
 	if err != nil {
 		// Exchange failed, but token is still valid - just extend current expiry
 		newExpiry := time.Now().Add(24 * time.Hour)